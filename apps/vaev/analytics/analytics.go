@@ -0,0 +1,348 @@
+package analytics
+
+import (
+	"koppla/apps/vaev/views/graph"
+	"slices"
+	"sort"
+)
+
+// Graph is a directed adjacency structure over the nodes of a project. Nodes
+// are addressed by their index in Ids, which is sorted so that every
+// algorithm gives the same answer for the same project.
+type Graph struct {
+	Ids   []string
+	Out   [][]int
+	In    [][]int
+	index map[string]int
+}
+
+// New builds a graph from project rows. Edges that point at nodes which are
+// not in nodes are ignored.
+func New(nodes []graph.Node, edges []graph.Edge) *Graph {
+	ids := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		ids = append(ids, n.Id)
+	}
+	sort.Strings(ids)
+	ids = slices.Compact(ids)
+
+	g := &Graph{
+		Ids:   ids,
+		Out:   make([][]int, len(ids)),
+		In:    make([][]int, len(ids)),
+		index: make(map[string]int, len(ids)),
+	}
+	for i, id := range ids {
+		g.index[id] = i
+	}
+
+	for _, e := range edges {
+		from, ok := g.index[e.StartId]
+		if !ok {
+			continue
+		}
+		to, ok := g.index[e.EndId]
+		if !ok {
+			continue
+		}
+		g.Out[from] = append(g.Out[from], to)
+		g.In[to] = append(g.In[to], from)
+	}
+
+	return g
+}
+
+// Index returns the index of the node with the given id.
+func (g *Graph) Index(id string) (int, bool) {
+	i, ok := g.index[id]
+	return i, ok
+}
+
+func (g *Graph) Len() int {
+	return len(g.Ids)
+}
+
+// neighbours returns every node adjacent to i regardless of edge direction.
+func (g *Graph) neighbours(i int) []int {
+	return append(slices.Clone(g.Out[i]), g.In[i]...)
+}
+
+// ConnectedComponents returns the weakly connected components of the graph,
+// largest first.
+func (g *Graph) ConnectedComponents() [][]string {
+	seen := make([]bool, g.Len())
+	components := [][]string{}
+
+	for start := range g.Ids {
+		if seen[start] {
+			continue
+		}
+
+		component := []string{}
+		queue := []int{start}
+		seen[start] = true
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			component = append(component, g.Ids[current])
+
+			for _, next := range g.neighbours(current) {
+				if !seen[next] {
+					seen[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+
+		sort.Strings(component)
+		components = append(components, component)
+	}
+
+	sort.SliceStable(components, func(a, b int) bool {
+		return len(components[a]) > len(components[b])
+	})
+	return components
+}
+
+type NodeDegree struct {
+	Id     string `json:"id"`
+	In     int    `json:"in"`
+	Out    int    `json:"out"`
+	Degree int    `json:"degree"`
+}
+
+type DegreeStats struct {
+	Nodes     []NodeDegree `json:"nodes"`
+	MinDegree int          `json:"min_degree"`
+	MaxDegree int          `json:"max_degree"`
+	Mean      float64      `json:"mean"`
+	MaxIn     int          `json:"max_in"`
+	MaxOut    int          `json:"max_out"`
+}
+
+// Degrees returns the in-, out- and total degree of every node along with a
+// summary for the whole graph.
+func (g *Graph) Degrees() DegreeStats {
+	stats := DegreeStats{Nodes: make([]NodeDegree, 0, g.Len())}
+	if g.Len() == 0 {
+		return stats
+	}
+
+	stats.MinDegree = -1
+	total := 0
+	for i, id := range g.Ids {
+		d := NodeDegree{
+			Id:     id,
+			In:     len(g.In[i]),
+			Out:    len(g.Out[i]),
+			Degree: len(g.In[i]) + len(g.Out[i]),
+		}
+		stats.Nodes = append(stats.Nodes, d)
+
+		total += d.Degree
+		stats.MaxDegree = max(stats.MaxDegree, d.Degree)
+		stats.MaxIn = max(stats.MaxIn, d.In)
+		stats.MaxOut = max(stats.MaxOut, d.Out)
+		if stats.MinDegree < 0 || d.Degree < stats.MinDegree {
+			stats.MinDegree = d.Degree
+		}
+	}
+	stats.Mean = float64(total) / float64(g.Len())
+
+	return stats
+}
+
+// ShortestPath returns the ids on a shortest path from one node to another,
+// both included, or nil when there is none. Unless directed is set edges can
+// be walked in both directions.
+func (g *Graph) ShortestPath(from string, to string, directed bool) []string {
+	start, ok := g.index[from]
+	if !ok {
+		return nil
+	}
+	end, ok := g.index[to]
+	if !ok {
+		return nil
+	}
+
+	prev := make([]int, g.Len())
+	for i := range prev {
+		prev[i] = -1
+	}
+	prev[start] = start
+
+	queue := []int{start}
+	for len(queue) > 0 && prev[end] < 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		next_nodes := g.Out[current]
+		if !directed {
+			next_nodes = g.neighbours(current)
+		}
+		for _, next := range next_nodes {
+			if prev[next] < 0 {
+				prev[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	if prev[end] < 0 {
+		return nil
+	}
+
+	path := []string{}
+	for at := end; at != start; at = prev[at] {
+		path = append(path, g.Ids[at])
+	}
+	path = append(path, g.Ids[start])
+	slices.Reverse(path)
+
+	return path
+}
+
+// FindCycle returns the ids of a directed cycle in the graph, starting and
+// ending with the same node, or nil when the graph is acyclic.
+func (g *Graph) FindCycle() []string {
+	const (
+		unvisited = iota
+		in_progress
+		done
+	)
+
+	state := make([]int, g.Len())
+	parent := make([]int, g.Len())
+
+	var cycle []string
+	var visit func(i int) bool
+	visit = func(i int) bool {
+		state[i] = in_progress
+		for _, next := range g.Out[i] {
+			switch state[next] {
+			case unvisited:
+				parent[next] = i
+				if visit(next) {
+					return true
+				}
+			case in_progress:
+				cycle = []string{g.Ids[next]}
+				for at := i; at != next; at = parent[at] {
+					cycle = append(cycle, g.Ids[at])
+				}
+				cycle = append(cycle, g.Ids[next])
+				slices.Reverse(cycle)
+				return true
+			}
+		}
+		state[i] = done
+		return false
+	}
+
+	for i := range g.Ids {
+		if state[i] == unvisited && visit(i) {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// Betweenness returns the betweenness centrality of every node using
+// Brandes' algorithm on the directed, unweighted graph.
+func (g *Graph) Betweenness() map[string]float64 {
+	n := g.Len()
+	centrality := make([]float64, n)
+
+	for s := range n {
+		stack := make([]int, 0, n)
+		preds := make([][]int, n)
+		sigma := make([]float64, n)
+		dist := make([]int, n)
+		for i := range dist {
+			dist[i] = -1
+		}
+		sigma[s] = 1
+		dist[s] = 0
+
+		queue := []int{s}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			stack = append(stack, v)
+
+			for _, w := range g.Out[v] {
+				if dist[w] < 0 {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+					preds[w] = append(preds[w], v)
+				}
+			}
+		}
+
+		delta := make([]float64, n)
+		for len(stack) > 0 {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, v := range preds[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			if w != s {
+				centrality[w] += delta[w]
+			}
+		}
+	}
+
+	return g.byId(centrality)
+}
+
+// PageRank returns the PageRank of every node. Rank held by nodes without
+// outgoing edges is spread evenly over the graph.
+func (g *Graph) PageRank(damping float64, iterations int) map[string]float64 {
+	n := g.Len()
+	if n == 0 {
+		return map[string]float64{}
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+
+	for range iterations {
+		dangling := 0.0
+		for i := range n {
+			if len(g.Out[i]) == 0 {
+				dangling += rank[i]
+			}
+		}
+
+		next := make([]float64, n)
+		base := (1-damping)/float64(n) + damping*dangling/float64(n)
+		for i := range next {
+			next[i] = base
+		}
+		for i := range n {
+			if len(g.Out[i]) == 0 {
+				continue
+			}
+			share := damping * rank[i] / float64(len(g.Out[i]))
+			for _, to := range g.Out[i] {
+				next[to] += share
+			}
+		}
+		rank = next
+	}
+
+	return g.byId(rank)
+}
+
+func (g *Graph) byId(values []float64) map[string]float64 {
+	res := make(map[string]float64, len(values))
+	for i, v := range values {
+		res[g.Ids[i]] = v
+	}
+	return res
+}
@@ -0,0 +1,190 @@
+package apperr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"koppla/apps/vaev/middleware"
+	"koppla/apps/vaev/views/layout"
+	"koppla/apps/vaev/views/toaster"
+	"log"
+	"net/http"
+
+	"github.com/a-h/templ"
+	chi_middleware "github.com/go-chi/chi/v5/middleware"
+	datastar "github.com/starfederation/datastar/sdk/go"
+)
+
+type Kind int
+
+const (
+	K_VALIDATION Kind = iota
+	K_UNAUTHORIZED
+	K_FORBIDDEN
+	K_NOT_FOUND
+	K_CONFLICT
+	K_INTERNAL
+)
+
+func (k Kind) Status() int {
+	switch k {
+	case K_VALIDATION:
+		return http.StatusUnprocessableEntity
+	case K_UNAUTHORIZED:
+		return http.StatusUnauthorized
+	case K_FORBIDDEN:
+		return http.StatusForbidden
+	case K_NOT_FOUND:
+		return http.StatusNotFound
+	case K_CONFLICT:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Error is an error that knows how it should be presented to the client.
+// Message is shown to the user as is, Err is the underlying cause and only
+// ever ends up in the logs.
+type Error struct {
+	Kind    Kind
+	Message string
+	Details any
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithDetails attaches a value that is rendered next to the message in JSON
+// responses.
+func (e *Error) WithDetails(details any) *Error {
+	e.Details = details
+	return e
+}
+
+func Validation(msg string) *Error {
+	return &Error{Kind: K_VALIDATION, Message: msg}
+}
+
+func Unauthorized(msg string) *Error {
+	return &Error{Kind: K_UNAUTHORIZED, Message: msg}
+}
+
+func Forbidden(msg string) *Error {
+	return &Error{Kind: K_FORBIDDEN, Message: msg}
+}
+
+func NotFound(msg string) *Error {
+	return &Error{Kind: K_NOT_FOUND, Message: msg}
+}
+
+func Conflict(msg string) *Error {
+	return &Error{Kind: K_CONFLICT, Message: msg}
+}
+
+func Internal(err error) *Error {
+	return &Error{Kind: K_INTERNAL, Message: "Something went wrong", Err: err}
+}
+
+// From returns err as an *Error, treating anything unknown as internal.
+func From(err error) *Error {
+	var app_err *Error
+	if errors.As(err, &app_err) {
+		return app_err
+	}
+	return Internal(err)
+}
+
+// HandlerFunc is an http.HandlerFunc that hands its error to one of the
+// adapters below instead of writing it itself.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// JSON renders errors as a middleware.JSONErrorMessage, for /v-api routes.
+func JSON(fn HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := fn(w, r); err != nil {
+			WriteJSON(w, r, err)
+		}
+	}
+}
+
+// SSE renders errors as a toaster message, for datastar /sse routes.
+func SSE(fn HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := fn(w, r); err != nil {
+			WriteSSE(w, r, err)
+		}
+	}
+}
+
+// Page renders errors as a full HTML error page.
+func Page(fn HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := fn(w, r); err != nil {
+			WritePage(w, r, err)
+		}
+	}
+}
+
+func WriteJSON(w http.ResponseWriter, r *http.Request, err error) {
+	app_err := logError(r, err)
+	request_id := chi_middleware.GetReqID(r.Context())
+
+	bytes, err := json.Marshal(middleware.JSONErrorMessage{
+		Message:   app_err.Message,
+		Status:    app_err.Kind.Status(),
+		RequestId: request_id,
+		Details:   app_err.Details,
+	})
+	if err != nil {
+		log.Printf("[%s] Unable to marshal error response: %v", request_id, err)
+		http.Error(w, app_err.Message, app_err.Kind.Status())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(app_err.Kind.Status())
+	w.Write(bytes)
+}
+
+func WriteSSE(w http.ResponseWriter, r *http.Request, err error) {
+	app_err := logError(r, err)
+	sse := datastar.NewSSE(w, r)
+	toaster.SendErrorMessage(sse, app_err.Message)
+}
+
+func WritePage(w http.ResponseWriter, r *http.Request, err error) {
+	app_err := logError(r, err)
+	request_id := ""
+	if app_err.Kind == K_INTERNAL {
+		request_id = chi_middleware.GetReqID(r.Context())
+	}
+
+	templ.Handler(
+		layout.Doc(func() templ.Component {
+			return layout.ErrorPage(app_err.Kind.Status(), app_err.Message, request_id)
+		}),
+		templ.WithStatus(app_err.Kind.Status()),
+	).ServeHTTP(w, r)
+}
+
+func logError(r *http.Request, err error) *Error {
+	app_err := From(err)
+	log.Printf("[%s] %s %s: %d %v",
+		chi_middleware.GetReqID(r.Context()),
+		r.Method,
+		r.URL.Path,
+		app_err.Kind.Status(),
+		app_err,
+	)
+	return app_err
+}
@@ -0,0 +1,471 @@
+package arrange
+
+import (
+	"koppla/apps/vaev/analytics"
+	"math"
+	"slices"
+	"sort"
+)
+
+const (
+	L_FORCE   = "force"
+	L_LAYERED = "layered"
+	L_RADIAL  = "radial"
+)
+
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type Options struct {
+	// Spacing is the preferred distance between two nodes.
+	Spacing int
+	// Iterations caps the number of simulation steps of the force layout.
+	Iterations int
+	// Root is the node the radial layout is centered on. When empty the
+	// node with the highest degree of every component is used.
+	Root string
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Spacing:    120,
+		Iterations: 300,
+	}
+}
+
+// Compute runs the named layout and returns a position for every node of g.
+// The second return value is false when the layout is unknown.
+func Compute(name string, g *analytics.Graph, current map[string]Point, opts Options) (map[string]Point, bool) {
+	switch name {
+	case L_FORCE:
+		return ForceDirected(g, current, opts), true
+	case L_LAYERED:
+		return Layered(g, opts), true
+	case L_RADIAL:
+		return Radial(g, opts), true
+	default:
+		return nil, false
+	}
+}
+
+// Auto lays out a graph nobody arranged yet: in layers when it has edges and
+// none of them close a cycle, force directed otherwise.
+func Auto(g *analytics.Graph, opts Options) map[string]Point {
+	edges := 0
+	for _, targets := range g.Out {
+		edges += len(targets)
+	}
+	if edges > 0 && len(topologicalOrder(g.Out)) == g.Len() {
+		return Layered(g, opts)
+	}
+	return ForceDirected(g, map[string]Point{}, opts)
+}
+
+// ForceDirected places nodes with the Fruchterman-Reingold algorithm,
+// starting from their current positions. Repulsion is only computed between
+// nodes in neighbouring grid cells so that large projects stay tractable.
+func ForceDirected(g *analytics.Graph, current map[string]Point, opts Options) map[string]Point {
+	n := g.Len()
+	if n == 0 {
+		return map[string]Point{}
+	}
+
+	k := float64(opts.Spacing)
+	xs, ys := initialPositions(g, current, k)
+
+	temperature := k * math.Sqrt(float64(n)) / 2
+	cooling := temperature / float64(opts.Iterations+1)
+	cell_size := 2 * k
+
+	dx := make([]float64, n)
+	dy := make([]float64, n)
+	for range opts.Iterations {
+		clear(dx)
+		clear(dy)
+
+		cells := map[[2]int][]int{}
+		for i := range n {
+			cell := [2]int{int(math.Floor(xs[i] / cell_size)), int(math.Floor(ys[i] / cell_size))}
+			cells[cell] = append(cells[cell], i)
+		}
+
+		for cell, members := range cells {
+			for ox := -1; ox <= 1; ox++ {
+				for oy := -1; oy <= 1; oy++ {
+					others := cells[[2]int{cell[0] + ox, cell[1] + oy}]
+					for _, i := range members {
+						for _, j := range others {
+							if i == j {
+								continue
+							}
+							ddx, ddy := xs[i]-xs[j], ys[i]-ys[j]
+							dist := math.Max(math.Hypot(ddx, ddy), 0.01)
+							if dist > cell_size {
+								continue
+							}
+							force := k * k / dist
+							dx[i] += ddx / dist * force
+							dy[i] += ddy / dist * force
+						}
+					}
+				}
+			}
+		}
+
+		for from := range n {
+			for _, to := range g.Out[from] {
+				if from == to {
+					continue
+				}
+				ddx, ddy := xs[from]-xs[to], ys[from]-ys[to]
+				dist := math.Max(math.Hypot(ddx, ddy), 0.01)
+				force := dist * dist / k
+				dx[from] -= ddx / dist * force
+				dy[from] -= ddy / dist * force
+				dx[to] += ddx / dist * force
+				dy[to] += ddy / dist * force
+			}
+		}
+
+		// A weak pull towards the origin keeps disconnected components from
+		// drifting apart.
+		for i := range n {
+			dx[i] -= xs[i] * 0.01
+			dy[i] -= ys[i] * 0.01
+
+			length := math.Max(math.Hypot(dx[i], dy[i]), 0.01)
+			step := math.Min(length, temperature)
+			xs[i] += dx[i] / length * step
+			ys[i] += dy[i] / length * step
+		}
+
+		temperature = math.Max(temperature-cooling, 1)
+	}
+
+	return toPoints(g, xs, ys)
+}
+
+// initialPositions returns the current position of every node, centered on
+// the origin. Nodes that share a position with an earlier node are moved
+// onto a spiral around it so that the simulation can pull them apart.
+func initialPositions(g *analytics.Graph, current map[string]Point, k float64) ([]float64, []float64) {
+	n := g.Len()
+	xs := make([]float64, n)
+	ys := make([]float64, n)
+
+	var cx, cy float64
+	for i, id := range g.Ids {
+		p := current[id]
+		xs[i], ys[i] = float64(p.X), float64(p.Y)
+		cx += xs[i]
+		cy += ys[i]
+	}
+	cx /= float64(n)
+	cy /= float64(n)
+
+	taken := map[[2]float64]bool{}
+	for i := range n {
+		xs[i] -= cx
+		ys[i] -= cy
+
+		for step := 1; taken[[2]float64{xs[i], ys[i]}]; step++ {
+			angle := float64(step) * 2.399963
+			radius := k * math.Sqrt(float64(step)) / 2
+			xs[i] += math.Cos(angle) * radius
+			ys[i] += math.Sin(angle) * radius
+		}
+		taken[[2]float64{xs[i], ys[i]}] = true
+	}
+
+	return xs, ys
+}
+
+// Layered places nodes in horizontal layers following the direction of the
+// edges, in the style of Sugiyama: cycles are broken, nodes are assigned to
+// layers by longest path, long edges get virtual nodes and the order within
+// each layer is improved with the barycenter heuristic.
+func Layered(g *analytics.Graph, opts Options) map[string]Point {
+	n := g.Len()
+	if n == 0 {
+		return map[string]Point{}
+	}
+
+	out := acyclic(g)
+
+	layer := make([]int, n)
+	for _, v := range topologicalOrder(out) {
+		for _, w := range out[v] {
+			layer[w] = max(layer[w], layer[v]+1)
+		}
+	}
+
+	// Split edges spanning several layers with virtual nodes so that the
+	// crossing reduction sees them in every layer they pass through.
+	down := make([][]int, n)
+	layers := [][]int{}
+	place := func(v int, l int) {
+		for len(layers) <= l {
+			layers = append(layers, []int{})
+		}
+		layers[l] = append(layers[l], v)
+	}
+	for v := range n {
+		place(v, layer[v])
+	}
+	for v := range n {
+		for _, w := range out[v] {
+			prev := v
+			for l := layer[v] + 1; l < layer[w]; l++ {
+				virtual := len(down)
+				down = append(down, nil)
+				layer = append(layer, l)
+				place(virtual, l)
+				down[prev] = append(down[prev], virtual)
+				prev = virtual
+			}
+			down[prev] = append(down[prev], w)
+		}
+	}
+
+	up := make([][]int, len(down))
+	for v, targets := range down {
+		for _, w := range targets {
+			up[w] = append(up[w], v)
+		}
+	}
+
+	position := make([]float64, len(down))
+	renumber := func(l int) {
+		for i, v := range layers[l] {
+			position[v] = float64(i)
+		}
+	}
+	for l := range layers {
+		renumber(l)
+	}
+
+	for range 8 {
+		for l := 1; l < len(layers); l++ {
+			sortByBarycenter(layers[l], up, position)
+			renumber(l)
+		}
+		for l := len(layers) - 2; l >= 0; l-- {
+			sortByBarycenter(layers[l], down, position)
+			renumber(l)
+		}
+	}
+
+	xs := make([]float64, n)
+	ys := make([]float64, n)
+	for l, members := range layers {
+		offset := float64(len(members)-1) / 2
+		for i, v := range members {
+			if v >= n {
+				continue
+			}
+			xs[v] = (float64(i) - offset) * float64(opts.Spacing)
+			ys[v] = float64(l) * float64(opts.Spacing)
+		}
+	}
+
+	return toPoints(g, xs, ys)
+}
+
+// acyclic returns the outgoing adjacency of g with every edge that closes a
+// cycle reversed, and self loops dropped.
+func acyclic(g *analytics.Graph) [][]int {
+	n := g.Len()
+	out := make([][]int, n)
+	state := make([]int, n)
+
+	var visit func(v int)
+	visit = func(v int) {
+		state[v] = 1
+		for _, w := range g.Out[v] {
+			switch state[w] {
+			case 0:
+				out[v] = append(out[v], w)
+				visit(w)
+			case 1:
+				if v != w {
+					out[w] = append(out[w], v)
+				}
+			default:
+				out[v] = append(out[v], w)
+			}
+		}
+		state[v] = 2
+	}
+
+	for v := range n {
+		if state[v] == 0 {
+			visit(v)
+		}
+	}
+	return out
+}
+
+func topologicalOrder(out [][]int) []int {
+	in_degree := make([]int, len(out))
+	for _, targets := range out {
+		for _, w := range targets {
+			in_degree[w]++
+		}
+	}
+
+	queue := []int{}
+	for v, d := range in_degree {
+		if d == 0 {
+			queue = append(queue, v)
+		}
+	}
+
+	order := make([]int, 0, len(out))
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		order = append(order, v)
+		for _, w := range out[v] {
+			in_degree[w]--
+			if in_degree[w] == 0 {
+				queue = append(queue, w)
+			}
+		}
+	}
+	return order
+}
+
+func sortByBarycenter(members []int, adjacent [][]int, position []float64) {
+	barycenter := make(map[int]float64, len(members))
+	for _, v := range members {
+		if len(adjacent[v]) == 0 {
+			barycenter[v] = position[v]
+			continue
+		}
+		sum := 0.0
+		for _, w := range adjacent[v] {
+			sum += position[w]
+		}
+		barycenter[v] = sum / float64(len(adjacent[v]))
+	}
+
+	sort.SliceStable(members, func(a, b int) bool {
+		return barycenter[members[a]] < barycenter[members[b]]
+	})
+}
+
+// Radial places every component as a tree around its root, with each ring
+// one step further away from the root. Components are laid out next to each
+// other from left to right.
+func Radial(g *analytics.Graph, opts Options) map[string]Point {
+	n := g.Len()
+	xs := make([]float64, n)
+	ys := make([]float64, n)
+	spacing := float64(opts.Spacing)
+
+	degrees := g.Degrees()
+	offset := 0.0
+	for _, component := range g.ConnectedComponents() {
+		members := make([]int, 0, len(component))
+		for _, id := range component {
+			i, _ := g.Index(id)
+			members = append(members, i)
+		}
+
+		root := members[0]
+		if i, ok := g.Index(opts.Root); ok && slices.Contains(members, i) {
+			root = i
+		} else {
+			for _, i := range members {
+				if degrees.Nodes[i].Degree > degrees.Nodes[root].Degree {
+					root = i
+				}
+			}
+		}
+
+		children, depth := bfsTree(g, root)
+
+		leaves := make([]int, n)
+		var count_leaves func(v int) int
+		count_leaves = func(v int) int {
+			if len(children[v]) == 0 {
+				leaves[v] = 1
+				return 1
+			}
+			for _, c := range children[v] {
+				leaves[v] += count_leaves(c)
+			}
+			return leaves[v]
+		}
+		count_leaves(root)
+
+		var place func(v int, from float64, to float64)
+		place = func(v int, from float64, to float64) {
+			angle := (from + to) / 2
+			radius := float64(depth[v]) * spacing
+			xs[v] = math.Cos(angle) * radius
+			ys[v] = math.Sin(angle) * radius
+
+			start := from
+			for _, c := range children[v] {
+				share := (to - from) * float64(leaves[c]) / float64(leaves[v])
+				place(c, start, start+share)
+				start += share
+			}
+		}
+		place(root, 0, 2*math.Pi)
+
+		max_depth := 0
+		for _, i := range members {
+			max_depth = max(max_depth, depth[i])
+		}
+		radius := float64(max_depth) * spacing
+		for _, i := range members {
+			xs[i] += offset + radius
+		}
+		offset += 2*radius + spacing
+	}
+
+	return toPoints(g, xs, ys)
+}
+
+// bfsTree returns a breadth first spanning tree of the component of root,
+// ignoring edge direction, as the children and depth of every node.
+func bfsTree(g *analytics.Graph, root int) ([][]int, []int) {
+	children := make([][]int, g.Len())
+	depth := make([]int, g.Len())
+	seen := make([]bool, g.Len())
+	seen[root] = true
+
+	queue := []int{root}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+
+		neighbours := append(slices.Clone(g.Out[v]), g.In[v]...)
+		slices.Sort(neighbours)
+		for _, w := range neighbours {
+			if seen[w] {
+				continue
+			}
+			seen[w] = true
+			depth[w] = depth[v] + 1
+			children[v] = append(children[v], w)
+			queue = append(queue, w)
+		}
+	}
+	return children, depth
+}
+
+func toPoints(g *analytics.Graph, xs []float64, ys []float64) map[string]Point {
+	points := make(map[string]Point, g.Len())
+	for i, id := range g.Ids {
+		points[id] = Point{
+			X: int(math.Round(xs[i])),
+			Y: int(math.Round(ys[i])),
+		}
+	}
+	return points
+}
@@ -0,0 +1,162 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	O_CREATE  = "create"
+	O_UPDATE  = "update"
+	O_DELETE  = "delete"
+	O_RESTORE = "restore"
+)
+
+const (
+	ENT_PROJECT   = "project"
+	ENT_MEMBER    = "member"
+	ENT_SNAPSHOT  = "snapshot"
+	ENT_NODE_TYPE = "node_type"
+	ENT_EDGE_TYPE = "edge_type"
+	ENT_NODE      = "node"
+	ENT_EDGE      = "edge"
+)
+
+// Change is a single write to be recorded. Before is nil for records that
+// were created, After for records that were deleted.
+type Change struct {
+	Operation string `json:"operation"`
+	Entity    string `json:"entity"`
+	EntityId  string `json:"entity_id"`
+	Before    any    `json:"before"`
+	After     any    `json:"after"`
+}
+
+// Event is a recorded change as it is read back. UserName is joined in from
+// users, it stays empty once the user is gone.
+type Event struct {
+	Id        string        `db:"id" json:"id"`
+	Project   string        `db:"project" json:"project"`
+	User      string        `db:"user" json:"user"`
+	UserName  string        `db:"user_name" json:"user_name"`
+	Operation string        `db:"operation" json:"operation"`
+	Entity    string        `db:"entity" json:"entity"`
+	EntityId  string        `db:"entity_id" json:"entity_id"`
+	Before    types.JSONRaw `db:"before" json:"before"`
+	After     types.JSONRaw `db:"after" json:"after"`
+	Created   string        `db:"created" json:"created"`
+}
+
+// Filter narrows down List. Empty fields match everything, From and To are
+// inclusive timestamps in the format PocketBase stores them in.
+type Filter struct {
+	User      string
+	Entity    string
+	EntityId  string
+	Operation string
+	From      string
+	To        string
+	Limit     int
+}
+
+// MAX_LIMIT is the most events List returns at once.
+const MAX_LIMIT = 500
+
+// Record appends changes to the audit log of the project. Pass the
+// transaction the changes were made in, so that the log is written if and
+// only if they are.
+func Record(db dbx.Builder, project_id string, user_id string, changes ...Change) error {
+	query := `
+	INSERT INTO audit_events (project, user, operation, entity, entity_id, before, after, created, updated)
+	VALUES ({:project}, {:user}, {:operation}, {:entity}, {:entity_id}, {:before}, {:after}, {:now}, {:now})
+	`
+	now := time.Now().UTC().Format("2006-01-02 15:04:05.000Z")
+
+	for _, change := range changes {
+		before, err := marshal(change.Before)
+		if err != nil {
+			return err
+		}
+		after, err := marshal(change.After)
+		if err != nil {
+			return err
+		}
+
+		if _, err := db.
+			NewQuery(query).
+			Bind(dbx.Params{
+				"project":   project_id,
+				"user":      user_id,
+				"operation": change.Operation,
+				"entity":    change.Entity,
+				"entity_id": change.EntityId,
+				"before":    before,
+				"after":     after,
+				"now":       now,
+			}).
+			Execute(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func marshal(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(bytes), nil
+}
+
+// List returns the events of the project that match the filter, newest
+// first.
+func List(db dbx.Builder, project_id string, filter Filter) ([]Event, error) {
+	where := dbx.And(dbx.HashExp{"e.project": project_id})
+	for column, value := range map[string]string{
+		"e.user":      filter.User,
+		"e.entity":    filter.Entity,
+		"e.entity_id": filter.EntityId,
+		"e.operation": filter.Operation,
+	} {
+		if value != "" {
+			where = dbx.And(where, dbx.HashExp{column: value})
+		}
+	}
+	if filter.From != "" {
+		where = dbx.And(where, dbx.NewExp("e.created >= {:from}", dbx.Params{"from": filter.From}))
+	}
+	if filter.To != "" {
+		where = dbx.And(where, dbx.NewExp("e.created <= {:to}", dbx.Params{"to": filter.To}))
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > MAX_LIMIT {
+		limit = MAX_LIMIT
+	}
+
+	events := []Event{}
+	if err := db.
+		Select(
+			"e.id", "e.project", "e.user", "e.operation", "e.entity", "e.entity_id",
+			"e.before", "e.after", "e.created",
+			"COALESCE(NULLIF(u.name, ''), u.email, '') AS user_name",
+		).
+		From("audit_events e").
+		LeftJoin("users u", dbx.NewExp("u.id = e.user")).
+		Where(where).
+		OrderBy("e.created DESC", "e.rowid DESC").
+		Limit(int64(limit)).
+		All(&events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
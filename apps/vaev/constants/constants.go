@@ -2,5 +2,8 @@ package constants
 
 const (
 	CTX_AUTH    = "vaev-auth"
+	CTX_PROJECT = "vaev-project"
+	// CTX_TOKEN holds the access token a request was signed in with.
+	CTX_TOKEN   = "vaev-token"
 	COOKIE_AUTH = "vaev-auth"
 )
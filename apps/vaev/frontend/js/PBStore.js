@@ -45,17 +45,39 @@ export class PBStore extends IGraphStore {
 
     throttledPersist = throttle(this._persist.bind(this), 1000)
 
+    /** @type {boolean} */
+    read_only
+
+    /**
+     * Id of the live stream of this page, sent along with every write so
+     * that the server does not echo our own changes back to us.
+     *
+     * @type {string}
+     */
+    client_id = ""
+
     #csrf_token
-    constructor(project_id) {
+    /**
+     * @param {string} project_id
+     * @param {boolean} read_only
+     * @param {string} snapshot_id opens a snapshot of the project instead,
+     * snapshots are always read-only.
+     */
+    constructor(project_id, read_only = false, snapshot_id = "") {
         super();
         this.base_url = `/v-api/project/${project_id}`;
+        if (snapshot_id !== "") {
+            this.base_url += `/snapshots/${snapshot_id}`;
+            read_only = true;
+        }
+        this.read_only = read_only;
         window.addEventListener("beforeunload", async () => {
             await this.throttledPersist.flush()
         });
         const csrf_meta = document.querySelector('meta[name="CSRF-Token"]');
         if (csrf_meta) {
             this.#csrf_token = csrf_meta.getAttribute('content');
-        } else {
+        } else if (!read_only) {
             console.warn("CSRF token meta tag not found.");
         }
     }
@@ -83,13 +105,7 @@ export class PBStore extends IGraphStore {
             /** @type {import("@kpla/engine").EdgeType[]} */
             const edge_types = await fetch(this.base_url + "/edge-types").
                 then(res => res.json());
-            for (const t of edge_types.map(e => {
-                if (e.line_dash == null) return e;
-                return {
-                    ...e,
-                    line_dash: JSON.parse(atob(e.line_dash))
-                }
-            })) {
+            for (const t of edge_types.map(decodeEdgeType)) {
                 this.edge_types.set(t.id, t);
             }
         } catch (e) {
@@ -195,15 +211,22 @@ export class PBStore extends IGraphStore {
     }
 
     async _persist() {
+        if (this.read_only) {
+            this.nodes_to_create.clear()
+            this.nodes_to_update.clear()
+            this.nodes_to_delete.clear()
+            this.edges_to_create.clear()
+            this.edges_to_update.clear()
+            this.edges_to_delete.clear()
+            return;
+        }
+
         if (!this.#csrf_token) {
             console.error("CSRF token is missing. Aborting persistence.");
             return;
         };
 
-        const headers = {
-            'Content-Type': 'application/json',
-            'X-CSRF-Token': this.#csrf_token
-        };
+        const headers = this._headers();
 
         const create_nodes_payload = Array.from(this.nodes_to_create.values())
         const update_nodes_payload = Array.from(this.nodes_to_update.values())
@@ -220,7 +243,7 @@ export class PBStore extends IGraphStore {
                 method: "PUT",
                 headers,
                 body: JSON.stringify(update_nodes_payload)
-            }),
+            }).then(res => this._afterUpdate(res, update_nodes_payload, "nodes")),
             delete_nodes_payload.length && fetch(this.base_url + "/delete-nodes", {
                 method: "DELETE",
                 headers,
@@ -249,6 +272,7 @@ export class PBStore extends IGraphStore {
                         const edge_data = this.edges_by_id.get(temp_id);
                         if (edge_data) {
                             edge_data.id = real_id;
+                            edge_data.revision = temp_edge.revision;
                             this.edges_by_id.delete(temp_id);
                             this.edges_by_id.set(real_id, edge_data);
                             const handle = this.id_to_edge_handle.get(temp_id);
@@ -265,7 +289,7 @@ export class PBStore extends IGraphStore {
                 method: "PUT",
                 headers,
                 body: JSON.stringify(update_edges_payload)
-            }),
+            }).then(res => this._afterUpdate(res, update_edges_payload, "edges")),
             delete_edges_payload.length && fetch(this.base_url + "/delete-edges", {
                 method: "DELETE",
                 headers,
@@ -274,6 +298,241 @@ export class PBStore extends IGraphStore {
         ]).catch(e => console.error("Persistance error:", e))
     }
 
+    /**
+     * Steps back or forth through the changes of this user. The server keeps
+     * the history, so it survives reloads.
+     *
+     * @param {"undo" | "redo"} direction
+     */
+    async travel(direction) {
+        if (this.graph === null || this.read_only) return;
+        await this.throttledPersist.flush();
+
+        const res = await fetch(this.base_url + `/${direction}`, {
+            method: "POST",
+            headers: this._headers(),
+        });
+        const body = await res.json();
+        if (!res.ok) {
+            console.warn(`Could not ${direction}:`, body.message);
+            return;
+        }
+
+        this.applyChanges(body);
+    }
+
+    /**
+     * Lets the server compute and store a layout, then moves the nodes to
+     * their new positions.
+     *
+     * @param {"force" | "layered" | "radial"} algorithm
+     */
+    async applyLayout(algorithm) {
+        if (this.graph === null) return;
+        await this.throttledPersist.flush();
+
+        const res = await fetch(this.base_url + `/layout/${algorithm}`, {
+            method: "POST",
+            headers: this._headers(),
+        });
+        if (!res.ok) {
+            console.error("Layout failed:", await res.json());
+            return;
+        }
+
+        /** @type {Record<string, {x: number, y: number}>} */
+        const points = await res.json();
+        for (const [id, { x, y }] of Object.entries(points)) {
+            const handle = this.id_to_node_handle.get(id);
+            const node = this.nodes_by_id.get(id);
+            if (handle === undefined || node === undefined) continue;
+            this.graph.wasm.setNodePosition(handle, x, y);
+            node.x = x;
+            node.y = y;
+        }
+        this.graph.emit("world:update");
+    }
+
+    /**
+     * Keeps revisions in step after an update. When someone else changed
+     * some of the records first the server rejects the whole batch; their
+     * version wins for those records and the rest of the batch is queued
+     * again.
+     *
+     * @private
+     * @param {Response} res
+     * @param {Array<{id: string}>} payload
+     * @param {"nodes" | "edges"} kind
+     */
+    async _afterUpdate(res, payload, kind) {
+        const by_id = kind === "nodes" ? this.nodes_by_id : this.edges_by_id;
+        const queue = kind === "nodes" ? this.nodes_to_update : this.edges_to_update;
+        const body = await res.json();
+
+        if (res.status === 409) {
+            const conflict = body.details ?? { nodes: [], edges: [] };
+            this.applyChanges(conflict);
+
+            const stale = new Set([...conflict.nodes, ...conflict.edges].map(r => r.id));
+            for (const { id } of payload) {
+                if (stale.has(id) || !by_id.has(id)) continue;
+                queue.set(id, by_id.get(id));
+            }
+            this.throttledPersist();
+            return;
+        }
+        if (!res.ok) {
+            console.error("Update failed:", body);
+            return;
+        }
+
+        /** @type {Record<string, number>} */
+        const revisions = kind === "nodes"
+            ? body.revisions ?? {}
+            : Object.fromEntries(body.map(e => [e.id, e.revision]));
+        for (const [id, revision] of Object.entries(revisions)) {
+            const record = by_id.get(id);
+            if (record) record.revision = revision;
+        }
+    }
+
+    /**
+     * Shares the pointer and selection of this page with other editors.
+     *
+     * @param {{x: number, y: number, selection: string[]}} cursor
+     */
+    async sendCursor(cursor) {
+        if (this.read_only || this.client_id === "") return;
+        await fetch(this.base_url + "/cursor", {
+            method: "POST",
+            headers: this._headers(),
+            body: JSON.stringify(cursor)
+        }).catch(e => console.error("Cursor error:", e))
+    }
+
+    /**
+     * Applies records another editor committed. Nothing is queued for
+     * persistence since the records are already stored.
+     *
+     * @param {import("./live.js").Changes} changes
+     */
+    applyChanges(changes) {
+        if (this.graph === null) return;
+        const wasm = this.graph.wasm;
+
+        for (const t of changes.node_types ?? []) {
+            this.node_types.set(t.id, t);
+        }
+        for (const t of changes.edge_types ?? []) {
+            this.edge_types.set(t.id, decodeEdgeType(t));
+        }
+
+        for (const id of changes.deleted_edges ?? []) {
+            this._removeEdge(id);
+        }
+        for (const id of changes.deleted_nodes ?? []) {
+            this._removeNode(id);
+        }
+
+        for (const n of changes.nodes ?? []) {
+            const handle = this.id_to_node_handle.get(n.id);
+            if (handle === undefined) {
+                const node_handle = wasm.createNode(n.x, n.y);
+                this.nodes_by_id.set(n.id, {
+                    ...n,
+                    handle: node_handle,
+                    edges_outgoing: [],
+                    edges_incoming: [],
+                });
+                this.id_to_node_handle.set(n.id, node_handle);
+                this.node_handle_to_id.set(node_handle, n.id);
+                continue;
+            }
+            wasm.setNodePosition(handle, n.x, n.y);
+            this.nodes_by_id.set(n.id, {
+                ...this.nodes_by_id.get(n.id),
+                ...n,
+                handle,
+            });
+        }
+
+        for (const e of changes.edges ?? []) {
+            const existing = this.edges_by_id.get(e.id);
+            if (existing && existing.start_id === e.start_id && existing.end_id === e.end_id) {
+                Object.assign(existing, e);
+                continue;
+            }
+            if (existing) {
+                this._removeEdge(e.id);
+            }
+
+            const start_handle = this.id_to_node_handle.get(e.start_id);
+            const end_handle = this.id_to_node_handle.get(e.end_id);
+            if (start_handle === undefined || end_handle === undefined) continue;
+
+            const edge_handle = wasm.createEdge(start_handle, end_handle);
+            this.edges_by_id.set(e.id, {
+                ...e,
+                handle: edge_handle,
+                start_handle,
+                end_handle,
+            });
+            this.id_to_edge_handle.set(e.id, edge_handle);
+            this.edge_handle_to_id.set(edge_handle, e.id);
+        }
+
+        for (const id of changes.deleted_node_types ?? []) {
+            this.node_types.delete(id);
+        }
+        for (const id of changes.deleted_edge_types ?? []) {
+            this.edge_types.delete(id);
+        }
+
+        this.graph.emit("world:update");
+    }
+
+    /**
+     * @private
+     * @param {string} id
+     */
+    _removeEdge(id) {
+        const handle = this.id_to_edge_handle.get(id);
+        if (handle === undefined || this.graph === null) return;
+        this.graph.wasm.deleteEdge(handle);
+        this.edges_by_id.delete(id);
+        this.id_to_edge_handle.delete(id);
+        this.edge_handle_to_id.delete(handle);
+    }
+
+    /**
+     * @private
+     * @param {string} id
+     */
+    _removeNode(id) {
+        const handle = this.id_to_node_handle.get(id);
+        if (handle === undefined || this.graph === null) return;
+        for (const [edge_id, edge] of this.edges_by_id) {
+            if (edge.start_id === id || edge.end_id === id) {
+                this._removeEdge(edge_id);
+            }
+        }
+        this.graph.wasm.deleteNode(handle);
+        this.nodes_by_id.delete(id);
+        this.id_to_node_handle.delete(id);
+        this.node_handle_to_id.delete(handle);
+    }
+
+    /**
+     * @private
+     */
+    _headers() {
+        return {
+            'Content-Type': 'application/json',
+            'X-CSRF-Token': this.#csrf_token,
+            'X-Client-Id': this.client_id
+        };
+    }
+
     _map_temp_ids(temp_nodes) {
         for (const temp_node of temp_nodes) {
             const real_id = temp_node.id;
@@ -281,6 +540,7 @@ export class PBStore extends IGraphStore {
             const node_data = this.nodes_by_id.get(temp_id);
             if (node_data) {
                 node_data.id = real_id;
+                node_data.revision = temp_node.revision;
                 this.nodes_by_id.delete(temp_id);
                 this.nodes_by_id.set(real_id, node_data);
                 const handle = this.id_to_node_handle.get(temp_id);
@@ -294,10 +554,7 @@ export class PBStore extends IGraphStore {
     }
 
     async _resolveNodes(payload) {
-        const headers = {
-            'Content-Type': 'application/json',
-            'X-CSRF-Token': this.#csrf_token
-        };
+        const headers = this._headers();
         const res = fetch(this.base_url + "/create-nodes", {
             method: "POST",
             headers,
@@ -468,6 +725,20 @@ export class PBStore extends IGraphStore {
     }
 }
 
+/**
+ * Edge types arrive with their line dash base64 encoded.
+ *
+ * @param {any} edge_type
+ * @returns {import("@kpla/engine").EdgeType}
+ */
+function decodeEdgeType(edge_type) {
+    if (edge_type.line_dash == null) return edge_type;
+    return {
+        ...edge_type,
+        line_dash: JSON.parse(atob(edge_type.line_dash))
+    }
+}
+
 /**
  * Creates a throttled function that only invokes the provided function `func`
  * at most once per every `delay` milliseconds.
@@ -112,6 +112,12 @@ export class ControlPanel {
             case "sort_force":
                 this.driver.graph.sortNodes();
                 break;
+            case "sort_hierarchy":
+                this.driver.graph.store.applyLayout("layered");
+                break;
+            case "sort_radial":
+                this.driver.graph.store.applyLayout("radial");
+                break;
         }
     }
 }
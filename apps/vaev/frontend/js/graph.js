@@ -2,6 +2,7 @@ import { CanvasGUIDriver } from "@kpla/canvas-driver"
 import wasm_url from '@kpla/engine/public/main.wasm?url';
 export { PBStore } from "./PBStore.js";
 export { CSVWriter } from "./CSVWriter.js";
+export { LiveSession } from "./live.js";
 
 import "./components/graph-btn.js"
 import "./components/graph-coords.js"
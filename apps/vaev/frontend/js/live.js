@@ -0,0 +1,173 @@
+import { PBStore, throttle } from "./PBStore.js";
+
+/**
+ * @typedef {{client_id: string, user_id: string, name: string, color: string}} Viewer
+ * @typedef {Viewer & {x: number, y: number, selection: string[]}} Cursor
+ * @typedef {{type: "hello" | "changes" | "presence" | "cursor" | "resync", origin?: string, data: any}} LiveEvent
+ * @typedef {{
+ *   node_types?: any[], edge_types?: any[], nodes?: any[], edges?: any[],
+ *   deleted_node_types?: string[], deleted_edge_types?: string[],
+ *   deleted_nodes?: string[], deleted_edges?: string[]
+ * }} Changes
+ */
+
+/**
+ * Keeps an open project in sync with everyone else who has it open. Events
+ * arrive as "graph-live" DOM events, dispatched by the datastar stream the
+ * page opens on /sse/project/{id}/live.
+ */
+export class LiveSession {
+    /** @type {PBStore} */
+    store
+    /** @type {import("@kpla/canvas-driver").CanvasGUIDriver} */
+    driver
+
+    /** @type {Map<string, {cursor: Cursor, el: HTMLElement}>} */
+    cursors = new Map()
+
+    /** @type {HTMLElement} */
+    overlay
+
+    sendCursor = throttle(this._sendCursor.bind(this), 100)
+
+    /** @type {{x: number, y: number}} */
+    pointer = { x: 0, y: 0 }
+
+    /** @type {string} */
+    last_selection = ""
+
+    /**
+     * @param {PBStore} store
+     * @param {import("@kpla/canvas-driver").CanvasGUIDriver} driver
+     */
+    constructor(store, driver) {
+        this.store = store;
+        this.driver = driver;
+        document.addEventListener("graph-live", (e) => {
+            this._onEvent(/** @type {CustomEvent<LiveEvent>} */ (e).detail)
+        });
+    }
+
+    /**
+     * Starts sharing the pointer once the engine has loaded.
+     */
+    start() {
+        const graph = this.driver.graph;
+        if (graph === null) return;
+
+        this.overlay = document.createElement("div");
+        this.overlay.className = "live-cursors";
+        this.driver.container.appendChild(this.overlay);
+
+        if (!this.store.read_only) {
+            this.driver.container.addEventListener("mousemove", (e) => {
+                const rect = this.driver.container.getBoundingClientRect();
+                this.pointer = graph.screenToWorld({
+                    x: e.clientX - rect.left,
+                    y: e.clientY - rect.top,
+                });
+                this.sendCursor();
+            });
+            graph.on("world:update", () => {
+                const [selected] = this.driver.selected_nodes;
+                const selection = selected().map(n => n.id).join(",");
+                if (selection !== this.last_selection) {
+                    this.last_selection = selection;
+                    this.sendCursor();
+                }
+            });
+        }
+        graph.on("world:update", () => this._renderCursors());
+    }
+
+    /**
+     * @param {LiveEvent} event
+     */
+    async _onEvent(event) {
+        switch (event.type) {
+            case "hello":
+                this.store.client_id = event.data.client_id;
+                break;
+            case "changes":
+                if (event.origin === this.store.client_id) return;
+                this.store.applyChanges(event.data);
+                break;
+            case "presence":
+                this.setViewers(event.data);
+                break;
+            case "cursor":
+                if (event.origin === this.store.client_id) return;
+                this._setCursor(event.data);
+                break;
+            case "resync":
+                await this.store.throttledPersist.flush();
+                window.location.reload();
+                break;
+        }
+    }
+
+    _sendCursor() {
+        const [selected] = this.driver.selected_nodes;
+        this.store.sendCursor({
+            x: this.pointer.x,
+            y: this.pointer.y,
+            selection: selected().map(n => n.id).filter(id => id !== undefined),
+        });
+    }
+
+    /**
+     * @param {Cursor} cursor
+     */
+    _setCursor(cursor) {
+        let entry = this.cursors.get(cursor.client_id);
+        if (entry === undefined) {
+            const el = document.createElement("div");
+            el.className = "live-cursor";
+            el.style.setProperty("--live-color", cursor.color);
+            el.dataset.name = cursor.name;
+            this.overlay?.appendChild(el);
+            entry = { cursor, el };
+            this.cursors.set(cursor.client_id, entry);
+        }
+        entry.cursor = cursor;
+        this._renderCursors();
+    }
+
+    /**
+     * Drops the cursors of viewers that have left.
+     *
+     * @param {Viewer[]} viewers
+     */
+    setViewers(viewers) {
+        const ids = new Set(viewers.map(v => v.client_id));
+        for (const [id, { el }] of this.cursors) {
+            if (!ids.has(id)) {
+                el.remove();
+                this.cursors.delete(id);
+            }
+        }
+    }
+
+    _renderCursors() {
+        const graph = this.driver.graph;
+        if (graph === null) return;
+
+        const toScreen = (/** @type {{x: number, y: number}} */ p) => ({
+            x: p.x * graph.scale + graph.pan_coords.x,
+            y: p.y * graph.scale + graph.pan_coords.y,
+        });
+
+        for (const { cursor, el } of this.cursors.values()) {
+            const { x, y } = toScreen(cursor);
+            el.style.transform = `translate(${x}px, ${y}px)`;
+
+            const rings = cursor.selection.map(id => {
+                const node = this.store.getNodeById(id);
+                if (node === undefined) return "";
+                const p = toScreen(node);
+                return `<div class="live-selection" style="transform: translate(${p.x - x}px, ${p.y - y}px) scale(${graph.scale})"></div>`;
+            });
+            el.innerHTML = rings.join("");
+        }
+    }
+}
@@ -0,0 +1,202 @@
+package hub
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"sort"
+	"sync"
+)
+
+const (
+	// E_HELLO is the first event of every stream and tells the client the
+	// id it was given.
+	E_HELLO = "hello"
+	// E_CHANGES carries records committed through vapi.
+	E_CHANGES = "changes"
+	// E_PRESENCE carries everyone who has the project open.
+	E_PRESENCE = "presence"
+	// E_CURSOR carries the pointer and selection of a single viewer.
+	E_CURSOR = "cursor"
+	// E_RESYNC tells a client that it missed events and has to load the
+	// project again.
+	E_RESYNC = "resync"
+)
+
+// events is how many events a client may fall behind before it is dropped.
+const events = 64
+
+type Event struct {
+	Type string `json:"type"`
+	// Origin is the id of the client whose request caused the event, so that
+	// it can skip changes it already has.
+	Origin string `json:"origin,omitempty"`
+	Data   any    `json:"data"`
+}
+
+type Viewer struct {
+	ClientId string `json:"client_id"`
+	UserId   string `json:"user_id"`
+	Name     string `json:"name"`
+	Color    string `json:"color"`
+}
+
+var colors = []string{"#e6194b", "#3cb44b", "#4363d8", "#f58231", "#911eb4", "#42d4f4", "#f032e6", "#9a6324"}
+
+type Cursor struct {
+	Viewer
+	X         float64  `json:"x"`
+	Y         float64  `json:"y"`
+	Selection []string `json:"selection"`
+}
+
+type Client struct {
+	Viewer
+	events chan Event
+}
+
+// Events is closed when the client leaves or falls too far behind.
+func (c *Client) Events() <-chan Event {
+	return c.events
+}
+
+// Hub fans events out to every client that has a project open.
+type Hub struct {
+	mu       sync.Mutex
+	projects map[string]map[string]*Client
+}
+
+func New() *Hub {
+	return &Hub{projects: map[string]map[string]*Client{}}
+}
+
+func NewClientId() string {
+	bytes := make([]byte, 8)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
+
+// Join registers a viewer of the project and tells everyone, the new client
+// included, who is there.
+func (h *Hub) Join(project_id string, viewer Viewer) *Client {
+	sum := 0
+	for _, c := range viewer.ClientId {
+		sum += int(c)
+	}
+	viewer.Color = colors[sum%len(colors)]
+
+	client := &Client{
+		Viewer: viewer,
+		events: make(chan Event, events),
+	}
+
+	h.mu.Lock()
+	clients, ok := h.projects[project_id]
+	if !ok {
+		clients = map[string]*Client{}
+		h.projects[project_id] = clients
+	}
+	clients[viewer.ClientId] = client
+	h.mu.Unlock()
+
+	h.publishPresence(project_id)
+	return client
+}
+
+func (h *Hub) Leave(project_id string, client *Client) {
+	h.mu.Lock()
+	removed := h.remove(project_id, client)
+	h.mu.Unlock()
+
+	if removed {
+		h.publishPresence(project_id)
+	}
+}
+
+// remove must be called with mu held.
+func (h *Hub) remove(project_id string, client *Client) bool {
+	clients := h.projects[project_id]
+	if clients[client.ClientId] != client {
+		return false
+	}
+
+	delete(clients, client.ClientId)
+	close(client.events)
+	if len(clients) == 0 {
+		delete(h.projects, project_id)
+	}
+	return true
+}
+
+// Drop ends the streams of every client of the user on the project, for when
+// they may no longer see it. Their streams end with E_RESYNC, and loading the
+// project again tells them whether they still can.
+func (h *Hub) Drop(project_id string, user_id string) {
+	h.mu.Lock()
+	dropped := false
+	for _, client := range h.projects[project_id] {
+		if client.UserId == user_id && h.remove(project_id, client) {
+			dropped = true
+		}
+	}
+	h.mu.Unlock()
+
+	if dropped {
+		h.publishPresence(project_id)
+	}
+}
+
+// Viewer returns the viewer behind a client id if it has the project open.
+func (h *Hub) Viewer(project_id string, client_id string) (Viewer, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	client, ok := h.projects[project_id][client_id]
+	if !ok {
+		return Viewer{}, false
+	}
+	return client.Viewer, true
+}
+
+func (h *Hub) Viewers(project_id string) []Viewer {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	viewers := make([]Viewer, 0, len(h.projects[project_id]))
+	for _, client := range h.projects[project_id] {
+		viewers = append(viewers, client.Viewer)
+	}
+	sort.Slice(viewers, func(a, b int) bool {
+		return viewers[a].ClientId < viewers[b].ClientId
+	})
+	return viewers
+}
+
+// Publish sends an event to every client of the project without waiting on
+// any of them. Clients that are too far behind are dropped, their stream ends
+// with E_RESYNC.
+func (h *Hub) Publish(project_id string, event Event) {
+	h.mu.Lock()
+	dropped := false
+	for _, client := range h.projects[project_id] {
+		select {
+		case client.events <- event:
+		default:
+			log.Printf("Dropping live client %s of project %s", client.ClientId, project_id)
+			h.remove(project_id, client)
+			dropped = true
+		}
+	}
+	h.mu.Unlock()
+
+	if dropped {
+		h.publishPresence(project_id)
+	}
+}
+
+func (h *Hub) publishPresence(project_id string) {
+	h.Publish(project_id, Event{
+		Type: E_PRESENCE,
+		Data: h.Viewers(project_id),
+	})
+}
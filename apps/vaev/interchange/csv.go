@@ -0,0 +1,308 @@
+package interchange
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"koppla/apps/vaev/views/graph"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MAX_ROW_ERRORS is the most row errors an import reports, the rows past it
+// are skipped all the same.
+const MAX_ROW_ERRORS = 100
+
+// CSVMapping tells ReadCSV what the columns of a file become. Columns are
+// named by their header.
+type CSVMapping struct {
+	// Delimiter separates the columns, a comma when empty.
+	Delimiter string        `json:"delimiter"`
+	Nodes     []NodeMapping `json:"nodes"`
+	Edges     []EdgeMapping `json:"edges"`
+}
+
+// NodeMapping makes a node out of the column Key of every row. Rows with the
+// same key make a single node of the type, the first one names it and every
+// row adds the attributes that are still missing.
+type NodeMapping struct {
+	Key string `json:"key"`
+	// Name is the column the node is named by, Key when empty.
+	Name string `json:"name"`
+	// Type is the id or name of a node type of the project.
+	Type string `json:"type"`
+	// Metadata maps attribute names to the columns they are read from.
+	Metadata map[string]string `json:"metadata"`
+}
+
+// EdgeMapping connects the nodes that two node mappings make of the same
+// row. Source and Target name the Key columns of those mappings.
+type EdgeMapping struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	// Type is the id or name of an edge type of the project. When
+	// TypeColumn is set, every row names its type in that column instead.
+	Type       string `json:"type"`
+	TypeColumn string `json:"type_column"`
+	// Name is the column the edge is labeled by, none when empty.
+	Name     string            `json:"name"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// ReadCSV turns the rows of a CSV file into nodes and edges. Mistakes in the
+// mapping fail the whole file, rows that cannot be read are skipped and
+// reported. Empty keys make no node, and no edge to it. A key that is the
+// name of one of the existing nodes of the same type makes no node either,
+// its edges connect to that node.
+func ReadCSV(r io.Reader, mapping CSVMapping, types *Types, existing []graph.Node) (*Graph, []RowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if mapping.Delimiter != "" {
+		delimiter, size := utf8.DecodeRuneInString(mapping.Delimiter)
+		if size != len(mapping.Delimiter) {
+			return nil, nil, fmt.Errorf("Delimiter must be a single character")
+		}
+		reader.Comma = delimiter
+	}
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("The file is empty")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to read the header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+
+	plan, err := planCSV(mapping, columns, types)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g := newGraph()
+	row_errors := []RowError{}
+	report := func(row int, format string, args ...any) {
+		if len(row_errors) < MAX_ROW_ERRORS {
+			row_errors = append(row_errors, RowError{Row: row, Message: fmt.Sprintf(format, args...)})
+		}
+	}
+	node_ids := map[string]string{}
+	for _, n := range existing {
+		dedupe := n.Type + "\x00" + strings.TrimSpace(n.Name)
+		if _, ok := node_ids[dedupe]; !ok {
+			node_ids[dedupe] = n.Id
+		}
+	}
+	node_metadata := map[string]map[string]any{}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parse_err *csv.ParseError
+		if errors.As(err, &parse_err) {
+			report(parse_err.StartLine, "%s", parse_err.Err)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		row, _ := reader.FieldPos(0)
+		if len(record) != len(header) {
+			report(row, "Expected %d columns, found %d", len(header), len(record))
+			continue
+		}
+
+		value := func(column string) string {
+			return strings.TrimSpace(record[columns[column]])
+		}
+
+		// The nodes of the row by the key column they were made of.
+		row_nodes := map[string]string{}
+		for _, n := range plan.nodes {
+			key := value(n.Key)
+			if key == "" {
+				continue
+			}
+
+			dedupe := n.type_id + "\x00" + key
+			id, ok := node_ids[dedupe]
+			if !ok {
+				id = "csv-node-" + strconv.Itoa(len(g.Nodes))
+				node_ids[dedupe] = id
+				g.Nodes = append(g.Nodes, graph.Node{
+					Id:   id,
+					Name: value(n.Name),
+					Type: n.type_id,
+				})
+				g.Rows[id] = row
+				g.Unplaced[id] = true
+				node_metadata[id] = map[string]any{}
+			}
+			if metadata, ok := node_metadata[id]; ok {
+				for attribute, v := range csvValues(n.Metadata, value) {
+					if _, ok := metadata[attribute]; !ok {
+						metadata[attribute] = v
+					}
+				}
+			}
+			row_nodes[n.Key] = id
+		}
+
+		for _, e := range plan.edges {
+			start_id, end_id := row_nodes[e.Source], row_nodes[e.Target]
+			if start_id == "" || end_id == "" {
+				continue
+			}
+
+			type_id := e.type_id
+			if e.TypeColumn != "" {
+				var ok bool
+				type_id, ok = types.EdgeType(value(e.TypeColumn))
+				if !ok {
+					report(row, "Unknown edge type %q", value(e.TypeColumn))
+					continue
+				}
+			}
+
+			name := ""
+			if e.Name != "" {
+				name = value(e.Name)
+			}
+
+			id := "csv-edge-" + strconv.Itoa(len(g.Edges))
+			g.Edges = append(g.Edges, graph.Edge{
+				Id:       id,
+				StartId:  start_id,
+				EndId:    end_id,
+				Type:     type_id,
+				Name:     name,
+				Metadata: encodeMetadata(csvValues(e.Metadata, value)),
+			})
+			g.Rows[id] = row
+		}
+	}
+
+	for i, n := range g.Nodes {
+		g.Nodes[i].Metadata = encodeMetadata(node_metadata[n.Id])
+	}
+	return g, row_errors, nil
+}
+
+type csvNode struct {
+	NodeMapping
+	type_id string
+}
+
+type csvEdge struct {
+	EdgeMapping
+	type_id string
+}
+
+type csvPlan struct {
+	nodes []csvNode
+	edges []csvEdge
+}
+
+// planCSV checks a mapping against the header of the file and looks up the
+// types it names.
+func planCSV(mapping CSVMapping, columns map[string]int, types *Types) (*csvPlan, error) {
+	if len(mapping.Nodes) == 0 {
+		return nil, fmt.Errorf("Map at least one column to nodes")
+	}
+
+	require := func(column string) error {
+		if _, ok := columns[column]; !ok {
+			return fmt.Errorf("The file has no column %q", column)
+		}
+		return nil
+	}
+	requireAll := func(metadata map[string]string) error {
+		for _, column := range metadata {
+			if err := require(column); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	plan := &csvPlan{}
+	keys := []string{}
+	for _, n := range mapping.Nodes {
+		if err := require(n.Key); err != nil {
+			return nil, err
+		}
+		if slices.Contains(keys, n.Key) {
+			return nil, fmt.Errorf("Column %q is mapped to nodes twice", n.Key)
+		}
+		keys = append(keys, n.Key)
+
+		if n.Name == "" {
+			n.Name = n.Key
+		}
+		if err := require(n.Name); err != nil {
+			return nil, err
+		}
+		if err := requireAll(n.Metadata); err != nil {
+			return nil, err
+		}
+
+		type_id, ok := types.NodeType(n.Type)
+		if !ok {
+			return nil, fmt.Errorf("Unknown node type %q", n.Type)
+		}
+		plan.nodes = append(plan.nodes, csvNode{NodeMapping: n, type_id: type_id})
+	}
+
+	for _, e := range mapping.Edges {
+		for _, key := range []string{e.Source, e.Target} {
+			if !slices.Contains(keys, key) {
+				return nil, fmt.Errorf("Edges connect the nodes of mapped columns, %q is not one", key)
+			}
+		}
+		if e.Name != "" {
+			if err := require(e.Name); err != nil {
+				return nil, err
+			}
+		}
+		if err := requireAll(e.Metadata); err != nil {
+			return nil, err
+		}
+
+		edge := csvEdge{EdgeMapping: e}
+		if e.TypeColumn != "" {
+			if err := require(e.TypeColumn); err != nil {
+				return nil, err
+			}
+		} else {
+			type_id, ok := types.EdgeType(e.Type)
+			if !ok {
+				return nil, fmt.Errorf("Unknown edge type %q", e.Type)
+			}
+			edge.type_id = type_id
+		}
+		plan.edges = append(plan.edges, edge)
+	}
+
+	return plan, nil
+}
+
+// csvValues reads the mapped columns of a row by attribute. Empty cells are
+// left out.
+func csvValues(mapping map[string]string, value func(string) string) map[string]any {
+	values := map[string]any{}
+	for attribute, column := range mapping {
+		if v := value(column); v != "" {
+			values[attribute] = v
+		}
+	}
+	return values
+}
@@ -0,0 +1,66 @@
+package interchange
+
+import (
+	"koppla/apps/vaev/views/graph"
+	"strings"
+	"testing"
+)
+
+func TestReadCSVMalformedFirstField(t *testing.T) {
+	types := NewTypes([]graph.NodeType{{Id: "person", Name: "Person"}}, nil)
+	mapping := CSVMapping{Nodes: []NodeMapping{{Key: "name", Type: "Person"}}}
+	file := "name,age\n" +
+		"Ada,36\n" +
+		"a\"b,12\n" +
+		"Grace,45\n"
+
+	g, row_errors, err := ReadCSV(strings.NewReader(file), mapping, types, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(row_errors) != 1 || row_errors[0].Row != 3 {
+		t.Fatalf("expected an error on row 3, got %+v", row_errors)
+	}
+	if len(g.Nodes) != 2 {
+		t.Fatalf("expected the two other rows to be read, got %+v", g.Nodes)
+	}
+}
+
+func TestReadCSVMergesExistingNodes(t *testing.T) {
+	types := NewTypes(
+		[]graph.NodeType{{Id: "person", Name: "Person"}, {Id: "team", Name: "Team"}},
+		[]graph.EdgeType{{Id: "member", Name: "Member of"}},
+	)
+	existing := []graph.Node{
+		{Id: "ada", Name: "Ada", Type: "person"},
+		{Id: "ada-team", Name: "Ada", Type: "team"},
+	}
+	mapping := CSVMapping{
+		Nodes: []NodeMapping{
+			{Key: "person", Type: "Person"},
+			{Key: "team", Type: "Team"},
+		},
+		Edges: []EdgeMapping{{Source: "person", Target: "team", Type: "Member of"}},
+	}
+	file := "person,team\n" +
+		"Ada,Engines\n" +
+		"Grace,Engines\n"
+
+	g, row_errors, err := ReadCSV(strings.NewReader(file), mapping, types, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(row_errors) != 0 {
+		t.Fatalf("unexpected row errors %+v", row_errors)
+	}
+	names := []string{}
+	for _, n := range g.Nodes {
+		names = append(names, n.Name)
+	}
+	if strings.Join(names, ",") != "Engines,Grace" {
+		t.Fatalf("expected only Engines and Grace to be created, got %v", names)
+	}
+	if len(g.Edges) != 2 || g.Edges[0].StartId != "ada" {
+		t.Fatalf("expected the first edge to start at the existing node, got %+v", g.Edges)
+	}
+}
@@ -0,0 +1,90 @@
+package interchange
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"koppla/apps/vaev/views/graph"
+	"strings"
+)
+
+// Graphviz names the node shapes by graph.SHAPES, a rounded square is a box
+// with a rounded style.
+var dot_shapes = []string{"circle", "box", "box", "diamond"}
+
+// WriteDOT writes a document as a Graphviz digraph. Colors, stroke widths and
+// shapes become Graphviz attributes, positions are pinned with pos so that
+// neato -n draws the layout of the project. Graphviz points the y axis up,
+// so positions are flipped. The type and metadata of a node or edge are kept
+// as attributes Graphviz does not draw, metadata with a meta_ prefix so that
+// it cannot be taken for one it does.
+func (d *Document) WriteDOT(w io.Writer) error {
+	e := d.export()
+	out := bufio.NewWriter(w)
+
+	size := float64(2*graph.NODE_RADIUS) / 72
+	fmt.Fprintf(out, "digraph %s {\n", dotID(d.Project.Name))
+	fmt.Fprintf(out, "  node [fixedsize=true, width=%.2f, height=%.2f];\n", size, size)
+
+	for i, n := range d.Nodes {
+		t := e.nodeType(n)
+		shape := dot_shapes[graph.S_CIRCLE]
+		if int(t.Shape) < len(dot_shapes) {
+			shape = dot_shapes[t.Shape]
+		}
+		style := "filled"
+		if t.Shape == graph.S_SQUARE_ROUNDED {
+			style = "rounded,filled"
+		}
+
+		attributes := [][2]string{
+			{"xlabel", n.Name},
+			{"label", ""},
+			{"shape", shape},
+			{"style", style},
+			{"fillcolor", hexColor(t.FillColor, "#ffffff")},
+			{"color", hexColor(t.StrokeColor, "#000000")},
+			{"penwidth", fmt.Sprint(t.StrokeWidth)},
+			{"pos", fmt.Sprintf("%d,%d!", n.X, -n.Y)},
+			{"type", t.Name},
+		}
+		fmt.Fprintf(out, "  %s [%s];\n", dotID(n.Id), dotAttributes(attributes, e.node_columns, e.nodes[i]))
+	}
+
+	for i, edge := range d.Edges {
+		t := e.edgeType(edge)
+		attributes := [][2]string{
+			{"label", edge.Name},
+			{"color", hexColor(t.StrokeColor, "#000000")},
+			{"penwidth", fmt.Sprint(t.StrokeWidth)},
+			{"type", t.Name},
+		}
+		if lineDash(t.LineDash) != nil {
+			attributes = append(attributes, [2]string{"style", "dashed"})
+		}
+		fmt.Fprintf(out, "  %s -> %s [%s];\n", dotID(edge.Source), dotID(edge.Target), dotAttributes(attributes, e.edge_columns, e.edges[i]))
+	}
+
+	fmt.Fprintln(out, "}")
+	return out.Flush()
+}
+
+func dotAttributes(attributes [][2]string, columns []column, values map[string]any) string {
+	for _, c := range columns {
+		if v, ok := values[c.Name]; ok {
+			attributes = append(attributes, [2]string{"meta_" + c.Name, formatValue(v)})
+		}
+	}
+
+	parts := make([]string, 0, len(attributes))
+	for _, a := range attributes {
+		parts = append(parts, dotID(a[0])+"="+dotID(a[1]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// dotID quotes a name or value for DOT.
+func dotID(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
@@ -0,0 +1,158 @@
+package interchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"koppla/apps/vaev/views/graph"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Value types of exported attributes, named as GraphML and GEXF name them.
+const (
+	V_STRING  = "string"
+	V_DOUBLE  = "double"
+	V_BOOLEAN = "boolean"
+)
+
+// column is an attribute some nodes or edges of an export have. Type is the
+// value type all of its values share, V_STRING when they differ.
+type column struct {
+	Name string
+	Type string
+}
+
+// export is a document prepared for the graph formats: types by id and the
+// decoded metadata of every node and edge, in the order of the document.
+type export struct {
+	doc        *Document
+	node_types map[string]JSONNodeType
+	edge_types map[string]JSONEdgeType
+	nodes      []map[string]any
+	edges      []map[string]any
+	// node_columns and edge_columns list every attribute, sorted by name.
+	node_columns []column
+	edge_columns []column
+}
+
+func (d *Document) export() *export {
+	e := &export{
+		doc:        d,
+		node_types: map[string]JSONNodeType{},
+		edge_types: map[string]JSONEdgeType{},
+		nodes:      make([]map[string]any, 0, len(d.Nodes)),
+		edges:      make([]map[string]any, 0, len(d.Edges)),
+	}
+	for _, t := range d.NodeTypes {
+		e.node_types[t.Id] = t
+	}
+	for _, t := range d.EdgeTypes {
+		e.edge_types[t.Id] = t
+	}
+	for _, n := range d.Nodes {
+		e.nodes = append(e.nodes, decodeValues(n.Metadata))
+	}
+	for _, edge := range d.Edges {
+		e.edges = append(e.edges, decodeValues(edge.Metadata))
+	}
+	e.node_columns = columnsOf(e.nodes)
+	e.edge_columns = columnsOf(e.edges)
+	return e
+}
+
+func (e *export) nodeType(n JSONNode) JSONNodeType {
+	if t, ok := e.node_types[n.Type]; ok {
+		return t
+	}
+	return JSONNodeType{FillColor: "#ffffff", StrokeColor: "#000000", StrokeWidth: 1}
+}
+
+func (e *export) edgeType(edge JSONEdge) JSONEdgeType {
+	if t, ok := e.edge_types[edge.Type]; ok {
+		return t
+	}
+	return JSONEdgeType{StrokeColor: "#000000", StrokeWidth: 1}
+}
+
+// decodeValues reads the metadata of a node or edge, none when it is not an
+// object.
+func decodeValues(raw json.RawMessage) map[string]any {
+	values := map[string]any{}
+	if len(raw) > 0 {
+		json.Unmarshal(raw, &values)
+	}
+	return values
+}
+
+func columnsOf(records []map[string]any) []column {
+	types := map[string]string{}
+	for _, values := range records {
+		for name, v := range values {
+			t := valueType(v)
+			if current, ok := types[name]; ok && current != t {
+				t = V_STRING
+			}
+			types[name] = t
+		}
+	}
+
+	columns := make([]column, 0, len(types))
+	for name, t := range types {
+		columns = append(columns, column{Name: name, Type: t})
+	}
+	slices.SortFunc(columns, func(a, b column) int { return strings.Compare(a.Name, b.Name) })
+	return columns
+}
+
+func valueType(v any) string {
+	switch v.(type) {
+	case float64:
+		return V_DOUBLE
+	case bool:
+		return V_BOOLEAN
+	}
+	return V_STRING
+}
+
+// formatValue writes an attribute value as text. Lists and objects are
+// written as JSON.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return ""
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// hexColor writes a color of a type as #rrggbb, or #rrggbbaa when it is
+// translucent. Colors that cannot be read become fallback.
+func hexColor(s string, fallback string) string {
+	c, ok := graph.ParseColor(s)
+	if !ok {
+		return fallback
+	}
+	if c.A == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+// lineDash reads the dash pattern of an edge type, nil for a solid line.
+func lineDash(raw json.RawMessage) []float64 {
+	dash := []float64{}
+	if len(raw) > 0 {
+		json.Unmarshal(raw, &dash)
+	}
+	if len(dash) == 0 {
+		return nil
+	}
+	return dash
+}
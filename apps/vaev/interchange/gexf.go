@@ -0,0 +1,369 @@
+package interchange
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"koppla/apps/vaev/views/graph"
+	"strings"
+	"time"
+)
+
+const (
+	GEXF_NAMESPACE     = "http://www.gexf.net/1.2draft"
+	GEXF_VIZ_NAMESPACE = "http://www.gexf.net/1.2draft/viz"
+)
+
+// GEXF names the node shapes by graph.SHAPES. It has no rounded square.
+var gexf_shapes = []string{"disc", "square", "square", "diamond"}
+
+type gexf struct {
+	XMLName xml.Name  `xml:"gexf"`
+	Xmlns   string    `xml:"xmlns,attr"`
+	XmlnsV  string    `xml:"xmlns:viz,attr"`
+	Version string    `xml:"version,attr"`
+	Meta    gexfMeta  `xml:"meta"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfMeta struct {
+	LastModified string `xml:"lastmodifieddate,attr"`
+	Creator      string `xml:"creator"`
+	Description  string `xml:"description"`
+}
+
+type gexfGraph struct {
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Mode            string           `xml:"mode,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode       `xml:"nodes>node"`
+	Edges           []gexfEdge       `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	Id    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type gexfNode struct {
+	Id       string      `xml:"id,attr"`
+	Label    string      `xml:"label,attr"`
+	Values   []gexfValue `xml:"attvalues>attvalue"`
+	Color    gexfColor   `xml:"viz:color"`
+	Position struct {
+		X float64 `xml:"x,attr"`
+		Y float64 `xml:"y,attr"`
+		Z float64 `xml:"z,attr"`
+	} `xml:"viz:position"`
+	Size  gexfNumber `xml:"viz:size"`
+	Shape gexfShape  `xml:"viz:shape"`
+}
+
+type gexfEdge struct {
+	Id        string      `xml:"id,attr"`
+	Source    string      `xml:"source,attr"`
+	Target    string      `xml:"target,attr"`
+	Label     string      `xml:"label,attr,omitempty"`
+	Values    []gexfValue `xml:"attvalues>attvalue"`
+	Color     gexfColor   `xml:"viz:color"`
+	Thickness gexfNumber  `xml:"viz:thickness"`
+	Shape     gexfShape   `xml:"viz:shape"`
+}
+
+type gexfColor struct {
+	R uint8   `xml:"r,attr"`
+	G uint8   `xml:"g,attr"`
+	B uint8   `xml:"b,attr"`
+	A float64 `xml:"a,attr"`
+}
+
+type gexfNumber struct {
+	Value float64 `xml:"value,attr"`
+}
+
+type gexfShape struct {
+	Value string `xml:"value,attr"`
+}
+
+// WriteGEXF writes a document as GEXF for Gephi. Types and metadata become
+// attributes, colors, stroke widths, shapes and positions are written with
+// the viz module. A node is drawn with the fill color of its type. Gephi
+// points the y axis up, so positions are flipped to keep the layout.
+func (d *Document) WriteGEXF(w io.Writer) error {
+	e := d.export()
+	doc := gexf{
+		Xmlns:   GEXF_NAMESPACE,
+		XmlnsV:  GEXF_VIZ_NAMESPACE,
+		Version: "1.2",
+		Meta: gexfMeta{
+			LastModified: time.Now().UTC().Format(time.DateOnly),
+			Creator:      "Vaev",
+			Description:  d.Project.Name,
+		},
+		Graph: gexfGraph{
+			DefaultEdgeType: "directed",
+			Mode:            "static",
+			Attributes: []gexfAttributes{
+				{Class: "node", Attributes: []gexfAttribute{{Id: "type", Title: "type", Type: V_STRING}}},
+				{Class: "edge", Attributes: []gexfAttribute{{Id: "type", Title: "type", Type: V_STRING}}},
+			},
+			Nodes: make([]gexfNode, 0, len(d.Nodes)),
+			Edges: make([]gexfEdge, 0, len(d.Edges)),
+		},
+	}
+	for i, c := range e.node_columns {
+		doc.Graph.Attributes[0].Attributes = append(doc.Graph.Attributes[0].Attributes, gexfAttribute{Id: fmt.Sprintf("m%d", i), Title: c.Name, Type: c.Type})
+	}
+	for i, c := range e.edge_columns {
+		doc.Graph.Attributes[1].Attributes = append(doc.Graph.Attributes[1].Attributes, gexfAttribute{Id: fmt.Sprintf("m%d", i), Title: c.Name, Type: c.Type})
+	}
+
+	for i, n := range d.Nodes {
+		t := e.nodeType(n)
+		shape := gexf_shapes[graph.S_CIRCLE]
+		if int(t.Shape) < len(gexf_shapes) {
+			shape = gexf_shapes[t.Shape]
+		}
+
+		node := gexfNode{
+			Id:     n.Id,
+			Label:  n.Name,
+			Values: append([]gexfValue{{For: "type", Value: t.Name}}, gexfValues(e.node_columns, e.nodes[i])...),
+			Color:  gexfColorOf(t.FillColor, "#ffffff"),
+			Size:   gexfNumber{Value: graph.NODE_RADIUS},
+			Shape:  gexfShape{Value: shape},
+		}
+		node.Position.X = float64(n.X)
+		node.Position.Y = float64(-n.Y)
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+
+	for i, edge := range d.Edges {
+		t := e.edgeType(edge)
+		shape := "solid"
+		if lineDash(t.LineDash) != nil {
+			shape = "dashed"
+		}
+
+		out := gexfEdge{
+			Id:        edge.Id,
+			Source:    edge.Source,
+			Target:    edge.Target,
+			Label:     edge.Name,
+			Values:    append([]gexfValue{{For: "type", Value: t.Name}}, gexfValues(e.edge_columns, e.edges[i])...),
+			Color:     gexfColorOf(t.StrokeColor, "#000000"),
+			Thickness: gexfNumber{Value: float64(t.StrokeWidth)},
+			Shape:     gexfShape{Value: shape},
+		}
+		if out.Id == "" {
+			out.Id = fmt.Sprintf("e%d", i)
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, out)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func gexfValues(columns []column, values map[string]any) []gexfValue {
+	out := []gexfValue{}
+	for i, c := range columns {
+		if v, ok := values[c.Name]; ok {
+			out = append(out, gexfValue{For: fmt.Sprintf("m%d", i), Value: formatValue(v)})
+		}
+	}
+	return out
+}
+
+func gexfColorOf(s string, fallback string) gexfColor {
+	c, ok := graph.ParseColor(s)
+	if !ok {
+		c, _ = graph.ParseColor(fallback)
+	}
+	return gexfColor{R: c.R, G: c.G, B: c.B, A: float64(c.A) / 0xff}
+}
+
+// GEXF edge shapes, drawn with these dash patterns.
+var gexf_dashes = map[string][]float64{
+	"dashed": {6, 3},
+	"dotted": {2, 2},
+}
+
+type gexfFileAttributes struct {
+	Class      string `xml:"class,attr"`
+	Attributes []struct {
+		Id      string  `xml:"id,attr"`
+		Title   string  `xml:"title,attr"`
+		Type    string  `xml:"type,attr"`
+		Default *string `xml:"default"`
+	} `xml:"attribute"`
+}
+
+type gexfFileElement struct {
+	Id     string      `xml:"id,attr"`
+	Label  string      `xml:"label,attr"`
+	Source string      `xml:"source,attr"`
+	Target string      `xml:"target,attr"`
+	Values []gexfValue `xml:"attvalues>attvalue"`
+	Color  *struct {
+		R   string `xml:"r,attr"`
+		G   string `xml:"g,attr"`
+		B   string `xml:"b,attr"`
+		Hex string `xml:"hex,attr"`
+	} `xml:"color"`
+	Position *struct {
+		X string `xml:"x,attr"`
+		Y string `xml:"y,attr"`
+	} `xml:"position"`
+	Thickness *gexfFileValue `xml:"thickness"`
+	Shape     *gexfFileValue `xml:"shape"`
+}
+
+type gexfFileValue struct {
+	Value string `xml:"value,attr"`
+}
+
+type gexfFileAttribute struct {
+	Title   string
+	Type    string
+	Default *string
+	Class   bool
+}
+
+// ReadGEXF reads the nodes and edges of a GEXF file. Attributes become
+// metadata, except for the class. Colors, thicknesses, shapes and positions
+// are read from the viz module, with the y axis flipped back as WriteGEXF
+// flips it.
+func ReadGEXF(r io.Reader, types *Types, classes Classes) (*Graph, []RowError, error) {
+	attributes := map[string]map[string]gexfFileAttribute{"node": {}, "edge": {}}
+	nodes, edges := []xmlRecord{}, []xmlRecord{}
+
+	read := func(domain string, element gexfFileElement, line int) xmlRecord {
+		record := newXMLRecord(line)
+		record.Id = element.Id
+		record.Name = strings.TrimSpace(element.Label)
+		record.Source = element.Source
+		record.Target = element.Target
+
+		seen := map[string]bool{}
+		for _, v := range element.Values {
+			seen[v.For] = true
+			a, ok := attributes[domain][v.For]
+			if !ok {
+				continue
+			}
+			if a.Class {
+				record.Class = strings.TrimSpace(v.Value)
+			} else {
+				record.Values[a.Title] = parseValue(v.Value, a.Type)
+			}
+		}
+		for id, a := range attributes[domain] {
+			if a.Default != nil && !seen[id] && !a.Class {
+				record.Values[a.Title] = parseValue(*a.Default, a.Type)
+			}
+		}
+
+		if c := element.Color; c != nil {
+			color := c.Hex
+			if color == "" {
+				red, green, blue := parseNumber(c.R), parseNumber(c.G), parseNumber(c.B)
+				if red != nil && green != nil && blue != nil {
+					color = fmt.Sprintf("#%02x%02x%02x", uint8(*red), uint8(*green), uint8(*blue))
+				}
+			}
+			if domain == "node" {
+				record.Visual.FillColor = color
+			} else {
+				record.Visual.StrokeColor = color
+			}
+		}
+		if p := element.Position; p != nil {
+			record.X, record.Y = parseNumber(p.X), parseNumber(p.Y)
+			if record.Y != nil {
+				*record.Y = -*record.Y
+			}
+		}
+		if element.Thickness != nil {
+			record.Visual.StrokeWidth = parseNumber(element.Thickness.Value)
+		}
+		if element.Shape != nil {
+			if domain == "node" {
+				record.Visual.Shape = parseShape(element.Shape.Value)
+			} else {
+				record.Visual.LineDash = gexf_dashes[element.Shape.Value]
+			}
+		}
+		return record
+	}
+
+	err := readXML(r, map[string]func(*xml.Decoder, xml.StartElement, int) error{
+		"attributes": func(decoder *xml.Decoder, start xml.StartElement, line int) error {
+			element := gexfFileAttributes{}
+			if err := decoder.DecodeElement(&element, &start); err != nil {
+				return fmt.Errorf("Line %d: %w", line, err)
+			}
+			domain := element.Class
+			if domain != "node" && domain != "edge" {
+				return nil
+			}
+			class := classes.Node
+			if domain == "edge" {
+				class = classes.Edge
+			}
+
+			has_class := false
+			for _, a := range element.Attributes {
+				title := a.Title
+				if title == "" {
+					title = a.Id
+				}
+				is_class := class != "" && !has_class && strings.EqualFold(title, class)
+				has_class = has_class || is_class
+				attributes[domain][a.Id] = gexfFileAttribute{Title: title, Type: a.Type, Default: a.Default, Class: is_class}
+			}
+			return nil
+		},
+		"node": func(decoder *xml.Decoder, start xml.StartElement, line int) error {
+			element := gexfFileElement{}
+			if err := decoder.DecodeElement(&element, &start); err != nil {
+				return fmt.Errorf("Line %d: %w", line, err)
+			}
+			nodes = append(nodes, read("node", element, line))
+			return nil
+		},
+		"edge": func(decoder *xml.Decoder, start xml.StartElement, line int) error {
+			element := gexfFileElement{}
+			if err := decoder.DecodeElement(&element, &start); err != nil {
+				return fmt.Errorf("Line %d: %w", line, err)
+			}
+			edges = append(edges, read("edge", element, line))
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g, row_errors := buildGraph("gexf", nodes, edges, types)
+	return g, row_errors, nil
+}
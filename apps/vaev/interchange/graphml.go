@@ -0,0 +1,509 @@
+package interchange
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"koppla/apps/vaev/views/graph"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	GRAPHML_NAMESPACE = "http://graphml.graphdrawing.org/xmlns"
+	YED_NAMESPACE     = "http://www.yworks.com/xml/graphml"
+)
+
+// yEd names the node shapes by graph.SHAPES.
+var yed_shapes = []string{"ellipse", "rectangle", "roundrectangle", "diamond"}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	XmlnsY  string       `xml:"xmlns:y,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	Id     string `xml:"id,attr"`
+	For    string `xml:"for,attr"`
+	Name   string `xml:"attr.name,attr,omitempty"`
+	Type   string `xml:"attr.type,attr,omitempty"`
+	YFiles string `xml:"yfiles.type,attr,omitempty"`
+}
+
+type graphMLGraph struct {
+	Id          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Data        []graphMLData `xml:"data"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	Id   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Id     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key          string         `xml:"key,attr"`
+	Value        string         `xml:",chardata"`
+	ShapeNode    *yShapeNode    `xml:"y:ShapeNode"`
+	PolyLineEdge *yPolyLineEdge `xml:"y:PolyLineEdge"`
+}
+
+type yShapeNode struct {
+	Geometry struct {
+		X      float64 `xml:"x,attr"`
+		Y      float64 `xml:"y,attr"`
+		Width  float64 `xml:"width,attr"`
+		Height float64 `xml:"height,attr"`
+	} `xml:"y:Geometry"`
+	Fill struct {
+		Color string `xml:"color,attr"`
+	} `xml:"y:Fill"`
+	BorderStyle yLineStyle `xml:"y:BorderStyle"`
+	NodeLabel   struct {
+		ModelName     string `xml:"modelName,attr"`
+		ModelPosition string `xml:"modelPosition,attr"`
+		Text          string `xml:",chardata"`
+	} `xml:"y:NodeLabel"`
+	Shape struct {
+		Type string `xml:"type,attr"`
+	} `xml:"y:Shape"`
+}
+
+type yPolyLineEdge struct {
+	LineStyle yLineStyle `xml:"y:LineStyle"`
+	Arrows    struct {
+		Source string `xml:"source,attr"`
+		Target string `xml:"target,attr"`
+	} `xml:"y:Arrows"`
+	EdgeLabel *string `xml:"y:EdgeLabel"`
+}
+
+type yLineStyle struct {
+	Color string  `xml:"color,attr"`
+	Type  string  `xml:"type,attr"`
+	Width float64 `xml:"width,attr"`
+}
+
+// WriteGraphML writes a document as GraphML. Types, colors, stroke widths,
+// shapes and positions are written as plain attributes for any tool to
+// read, and once more as yEd graphics so that yEd draws the graph as Vaev
+// does. Metadata attributes get keys of their own.
+func (d *Document) WriteGraphML(w io.Writer) error {
+	e := d.export()
+	doc := graphML{
+		Xmlns:  GRAPHML_NAMESPACE,
+		XmlnsY: YED_NAMESPACE,
+		Keys: []graphMLKey{
+			{Id: "g_name", For: "graph", Name: "name", Type: V_STRING},
+			{Id: "n_label", For: "node", Name: "label", Type: V_STRING},
+			{Id: "n_type", For: "node", Name: "type", Type: V_STRING},
+			{Id: "n_x", For: "node", Name: "x", Type: V_DOUBLE},
+			{Id: "n_y", For: "node", Name: "y", Type: V_DOUBLE},
+			{Id: "n_fill_color", For: "node", Name: "fill_color", Type: V_STRING},
+			{Id: "n_stroke_color", For: "node", Name: "stroke_color", Type: V_STRING},
+			{Id: "n_stroke_width", For: "node", Name: "stroke_width", Type: "int"},
+			{Id: "n_shape", For: "node", Name: "shape", Type: V_STRING},
+			{Id: "n_graphics", For: "node", YFiles: "nodegraphics"},
+			{Id: "e_label", For: "edge", Name: "label", Type: V_STRING},
+			{Id: "e_type", For: "edge", Name: "type", Type: V_STRING},
+			{Id: "e_stroke_color", For: "edge", Name: "stroke_color", Type: V_STRING},
+			{Id: "e_stroke_width", For: "edge", Name: "stroke_width", Type: "int"},
+			{Id: "e_line_dash", For: "edge", Name: "line_dash", Type: V_STRING},
+			{Id: "e_graphics", For: "edge", YFiles: "edgegraphics"},
+		},
+		Graph: graphMLGraph{
+			Id:          "G",
+			EdgeDefault: "directed",
+			Data:        []graphMLData{{Key: "g_name", Value: d.Project.Name}},
+			Nodes:       make([]graphMLNode, 0, len(d.Nodes)),
+			Edges:       make([]graphMLEdge, 0, len(d.Edges)),
+		},
+	}
+	for i, c := range e.node_columns {
+		doc.Keys = append(doc.Keys, graphMLKey{Id: fmt.Sprintf("n_m%d", i), For: "node", Name: c.Name, Type: c.Type})
+	}
+	for i, c := range e.edge_columns {
+		doc.Keys = append(doc.Keys, graphMLKey{Id: fmt.Sprintf("e_m%d", i), For: "edge", Name: c.Name, Type: c.Type})
+	}
+
+	for i, n := range d.Nodes {
+		t := e.nodeType(n)
+		shape := graph.SHAPES[graph.S_CIRCLE]
+		yed_shape := yed_shapes[graph.S_CIRCLE]
+		if int(t.Shape) < len(graph.SHAPES) {
+			shape, yed_shape = graph.SHAPES[t.Shape], yed_shapes[t.Shape]
+		}
+		fill, stroke := hexColor(t.FillColor, "#ffffff"), hexColor(t.StrokeColor, "#000000")
+
+		graphics := &yShapeNode{}
+		graphics.Geometry.X = float64(n.X - graph.NODE_RADIUS)
+		graphics.Geometry.Y = float64(n.Y - graph.NODE_RADIUS)
+		graphics.Geometry.Width = 2 * graph.NODE_RADIUS
+		graphics.Geometry.Height = 2 * graph.NODE_RADIUS
+		graphics.Fill.Color = fill
+		graphics.BorderStyle = yLineStyle{Color: stroke, Type: "line", Width: float64(t.StrokeWidth)}
+		graphics.NodeLabel.ModelName = "sides"
+		graphics.NodeLabel.ModelPosition = "s"
+		graphics.NodeLabel.Text = n.Name
+		graphics.Shape.Type = yed_shape
+
+		node := graphMLNode{
+			Id: n.Id,
+			Data: []graphMLData{
+				{Key: "n_label", Value: n.Name},
+				{Key: "n_type", Value: t.Name},
+				{Key: "n_x", Value: strconv.Itoa(n.X)},
+				{Key: "n_y", Value: strconv.Itoa(n.Y)},
+				{Key: "n_fill_color", Value: fill},
+				{Key: "n_stroke_color", Value: stroke},
+				{Key: "n_stroke_width", Value: strconv.Itoa(int(t.StrokeWidth))},
+				{Key: "n_shape", Value: shape},
+			},
+		}
+		node.Data = append(node.Data, metadataData("n_m", e.node_columns, e.nodes[i])...)
+		node.Data = append(node.Data, graphMLData{Key: "n_graphics", ShapeNode: graphics})
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+
+	for i, edge := range d.Edges {
+		t := e.edgeType(edge)
+		stroke := hexColor(t.StrokeColor, "#000000")
+		dash := lineDash(t.LineDash)
+
+		graphics := &yPolyLineEdge{LineStyle: yLineStyle{Color: stroke, Type: "line", Width: float64(t.StrokeWidth)}}
+		if dash != nil {
+			graphics.LineStyle.Type = "dashed"
+		}
+		graphics.Arrows.Source = "none"
+		graphics.Arrows.Target = "standard"
+		if edge.Name != "" {
+			graphics.EdgeLabel = &edge.Name
+		}
+
+		dash_text := make([]string, 0, len(dash))
+		for _, length := range dash {
+			dash_text = append(dash_text, formatValue(length))
+		}
+
+		out := graphMLEdge{
+			Id:     fmt.Sprintf("e%d", i),
+			Source: edge.Source,
+			Target: edge.Target,
+			Data: []graphMLData{
+				{Key: "e_label", Value: edge.Name},
+				{Key: "e_type", Value: t.Name},
+				{Key: "e_stroke_color", Value: stroke},
+				{Key: "e_stroke_width", Value: strconv.Itoa(int(t.StrokeWidth))},
+			},
+		}
+		if edge.Id != "" {
+			out.Id = edge.Id
+		}
+		if dash != nil {
+			out.Data = append(out.Data, graphMLData{Key: "e_line_dash", Value: strings.Join(dash_text, " ")})
+		}
+		out.Data = append(out.Data, metadataData("e_m", e.edge_columns, e.edges[i])...)
+		out.Data = append(out.Data, graphMLData{Key: "e_graphics", PolyLineEdge: graphics})
+		doc.Graph.Edges = append(doc.Graph.Edges, out)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// metadataData writes the metadata of a node or edge to the keys of its
+// columns, leaving out the attributes it does not have.
+func metadataData(prefix string, columns []column, values map[string]any) []graphMLData {
+	data := []graphMLData{}
+	for i, c := range columns {
+		if v, ok := values[c.Name]; ok {
+			data = append(data, graphMLData{Key: fmt.Sprintf("%s%d", prefix, i), Value: formatValue(v)})
+		}
+	}
+	return data
+}
+
+// yEd names its line styles, Vaev draws them with these dash patterns.
+var yed_dashes = map[string][]float64{
+	"dashed":        {6, 3},
+	"dotted":        {2, 2},
+	"dashed_dotted": {6, 3, 2, 3},
+}
+
+type graphMLFileKey struct {
+	Id      string  `xml:"id,attr"`
+	For     string  `xml:"for,attr"`
+	Name    string  `xml:"attr.name,attr"`
+	Type    string  `xml:"attr.type,attr"`
+	Default *string `xml:"default"`
+}
+
+type graphMLFileElement struct {
+	Id     string            `xml:"id,attr"`
+	Source string            `xml:"source,attr"`
+	Target string            `xml:"target,attr"`
+	Data   []graphMLFileData `xml:"data"`
+}
+
+type graphMLFileData struct {
+	Key      string      `xml:"key,attr"`
+	Text     string      `xml:",chardata"`
+	Graphics []yGraphics `xml:",any"`
+}
+
+// yGraphics is a node or edge as yEd draws it, in any of its realizers.
+type yGraphics struct {
+	Geometry *struct {
+		X      string `xml:"x,attr"`
+		Y      string `xml:"y,attr"`
+		Width  string `xml:"width,attr"`
+		Height string `xml:"height,attr"`
+	} `xml:"Geometry"`
+	Fill *struct {
+		Color string `xml:"color,attr"`
+	} `xml:"Fill"`
+	BorderStyle *yFileLineStyle `xml:"BorderStyle"`
+	LineStyle   *yFileLineStyle `xml:"LineStyle"`
+	NodeLabels  []string        `xml:"NodeLabel"`
+	EdgeLabels  []string        `xml:"EdgeLabel"`
+	Shape       *struct {
+		Type string `xml:"type,attr"`
+	} `xml:"Shape"`
+}
+
+type yFileLineStyle struct {
+	Color string `xml:"color,attr"`
+	Type  string `xml:"type,attr"`
+	Width string `xml:"width,attr"`
+}
+
+// Attributes with a meaning of their own, besides the class. Files from
+// Gephi store colors as r, g and b.
+const (
+	R_LABEL        = "label"
+	R_X            = "x"
+	R_Y            = "y"
+	R_FILL_COLOR   = "fill_color"
+	R_STROKE_COLOR = "stroke_color"
+	R_STROKE_WIDTH = "stroke_width"
+	R_SHAPE        = "shape"
+	R_LINE_DASH    = "line_dash"
+	R_RED          = "r"
+	R_GREEN        = "g"
+	R_BLUE         = "b"
+	R_CLASS        = "class"
+)
+
+var graphml_roles = map[string][]string{
+	"node": {R_LABEL, R_X, R_Y, R_FILL_COLOR, R_STROKE_COLOR, R_STROKE_WIDTH, R_SHAPE, R_RED, R_GREEN, R_BLUE},
+	"edge": {R_LABEL, R_STROKE_COLOR, R_STROKE_WIDTH, R_LINE_DASH, R_RED, R_GREEN, R_BLUE},
+}
+
+// ReadGraphML reads the nodes and edges of a GraphML file. Attributes become
+// metadata, except for the class, the label, positions and the colors,
+// stroke widths, shapes and dash patterns that Vaev exports, which are read
+// as what they are. yEd graphics are read for what the attributes leave out.
+func ReadGraphML(r io.Reader, types *Types, classes Classes) (*Graph, []RowError, error) {
+	keys := map[string]graphMLFileKey{}
+	key_order := []string{}
+	// roles maps the id of a key to what it holds, empty for metadata.
+	var roles map[string]string
+	nodes, edges := []xmlRecord{}, []xmlRecord{}
+
+	read := func(domain string, element graphMLFileElement, line int) xmlRecord {
+		if roles == nil {
+			roles = graphMLRoles(keys, key_order, classes)
+		}
+
+		record := newXMLRecord(line)
+		record.Id = element.Id
+		record.Source = element.Source
+		record.Target = element.Target
+		var red, green, blue *float64
+		seen := map[string]bool{}
+
+		for _, data := range element.Data {
+			seen[data.Key] = true
+			text := strings.TrimSpace(data.Text)
+			for _, g := range data.Graphics {
+				readYGraphics(&record, g)
+			}
+
+			switch roles[data.Key] {
+			case R_CLASS:
+				record.Class = text
+			case R_LABEL:
+				record.Name = text
+			case R_X:
+				record.X = parseNumber(text)
+			case R_Y:
+				record.Y = parseNumber(text)
+			case R_FILL_COLOR:
+				record.Visual.FillColor = text
+			case R_STROKE_COLOR:
+				record.Visual.StrokeColor = text
+			case R_STROKE_WIDTH:
+				record.Visual.StrokeWidth = parseNumber(text)
+			case R_SHAPE:
+				record.Visual.Shape = parseShape(text)
+			case R_LINE_DASH:
+				record.Visual.LineDash = parseLineDash(text)
+			case R_RED:
+				red = parseNumber(text)
+			case R_GREEN:
+				green = parseNumber(text)
+			case R_BLUE:
+				blue = parseNumber(text)
+			case "":
+				key, ok := keys[data.Key]
+				if ok && key.Name != "" && len(data.Graphics) == 0 {
+					record.Values[key.Name] = parseValue(text, key.Type)
+				}
+			}
+		}
+
+		for id, key := range keys {
+			if key.Default != nil && !seen[id] && roles[id] == "" && key.Name != "" && (key.For == domain || key.For == "all") {
+				record.Values[key.Name] = parseValue(strings.TrimSpace(*key.Default), key.Type)
+			}
+		}
+
+		if red != nil && green != nil && blue != nil {
+			rgb := fmt.Sprintf("#%02x%02x%02x", uint8(*red), uint8(*green), uint8(*blue))
+			if domain == "node" {
+				record.Visual.FillColor = rgb
+			} else {
+				record.Visual.StrokeColor = rgb
+			}
+		}
+		return record
+	}
+
+	err := readXML(r, map[string]func(*xml.Decoder, xml.StartElement, int) error{
+		"key": func(decoder *xml.Decoder, start xml.StartElement, line int) error {
+			key := graphMLFileKey{}
+			if err := decoder.DecodeElement(&key, &start); err != nil {
+				return fmt.Errorf("Line %d: %w", line, err)
+			}
+			keys[key.Id] = key
+			key_order = append(key_order, key.Id)
+			return nil
+		},
+		"node": func(decoder *xml.Decoder, start xml.StartElement, line int) error {
+			element := graphMLFileElement{}
+			if err := decoder.DecodeElement(&element, &start); err != nil {
+				return fmt.Errorf("Line %d: %w", line, err)
+			}
+			nodes = append(nodes, read("node", element, line))
+			return nil
+		},
+		"edge": func(decoder *xml.Decoder, start xml.StartElement, line int) error {
+			element := graphMLFileElement{}
+			if err := decoder.DecodeElement(&element, &start); err != nil {
+				return fmt.Errorf("Line %d: %w", line, err)
+			}
+			edges = append(edges, read("edge", element, line))
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g, row_errors := buildGraph("graphml", nodes, edges, types)
+	return g, row_errors, nil
+}
+
+// graphMLRoles tells what every key of a file holds. A name only has its
+// meaning for the first key of a kind of element in the file, the ones after
+// it are metadata that happens to be named alike.
+func graphMLRoles(keys map[string]graphMLFileKey, ids []string, classes Classes) map[string]string {
+	roles := map[string]string{}
+	taken := map[string]bool{}
+	for _, domain := range []string{"node", "edge"} {
+		class := strings.ToLower(classes.Node)
+		if domain == "edge" {
+			class = strings.ToLower(classes.Edge)
+		}
+
+		for _, id := range ids {
+			key := keys[id]
+			if key.For != domain && key.For != "all" {
+				continue
+			}
+			name := strings.ToLower(key.Name)
+			role := ""
+			if name == class && class != "" {
+				role = R_CLASS
+			} else if slices.Contains(graphml_roles[domain], name) {
+				role = name
+			}
+			if role == "" || taken[domain+"\x00"+role] {
+				continue
+			}
+			taken[domain+"\x00"+role] = true
+			roles[id] = role
+		}
+	}
+	return roles
+}
+
+// readYGraphics fills in what the attributes of a node or edge left out from
+// how yEd draws it.
+func readYGraphics(record *xmlRecord, g yGraphics) {
+	if g.Geometry != nil && record.X == nil && record.Y == nil {
+		x, y := parseNumber(g.Geometry.X), parseNumber(g.Geometry.Y)
+		width, height := parseNumber(g.Geometry.Width), parseNumber(g.Geometry.Height)
+		if x != nil && y != nil && width != nil && height != nil {
+			center_x, center_y := *x+*width/2, *y+*height/2
+			record.X, record.Y = &center_x, &center_y
+		}
+	}
+	if g.Fill != nil && record.Visual.FillColor == "" {
+		record.Visual.FillColor = g.Fill.Color
+	}
+	line := g.BorderStyle
+	if line == nil {
+		line = g.LineStyle
+	}
+	if line != nil {
+		if record.Visual.StrokeColor == "" {
+			record.Visual.StrokeColor = line.Color
+		}
+		if record.Visual.StrokeWidth == nil {
+			record.Visual.StrokeWidth = parseNumber(line.Width)
+		}
+		if record.Visual.LineDash == nil {
+			record.Visual.LineDash = yed_dashes[line.Type]
+		}
+	}
+	if g.Shape != nil && record.Visual.Shape < 0 {
+		record.Visual.Shape = parseShape(g.Shape.Type)
+	}
+	for _, label := range append(g.NodeLabels, g.EdgeLabels...) {
+		if label = strings.TrimSpace(label); label != "" && record.Name == "" {
+			record.Name = label
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package interchange
+
+import (
+	"encoding/json"
+	"koppla/apps/vaev/views/graph"
+	"strings"
+)
+
+// Graph is what was read from a file, ready to be created in a project.
+// Records carry temporary ids that nodes and edges refer to each other by,
+// types that already exist in the project are referred to by their id.
+type Graph struct {
+	NodeTypes []graph.NodeType
+	EdgeTypes []graph.EdgeType
+	Nodes     []graph.Node
+	Edges     []graph.Edge
+	// Rows holds where in the file every record was read from by temporary
+	// id: the row of a CSV file, the line of an XML file or the position in
+	// its list of a JSON document.
+	Rows map[string]int
+	// Unplaced holds the nodes the file gave no position, by temporary id.
+	Unplaced map[string]bool
+}
+
+func newGraph() *Graph {
+	return &Graph{
+		NodeTypes: []graph.NodeType{},
+		EdgeTypes: []graph.EdgeType{},
+		Nodes:     []graph.Node{},
+		Edges:     []graph.Edge{},
+		Rows:      map[string]int{},
+		Unplaced:  map[string]bool{},
+	}
+}
+
+// RowError is a record of a file that could not be imported.
+type RowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// Types looks up the node and edge types of a project by id or by name.
+// Names are matched regardless of case.
+type Types struct {
+	nodes map[string]string
+	edges map[string]string
+}
+
+func NewTypes(node_types []graph.NodeType, edge_types []graph.EdgeType) *Types {
+	types := &Types{
+		nodes: map[string]string{},
+		edges: map[string]string{},
+	}
+	for _, t := range node_types {
+		types.nodes[strings.ToLower(t.Name)] = t.Id
+	}
+	for _, t := range edge_types {
+		types.edges[strings.ToLower(t.Name)] = t.Id
+	}
+	// Ids win over names, a type could be named like the id of another.
+	for _, t := range node_types {
+		types.nodes[t.Id] = t.Id
+	}
+	for _, t := range edge_types {
+		types.edges[t.Id] = t.Id
+	}
+	return types
+}
+
+// NodeType returns the id of the node type with the given id or name.
+func (t *Types) NodeType(ref string) (string, bool) {
+	id, ok := t.nodes[t.key(t.nodes, ref)]
+	return id, ok
+}
+
+// EdgeType returns the id of the edge type with the given id or name.
+func (t *Types) EdgeType(ref string) (string, bool) {
+	id, ok := t.edges[t.key(t.edges, ref)]
+	return id, ok
+}
+
+// AddNodeType makes a node type that is about to be created known by name.
+func (t *Types) AddNodeType(id string, name string) {
+	t.nodes[strings.ToLower(name)] = id
+	t.nodes[id] = id
+}
+
+// AddEdgeType makes an edge type that is about to be created known by name.
+func (t *Types) AddEdgeType(id string, name string) {
+	t.edges[strings.ToLower(name)] = id
+	t.edges[id] = id
+}
+
+func (t *Types) key(by map[string]string, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if _, ok := by[ref]; ok {
+		return ref
+	}
+	return strings.ToLower(ref)
+}
+
+// encodeMetadata writes attribute values the way nodes and edges store them,
+// nothing at all when there are none.
+func encodeMetadata(values map[string]any) []byte {
+	if len(values) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil
+	}
+	return data
+}
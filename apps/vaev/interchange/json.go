@@ -0,0 +1,277 @@
+package interchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"koppla/apps/vaev/views/graph"
+	"time"
+)
+
+// The Vaev JSON project format. A document looks like
+//
+//	{
+//	  "format": "vaev",
+//	  "version": 1,
+//	  "exported": "2025-07-16T19:00:00Z",
+//	  "project": {"name": "Payments"},
+//	  "node_types": [{"id": "a1", "name": "Account", "fill_color": "#ffffff",
+//	    "stroke_color": "#000000", "stroke_width": 1, "shape": 0,
+//	    "schema": [{"name": "country", "type": "string"}]}],
+//	  "edge_types": [{"id": "b1", "name": "Transfer", "stroke_color": "#000000",
+//	    "stroke_width": 1, "line_dash": [5, 3]}],
+//	  "nodes": [{"id": "c1", "name": "ACC1001", "type": "a1", "x": 0, "y": 0,
+//	    "metadata": {"country": "Sweden"}}],
+//	  "edges": [{"id": "d1", "name": "TXN000001", "type": "b1",
+//	    "source": "c1", "target": "c2", "metadata": {"amount": 50.25}}]
+//	}
+//
+// Ids only tie the records of a document together, they are replaced by new
+// ones on import. Shapes are numbered as in graph.SHAPES, schemas are lists
+// of graph.Attribute. Metadata, schema and line_dash may be left out.
+const (
+	JSON_FORMAT  = "vaev"
+	JSON_VERSION = 1
+)
+
+type Document struct {
+	Format    string         `json:"format"`
+	Version   int            `json:"version"`
+	Exported  string         `json:"exported,omitempty"`
+	Project   JSONProject    `json:"project"`
+	NodeTypes []JSONNodeType `json:"node_types"`
+	EdgeTypes []JSONEdgeType `json:"edge_types"`
+	Nodes     []JSONNode     `json:"nodes"`
+	Edges     []JSONEdge     `json:"edges"`
+}
+
+type JSONProject struct {
+	Name string `json:"name"`
+}
+
+type JSONNodeType struct {
+	Id          string          `json:"id"`
+	Name        string          `json:"name"`
+	FillColor   string          `json:"fill_color"`
+	StrokeColor string          `json:"stroke_color"`
+	StrokeWidth uint8           `json:"stroke_width"`
+	Shape       uint8           `json:"shape"`
+	Schema      json.RawMessage `json:"schema,omitempty"`
+	Metadata    json.RawMessage `json:"metadata,omitempty"`
+}
+
+type JSONEdgeType struct {
+	Id          string          `json:"id"`
+	Name        string          `json:"name"`
+	StrokeColor string          `json:"stroke_color"`
+	StrokeWidth uint8           `json:"stroke_width"`
+	LineDash    json.RawMessage `json:"line_dash,omitempty"`
+	Schema      json.RawMessage `json:"schema,omitempty"`
+	Metadata    json.RawMessage `json:"metadata,omitempty"`
+}
+
+type JSONNode struct {
+	Id       string          `json:"id"`
+	Name     string          `json:"name"`
+	Type     string          `json:"type"`
+	X        int             `json:"x"`
+	Y        int             `json:"y"`
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+}
+
+type JSONEdge struct {
+	Id       string          `json:"id"`
+	Name     string          `json:"name,omitempty"`
+	Type     string          `json:"type"`
+	Source   string          `json:"source"`
+	Target   string          `json:"target"`
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+}
+
+// NewDocument writes a project out in the Vaev JSON format.
+func NewDocument(project graph.Project, node_types []graph.NodeType, edge_types []graph.EdgeType, nodes []graph.Node, edges []graph.Edge) *Document {
+	doc := &Document{
+		Format:    JSON_FORMAT,
+		Version:   JSON_VERSION,
+		Exported:  time.Now().UTC().Format(time.RFC3339),
+		Project:   JSONProject{Name: project.Name},
+		NodeTypes: make([]JSONNodeType, 0, len(node_types)),
+		EdgeTypes: make([]JSONEdgeType, 0, len(edge_types)),
+		Nodes:     make([]JSONNode, 0, len(nodes)),
+		Edges:     make([]JSONEdge, 0, len(edges)),
+	}
+
+	for _, t := range node_types {
+		doc.NodeTypes = append(doc.NodeTypes, JSONNodeType{
+			Id:          t.Id,
+			Name:        t.Name,
+			FillColor:   t.FillColor,
+			StrokeColor: t.StrokeColor,
+			StrokeWidth: t.StrokeWidth,
+			Shape:       t.Shape,
+			Schema:      rawJSON(t.Schema),
+			Metadata:    rawJSON(t.Metadata),
+		})
+	}
+	for _, t := range edge_types {
+		doc.EdgeTypes = append(doc.EdgeTypes, JSONEdgeType{
+			Id:          t.Id,
+			Name:        t.Name,
+			StrokeColor: t.StrokeColor,
+			StrokeWidth: t.StrokeWidth,
+			LineDash:    rawJSON(t.LineDash),
+			Schema:      rawJSON(t.Schema),
+			Metadata:    rawJSON(t.Metadata),
+		})
+	}
+	for _, n := range nodes {
+		doc.Nodes = append(doc.Nodes, JSONNode{
+			Id:       n.Id,
+			Name:     n.Name,
+			Type:     n.Type,
+			X:        n.X,
+			Y:        n.Y,
+			Metadata: rawJSON(n.Metadata),
+		})
+	}
+	for _, e := range edges {
+		doc.Edges = append(doc.Edges, JSONEdge{
+			Id:       e.Id,
+			Name:     e.Name,
+			Type:     e.Type,
+			Source:   e.StartId,
+			Target:   e.EndId,
+			Metadata: rawJSON(e.Metadata),
+		})
+	}
+	return doc
+}
+
+// ReadDocument reads a project in the Vaev JSON format.
+func ReadDocument(r io.Reader) (*Document, error) {
+	doc := &Document{}
+	if err := json.NewDecoder(r).Decode(doc); err != nil {
+		return nil, fmt.Errorf("The file is not valid JSON: %w", err)
+	}
+	if doc.Format != JSON_FORMAT {
+		return nil, fmt.Errorf("The file is not a Vaev project, its format is %q", doc.Format)
+	}
+	if doc.Version < 1 || doc.Version > JSON_VERSION {
+		return nil, fmt.Errorf("Version %d of the Vaev format is not supported", doc.Version)
+	}
+	return doc, nil
+}
+
+// Graph maps a document onto a project. Types are matched to the types of
+// the project by name when types is given, the others are created. Every
+// node and edge is created anew.
+func (d *Document) Graph(types *Types) (*Graph, error) {
+	g := newGraph()
+	if types == nil {
+		types = NewTypes(nil, nil)
+	}
+
+	node_types := map[string]string{}
+	for i, t := range d.NodeTypes {
+		if id, ok := types.NodeType(t.Name); ok {
+			node_types[t.Id] = id
+			continue
+		}
+
+		id := "json-node-type-" + t.Id
+		node_types[t.Id] = id
+		types.AddNodeType(id, t.Name)
+		g.NodeTypes = append(g.NodeTypes, graph.NodeType{
+			Id:          id,
+			Name:        t.Name,
+			FillColor:   t.FillColor,
+			StrokeColor: t.StrokeColor,
+			StrokeWidth: t.StrokeWidth,
+			Shape:       t.Shape,
+			Schema:      t.Schema,
+			Metadata:    t.Metadata,
+		})
+		g.Rows[id] = i
+	}
+
+	edge_types := map[string]string{}
+	for i, t := range d.EdgeTypes {
+		if id, ok := types.EdgeType(t.Name); ok {
+			edge_types[t.Id] = id
+			continue
+		}
+
+		id := "json-edge-type-" + t.Id
+		edge_types[t.Id] = id
+		types.AddEdgeType(id, t.Name)
+		g.EdgeTypes = append(g.EdgeTypes, graph.EdgeType{
+			Id:          id,
+			Name:        t.Name,
+			StrokeColor: t.StrokeColor,
+			StrokeWidth: t.StrokeWidth,
+			LineDash:    t.LineDash,
+			Schema:      t.Schema,
+			Metadata:    t.Metadata,
+		})
+		g.Rows[id] = i
+	}
+
+	nodes := map[string]string{}
+	for i, n := range d.Nodes {
+		type_id, ok := node_types[n.Type]
+		if !ok {
+			return nil, fmt.Errorf("Node %q has type %q, which is not in the file", n.Id, n.Type)
+		}
+		if _, ok := nodes[n.Id]; ok {
+			return nil, fmt.Errorf("Node %q is in the file twice", n.Id)
+		}
+
+		id := "json-node-" + n.Id
+		nodes[n.Id] = id
+		g.Nodes = append(g.Nodes, graph.Node{
+			Id:       id,
+			Name:     n.Name,
+			Type:     type_id,
+			X:        n.X,
+			Y:        n.Y,
+			Metadata: n.Metadata,
+		})
+		g.Rows[id] = i
+	}
+
+	for i, e := range d.Edges {
+		type_id, ok := edge_types[e.Type]
+		if !ok {
+			return nil, fmt.Errorf("Edge %q has type %q, which is not in the file", e.Id, e.Type)
+		}
+		start_id, ok := nodes[e.Source]
+		if !ok {
+			return nil, fmt.Errorf("Edge %q starts at node %q, which is not in the file", e.Id, e.Source)
+		}
+		end_id, ok := nodes[e.Target]
+		if !ok {
+			return nil, fmt.Errorf("Edge %q ends at node %q, which is not in the file", e.Id, e.Target)
+		}
+
+		id := fmt.Sprintf("json-edge-%d", i)
+		g.Edges = append(g.Edges, graph.Edge{
+			Id:       id,
+			StartId:  start_id,
+			EndId:    end_id,
+			Type:     type_id,
+			Name:     e.Name,
+			Metadata: e.Metadata,
+		})
+		g.Rows[id] = i
+	}
+
+	return g, nil
+}
+
+// rawJSON passes a JSON column on as it is, leaving out empty ones.
+func rawJSON(raw []byte) json.RawMessage {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	return json.RawMessage(raw)
+}
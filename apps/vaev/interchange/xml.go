@@ -0,0 +1,292 @@
+package interchange
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"koppla/apps/vaev/views/graph"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Classes names the attributes that hold the class of nodes and edges in
+// GraphML and GEXF files. Every class becomes a type, found in the project
+// by name or created.
+type Classes struct {
+	Node string
+	Edge string
+}
+
+// DEFAULT_CLASSES reads classes from the type attributes Vaev exports.
+var DEFAULT_CLASSES = Classes{Node: "type", Edge: "type"}
+
+// Types for nodes and edges that have no class.
+const (
+	DEFAULT_NODE_TYPE = "Node"
+	DEFAULT_EDGE_TYPE = "Connection"
+)
+
+// xmlRecord is a node or edge as read from a GraphML or GEXF file. Line is
+// where it starts in the file.
+type xmlRecord struct {
+	Line   int
+	Id     string
+	Name   string
+	Class  string
+	Source string
+	Target string
+	// X and Y are only read for nodes, and only when the file has them.
+	X, Y   *float64
+	Visual xmlVisual
+	Values map[string]any
+}
+
+// xmlVisual is how a file draws a node or edge. A type that is created for
+// a class is drawn like the first record of the class.
+type xmlVisual struct {
+	FillColor   string
+	StrokeColor string
+	StrokeWidth *float64
+	// Shape is an index of graph.SHAPES, -1 when the file has none.
+	Shape    int
+	LineDash []float64
+}
+
+func newXMLRecord(line int) xmlRecord {
+	return xmlRecord{Line: line, Visual: xmlVisual{Shape: -1}, Values: map[string]any{}}
+}
+
+// buildGraph turns the records read from a file into a graph for the
+// project. Records that cannot be imported are skipped and reported.
+func buildGraph(prefix string, nodes []xmlRecord, edges []xmlRecord, types *Types) (*Graph, []RowError) {
+	g := newGraph()
+	row_errors := []RowError{}
+	report := func(line int, format string, args ...any) {
+		if len(row_errors) < MAX_ROW_ERRORS {
+			row_errors = append(row_errors, RowError{Row: line, Message: fmt.Sprintf(format, args...)})
+		}
+	}
+
+	node_type := func(r xmlRecord) string {
+		class := strings.TrimSpace(r.Class)
+		if class == "" {
+			class = DEFAULT_NODE_TYPE
+		}
+		if id, ok := types.NodeType(class); ok {
+			return id
+		}
+
+		t := graph.NodeType{
+			Id:          fmt.Sprintf("%s-node-type-%d", prefix, len(g.NodeTypes)),
+			Name:        class,
+			FillColor:   hexColor(r.Visual.FillColor, "#ffffff"),
+			StrokeColor: hexColor(r.Visual.StrokeColor, "#000000"),
+			StrokeWidth: strokeWidth(r.Visual.StrokeWidth),
+		}
+		if r.Visual.Shape >= 0 && r.Visual.Shape < len(graph.SHAPES) {
+			t.Shape = uint8(r.Visual.Shape)
+		}
+		types.AddNodeType(t.Id, t.Name)
+		g.NodeTypes = append(g.NodeTypes, t)
+		g.Rows[t.Id] = r.Line
+		return t.Id
+	}
+
+	edge_type := func(r xmlRecord) string {
+		class := strings.TrimSpace(r.Class)
+		if class == "" {
+			class = DEFAULT_EDGE_TYPE
+		}
+		if id, ok := types.EdgeType(class); ok {
+			return id
+		}
+
+		t := graph.EdgeType{
+			Id:          fmt.Sprintf("%s-edge-type-%d", prefix, len(g.EdgeTypes)),
+			Name:        class,
+			StrokeColor: hexColor(r.Visual.StrokeColor, "#000000"),
+			StrokeWidth: strokeWidth(r.Visual.StrokeWidth),
+			LineDash:    []byte{},
+		}
+		if len(r.Visual.LineDash) > 0 {
+			t.LineDash = encodeLineDash(r.Visual.LineDash)
+		}
+		types.AddEdgeType(t.Id, t.Name)
+		g.EdgeTypes = append(g.EdgeTypes, t)
+		g.Rows[t.Id] = r.Line
+		return t.Id
+	}
+
+	node_ids := map[string]string{}
+	for _, r := range nodes {
+		if r.Id == "" {
+			report(r.Line, "Node has no id")
+			continue
+		}
+		if _, ok := node_ids[r.Id]; ok {
+			report(r.Line, "Node %q is in the file twice", r.Id)
+			continue
+		}
+
+		n := graph.Node{
+			Id:       fmt.Sprintf("%s-node-%d", prefix, len(g.Nodes)),
+			Name:     r.Name,
+			Type:     node_type(r),
+			Metadata: encodeMetadata(r.Values),
+		}
+		if n.Name == "" {
+			n.Name = r.Id
+		}
+		if r.X != nil && r.Y != nil {
+			n.X, n.Y = int(math.Round(*r.X)), int(math.Round(*r.Y))
+		} else {
+			g.Unplaced[n.Id] = true
+		}
+
+		node_ids[r.Id] = n.Id
+		g.Nodes = append(g.Nodes, n)
+		g.Rows[n.Id] = r.Line
+	}
+
+	for _, r := range edges {
+		start_id, ok := node_ids[r.Source]
+		if !ok {
+			report(r.Line, "Edge starts at node %q, which is not in the file", r.Source)
+			continue
+		}
+		end_id, ok := node_ids[r.Target]
+		if !ok {
+			report(r.Line, "Edge ends at node %q, which is not in the file", r.Target)
+			continue
+		}
+
+		e := graph.Edge{
+			Id:       fmt.Sprintf("%s-edge-%d", prefix, len(g.Edges)),
+			StartId:  start_id,
+			EndId:    end_id,
+			Type:     edge_type(r),
+			Name:     r.Name,
+			Metadata: encodeMetadata(r.Values),
+		}
+		g.Edges = append(g.Edges, e)
+		g.Rows[e.Id] = r.Line
+	}
+
+	return g, row_errors
+}
+
+// readXML walks the elements of a file, handing every element named in
+// handlers to its handler along with the line it starts on. Elements inside
+// a handled element are left to the handler.
+func readXML(r io.Reader, handlers map[string]func(decoder *xml.Decoder, start xml.StartElement, line int) error) error {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		if strings.EqualFold(charset, "utf-8") || strings.EqualFold(charset, "us-ascii") {
+			return input, nil
+		}
+		return nil, fmt.Errorf("Only UTF-8 files can be imported, this one is %s", charset)
+	}
+
+	found := false
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("The file is not valid XML: %w", err)
+		}
+		// The end of the start tag, which is where most tags start too.
+		line, _ := decoder.InputPos()
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		handler, ok := handlers[start.Name.Local]
+		if !ok {
+			continue
+		}
+		found = true
+		if err := handler(decoder, start, line); err != nil {
+			return err
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("The file has no graph")
+	}
+	return nil
+}
+
+// parseValue reads an attribute value as the type the file declares for it.
+// Values that do not match it are kept as text.
+func parseValue(value string, value_type string) any {
+	switch strings.ToLower(value_type) {
+	case "int", "integer", "long", "float", "double":
+		if f := parseNumber(value); f != nil {
+			return *f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// parseNumber reads a number, nil when there is none.
+func parseNumber(value string) *float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	return &f
+}
+
+// parseShape finds a node shape by its name in Vaev or in one of the
+// formats, -1 when it is none of them.
+func parseShape(name string) int {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, names := range [][]string{graph.SHAPES, yed_shapes, gexf_shapes, dot_shapes} {
+		for i, n := range names {
+			if strings.ToLower(n) == name {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseLineDash reads a dash pattern of lengths separated by spaces or
+// commas.
+func parseLineDash(value string) []float64 {
+	dash := []float64{}
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool { return r == ' ' || r == ',' }) {
+		if length := parseNumber(field); length != nil && *length >= 0 {
+			dash = append(dash, *length)
+		}
+	}
+	return dash
+}
+
+// strokeWidth fits the stroke width of a file to what a type may have.
+func strokeWidth(width *float64) uint8 {
+	if width == nil {
+		return 1
+	}
+	return uint8(min(max(math.Round(*width), 0), graph.MAX_STROKE_WIDTH))
+}
+
+func encodeLineDash(dash []float64) []byte {
+	if len(dash) > graph.MAX_LINE_DASH {
+		dash = dash[:graph.MAX_LINE_DASH]
+	}
+	parts := make([]string, 0, len(dash))
+	for _, length := range dash {
+		parts = append(parts, formatValue(length))
+	}
+	return []byte("[" + strings.Join(parts, ",") + "]")
+}
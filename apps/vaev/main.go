@@ -1,7 +1,12 @@
 package main
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"koppla/apps/vaev/apperr"
+	"koppla/apps/vaev/audit"
+	"koppla/apps/vaev/hub"
 	mw "koppla/apps/vaev/middleware"
 	"koppla/apps/vaev/routing"
 	"koppla/apps/vaev/vapi"
@@ -30,10 +35,8 @@ import (
 	_ "koppla/apps/vaev/migrations"
 )
 
-type JsonError struct {
-	Message string `db:"message" json:"message"`
-	Status  int    `db:"status" json:"status"`
-}
+// E_GRAPH_LIVE is the DOM event live editing events are dispatched as.
+const E_GRAPH_LIVE = "graph-live"
 
 func main() {
 	is_dev := os.Getenv("APP_ENV") == "development"
@@ -43,7 +46,18 @@ func main() {
 		Automigrate: is_dev,
 	})
 
+	live := hub.New()
+	vapi.StartThumbnails(app, vapi.THUMBNAIL_DELAY)
+
+	retention := vapi.TrashRetention()
+	app.Cron().MustAdd("purge_trash", "0 * * * *", func() {
+		if err := vapi.PurgeTrash(app, time.Now().Add(-retention)); err != nil {
+			log.Printf("Unable to purge the trash: %v", err)
+		}
+	})
+
 	r := chi.NewMux()
+	r.Use(middleware.RequestID)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.RealIP)
@@ -54,297 +68,821 @@ func main() {
 		r.Use(mw.WithAuthRedirectGuard(auth.R_LOGIN))
 		r.Use(mw.WithCSRF)
 		r.Route("/dashboard", func(r chi.Router) {
-			r.Get("/projects", func(w http.ResponseWriter, r *http.Request) {
+			r.Get("/projects", apperr.Page(func(w http.ResponseWriter, r *http.Request) error {
 				projects := []graph.Project{}
 
-				var csrf_token string
-				cookie, err := r.Cookie(mw.SESSION_COOKIE_NAME)
-				if err == nil {
-					sd, ok := mw.DecodeSignedCookie(cookie.Value)
-					if ok {
-						csrf_token = sd.CSRFToken
+				user, err := auth.GetSignedInUser(app, r)
+				if err != nil {
+					return apperr.Unauthorized("You need to sign in to see your projects")
+				}
+
+				query := `
+				SELECT p.*,
+					CASE WHEN p.owner = {:user} THEN {:all} ELSE m.permissions END AS permissions
+				FROM projects p
+				LEFT JOIN project_members m ON m.project = p.id AND m.user = {:user}
+				WHERE (p.owner = {:user} OR m.id IS NOT NULL) AND p.deleted_at = ''
+				`
+				if err := app.DB().
+					NewQuery(query).
+					Bind(dbx.Params{"user": user.Id, "all": int(graph.P_ALL)}).
+					All(&projects); err != nil {
+					return apperr.Internal(err)
+				}
+				// Projects from before thumbnails were drawn get one now.
+				for _, p := range projects {
+					if p.Thumbnail == "" {
+						vapi.ScheduleThumbnail(app, p.Id)
 					}
-				} else {
-					log.Println("Could not find session cookie for rendering form, CSRF token will be empty.")
 				}
 
-				user, err := auth.GetSignedInUser(app, r)
+				trashed, err := dashboard.ListTrashedProjects(app, user.Id)
 				if err != nil {
-					log.Fatal("")
+					return apperr.Internal(err)
 				}
 
-				app.DB().
-					Select("*").
-					From("projects").
-					Where(dbx.NewExp("owner = {:owner}", dbx.Params{"owner": user.Id})).
-					All(&projects)
-
 				dasboard_styles := layout.NewStylesheet("/dist/dashboard.css")
 				dashboard_script := layout.NewScript("/dist/dashboard.js")
 				doc(func() templ.Component {
-					return dashboard.Projects(projects, csrf_token)
+					return dashboard.Projects(projects, trashed, retentionDays(retention), user.Id, csrfToken(r))
 				}, dasboard_styles, dashboard_script).ServeHTTP(w, r)
-			})
+				return nil
+			}))
 		})
-		r.Get("/project/{id}", func(w http.ResponseWriter, r *http.Request) {
-			project_id := chi.URLParam(r, "id")
-			var project *graph.Project
-
-			var csrf_token string
-			cookie, err := r.Cookie(mw.SESSION_COOKIE_NAME)
-			if err == nil {
-				sd, ok := mw.DecodeSignedCookie(cookie.Value)
-				if ok {
-					csrf_token = sd.CSRFToken
-				}
-			} else {
-				log.Println("Could not find session cookie for rendering form, CSRF token will be empty.")
-			}
+	})
 
-			user, err := auth.GetSignedInUser(app, r)
+	r.Group(func(r chi.Router) {
+		r.Use(mw.WithCSRF)
+		r.Get("/project/{id}", apperr.Page(func(w http.ResponseWriter, r *http.Request) error {
+			project, err := dashboard.ValidateProjectViewer(app, r)
+			var app_err *apperr.Error
+			if errors.As(err, &app_err) && app_err.Kind == apperr.K_UNAUTHORIZED {
+				routing.RedirectTo(w, r, auth.R_LOGIN, true)
+				return nil
+			}
 			if err != nil {
-				log.Print(err)
-				templ.Handler(layout.Doc(func() templ.Component {
-					return graph.Main(app, project_id)
-				})).ServeHTTP(w, r)
-				return
+				return err
 			}
 
-			app.DB().
-				Select("*").
-				From("projects").
-				Where(dbx.NewExp("id = {:id}", dbx.Params{"id": project_id})).
-				AndWhere(dbx.NewExp("owner = {:owner}", dbx.Params{"owner": user.Id})).
-				One(&project)
+			resources := []layout.Resource{layout.NewScript("/dist/graph.js")}
+			if project.CanEdit() {
+				resources = append(resources, layout.NewMeta(mw.CSRF_TOKEN_FIELD, csrfToken(r)))
+			}
 
-			if project == nil {
-				routing.RedirectTo(w, r, "/", false)
+			doc(
+				func() templ.Component {
+					return graph.Main(app, project.Id, !project.CanEdit(), nil)
+				},
+				resources...,
+			).ServeHTTP(w, r)
+			return nil
+		}))
+		r.Get("/project/{id}/snapshots/{snapshot_id}", apperr.Page(func(w http.ResponseWriter, r *http.Request) error {
+			project, err := dashboard.ValidateProjectViewer(app, r)
+			var app_err *apperr.Error
+			if errors.As(err, &app_err) && app_err.Kind == apperr.K_UNAUTHORIZED {
+				routing.RedirectTo(w, r, auth.R_LOGIN, true)
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			snapshot, _, err := vapi.LoadSnapshot(app.DB(), project.Id, chi.URLParam(r, "snapshot_id"))
+			if err != nil {
+				return err
 			}
 
 			doc(
 				func() templ.Component {
-					return graph.Main(app, project_id)
+					return graph.Main(app, project.Id, true, snapshot)
 				},
 				layout.NewScript("/dist/graph.js"),
-				layout.NewMeta(mw.CSRF_TOKEN_FIELD, csrf_token),
 			).ServeHTTP(w, r)
-		})
+			return nil
+		}))
 		r.Route("/sse", func(r chi.Router) {
-			r.Post("/project/create", func(w http.ResponseWriter, r *http.Request) {
-				user, err := auth.GetSignedInUser(app, r)
+			r.Get("/project/{id}", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+				project, err := dashboard.ValidateProjectViewer(app, r)
 				if err != nil {
-					log.Fatal("No user in signed in scope")
+					return err
 				}
 
-				r.ParseMultipartForm(1024 * 1024)
-
-				query := `
-				INSERT INTO projects (name, owner, created, updated)
-				VALUES ({:name}, {:owner}, {:created}, {:updated})
-				RETURNING name, owner, id, created, updated
-				`
-				l := "2006-01-02 15:04:05.000Z"
-				c_date := time.Now().Format(l)
-				var id, name, owner, created, updated string
-				e := app.DB().
-					NewQuery(query).
-					Bind(dbx.Params{
-						"name":    r.FormValue("project-name"),
-						"owner":   user.Id,
-						"created": c_date,
-						"updated": c_date,
-					}).Row(&name, &owner, &id, &created, &updated)
-				if e != nil {
-					log.Fatal(e)
+				signals, err := vapi.LoadGraph(app.DB(), project)
+				if err != nil {
+					return apperr.Internal(err)
 				}
 
-				default_node_types := []graph.NodeType{}
-
-				if err := app.DB().
-					Select("*").
-					From("default_node_types").
-					All(&default_node_types); err != nil {
-					log.Fatal(err)
+				sse := datastar.NewSSE(w, r)
+				sse.MarshalAndMergeSignals(signals)
+				return nil
+			}))
+			r.Get("/project/{id}/live", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+				project, err := dashboard.ValidateProjectViewer(app, r)
+				if err != nil {
+					return err
 				}
 
-				for _, t := range default_node_types {
-					q := `
-					INSERT INTO node_types (name, fill_color, stroke_color, stroke_width, shape, project)
-					VALUES ({:name}, {:fill_color}, {:stroke_color}, {:stroke_width}, {:shape}, {:project})
-					`
-					if _, err := app.DB().
-						NewQuery(q).
-						Bind(dbx.Params{
-							"name":         fmt.Sprintf("%s - %s", name, t.Name),
-							"fill_color":   t.FillColor,
-							"stroke_color": t.StrokeColor,
-							"stroke_width": t.StrokeWidth,
-							"shape":        t.Shape,
-							"project":      id,
-						}).
-						Execute(); err != nil {
-						log.Fatal(err)
+				viewer := hub.Viewer{
+					ClientId: hub.NewClientId(),
+					Name:     "Guest",
+				}
+				if user, err := auth.GetSignedInUser(app, r); err == nil {
+					viewer.UserId = user.Id
+					// Guests see everyone who is there, so the email is never
+					// shown in place of a name.
+					viewer.Name = user.Name
+					if viewer.Name == "" {
+						viewer.Name = "Anonymous"
 					}
 				}
 
-				default_edge_types := []graph.EdgeType{}
+				sse := datastar.NewSSE(w, r)
+				client := live.Join(project.Id, viewer)
+				defer live.Leave(project.Id, client)
 
-				if err := app.DB().
-					Select("*").
-					From("default_edge_types").
-					All(&default_edge_types); err != nil {
-					log.Fatal(err)
-				}
+				sse.DispatchCustomEvent(E_GRAPH_LIVE, hub.Event{Type: hub.E_HELLO, Data: viewer})
+				for {
+					select {
+					case <-sse.Context().Done():
+						return nil
+					case event, ok := <-client.Events():
+						if !ok {
+							sse.DispatchCustomEvent(E_GRAPH_LIVE, hub.Event{Type: hub.E_RESYNC})
+							return nil
+						}
 
-				for _, t := range default_edge_types {
-					q := `
-					INSERT INTO edge_types (name, stroke_width, stroke_color, line_dash, project)
-					VALUES ({:name}, {:stroke_width}, {:stroke_color}, {:line_dash}, {:project})
-					`
-					if _, err := app.DB().
-						NewQuery(q).
-						Bind(dbx.Params{
-							"name":         fmt.Sprintf("%s - %s", name, t.Name),
-							"stroke_width": t.StrokeWidth,
-							"stroke_color": t.StrokeColor,
-							"line_dash":    []byte{},
-							"project":      id,
-						}).
-						Execute(); err != nil {
-						log.Fatal(err)
+						if event.Type == hub.E_PRESENCE {
+							if err := sse.MergeFragmentTempl(graph.Presence(event.Data.([]hub.Viewer))); err != nil {
+								return nil
+							}
+						}
+						if err := sse.DispatchCustomEvent(E_GRAPH_LIVE, event); err != nil {
+							return nil
+						}
 					}
 				}
+			}))
+			r.Group(func(r chi.Router) {
+				r.Use(mw.WithAuthRedirectGuard(auth.R_LOGIN))
+				r.Get("/tokens", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					user, err := auth.GetSignedInUser(app, r)
+					if err != nil {
+						return apperr.Unauthorized("You need to sign in to manage access tokens")
+					}
 
-				new_project := graph.Project{
-					Owner:   owner,
-					Id:      id,
-					Name:    name,
-					Updated: updated,
-					Created: created,
-				}
+					return mergeTokens(app, w, r, user.Id, "")
+				}))
+				r.Post("/tokens", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					user, err := auth.GetSignedInUser(app, r)
+					if err != nil {
+						return apperr.Unauthorized("You need to sign in to create an access token")
+					}
 
-				sse := datastar.NewSSE(w, r)
-				sse.MergeFragmentTempl(
-					dashboard.ProjectItem(new_project),
-					datastar.WithSelectorID("projects-list"),
-					datastar.WithMergeAppend(),
-				)
-			})
-			r.Get("/project/{id}/node-select", func(w http.ResponseWriter, r *http.Request) {
-				is_owner := dashboard.ValidateProjectOwner(app, w, r)
-				if !is_owner {
-					mw.WriteJSONUnauthorized(w)
-					return
-				}
+					r.ParseMultipartForm(1024 * 1024)
+					token, err := auth.CreateAccessToken(app, user.Id, r.FormValue("name"), r.Form["project"], r.FormValue("write") != "")
+					if err != nil {
+						return err
+					}
 
-				project_id := chi.URLParam(r, "id")
-				sse := datastar.NewSSE(w, r)
+					return mergeTokens(app, w, r, user.Id, token)
+				}))
+				r.Post("/tokens/{token_id}/revoke", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					user, err := auth.GetSignedInUser(app, r)
+					if err != nil {
+						return apperr.Unauthorized("You need to sign in to revoke an access token")
+					}
 
-				node_types := []graph.NodeType{}
-				app.DB().
-					Select("*").
-					From("node_types").
-					Where(
-						dbx.NewExp(
-							"project = {:project_id}",
-							dbx.Params{"project_id": project_id}),
-					).
-					All(&node_types)
-
-				sse.MergeFragmentTempl(
-					graph.NodeSelector(node_types),
-				)
-			})
-			r.Get("/project/{id}/edge-select", func(w http.ResponseWriter, r *http.Request) {
-				is_owner := dashboard.ValidateProjectOwner(app, w, r)
-				if !is_owner {
-					mw.WriteJSONUnauthorized(w)
-					return
-				}
+					if err := auth.RevokeAccessToken(app, user.Id, chi.URLParam(r, "token_id")); err != nil {
+						return err
+					}
 
-				project_id := chi.URLParam(r, "id")
-				sse := datastar.NewSSE(w, r)
+					return mergeTokens(app, w, r, user.Id, "")
+				}))
+				r.Post("/project/create", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					user, err := auth.GetSignedInUser(app, r)
+					if err != nil {
+						return apperr.Unauthorized("You need to sign in to create a project")
+					}
 
-				edge_types := []graph.EdgeType{}
-				app.DB().
-					Select("*").
-					From("edge_types").
-					Where(
-						dbx.NewExp(
-							"project = {:project_id}",
-							dbx.Params{"project_id": project_id}),
-					).
-					All(&edge_types)
-
-				sse.MergeFragmentTempl(
-					graph.EdgeSelector(edge_types),
-				)
-			})
-			r.Get("/project/{id}", func(w http.ResponseWriter, r *http.Request) {
-				is_owner := dashboard.ValidateProjectOwner(app, w, r)
-				if !is_owner {
-					mw.WriteJSONUnauthorized(w)
-					return
-				}
+					r.ParseMultipartForm(1024 * 1024)
 
-				project_id := chi.URLParam(r, "id")
-				project := dashboard.GetProject(app, r)
+					project_name := r.FormValue("project-name")
+					if project_name == "" {
+						return apperr.Validation("A project needs a name")
+					}
 
-				sse := datastar.NewSSE(w, r)
+					new_project := graph.Project{}
+					err = app.RunInTransaction(func(tx core.App) error {
+						query := `
+						INSERT INTO projects (name, owner, created, updated)
+						VALUES ({:name}, {:owner}, {:created}, {:updated})
+						RETURNING name, owner, id, created, updated
+						`
+						l := "2006-01-02 15:04:05.000Z"
+						c_date := time.Now().Format(l)
+						if err := tx.DB().
+							NewQuery(query).
+							Bind(dbx.Params{
+								"name":    project_name,
+								"owner":   user.Id,
+								"created": c_date,
+								"updated": c_date,
+							}).Row(
+							&new_project.Name,
+							&new_project.Owner,
+							&new_project.Id,
+							&new_project.Created,
+							&new_project.Updated,
+						); err != nil {
+							return err
+						}
 
-				node_types := []graph.NodeType{}
-				app.DB().
-					Select("*").
-					From("node_types").
-					Where(
-						dbx.NewExp(
-							"project = {:project_id}",
-							dbx.Params{"project_id": project_id}),
-					).
-					All(&node_types)
-
-				edge_types := []graph.EdgeType{}
-				app.DB().
-					Select("*").
-					From("edge_types").
-					Where(
-						dbx.NewExp(
-							"project = {:project_id}",
-							dbx.Params{"project_id": project_id}),
-					).
-					All(&edge_types)
-
-				nodes := []graph.Node{}
-				app.DB().
-					Select("*").
-					From("nodes").
-					Where(
-						dbx.NewExp(
-							"project = {:project_id}",
-							dbx.Params{"project_id": project_id}),
-					).
-					All(&nodes)
-
-				edges := []graph.Edge{}
-				app.DB().
-					Select("*").
-					From("edges").
-					Where(
-						dbx.NewExp(
-							"project = {:project_id}",
-							dbx.Params{"project_id": project_id}),
-					).
-					All(&edges)
-
-				signals := vapi.GraphSignals{
-					Project:   *project,
-					Nodes:     nodes,
-					NodeTypes: node_types,
-					EdgeTypes: edge_types,
-					Edges:     edges,
-				}
+						default_node_types := []graph.NodeType{}
 
-				sse.MarshalAndMergeSignals(signals)
+						if err := tx.DB().
+							Select("*").
+							From("default_node_types").
+							All(&default_node_types); err != nil {
+							return err
+						}
+
+						for _, t := range default_node_types {
+							q := `
+							INSERT INTO node_types (name, fill_color, stroke_color, stroke_width, shape, project)
+							VALUES ({:name}, {:fill_color}, {:stroke_color}, {:stroke_width}, {:shape}, {:project})
+							`
+							if _, err := tx.DB().
+								NewQuery(q).
+								Bind(dbx.Params{
+									"name":         fmt.Sprintf("%s - %s", new_project.Name, t.Name),
+									"fill_color":   t.FillColor,
+									"stroke_color": t.StrokeColor,
+									"stroke_width": t.StrokeWidth,
+									"shape":        t.Shape,
+									"project":      new_project.Id,
+								}).
+								Execute(); err != nil {
+								return err
+							}
+						}
+
+						default_edge_types := []graph.EdgeType{}
+
+						if err := tx.DB().
+							Select("*").
+							From("default_edge_types").
+							All(&default_edge_types); err != nil {
+							return err
+						}
+
+						for _, t := range default_edge_types {
+							q := `
+							INSERT INTO edge_types (name, stroke_width, stroke_color, line_dash, project)
+							VALUES ({:name}, {:stroke_width}, {:stroke_color}, {:line_dash}, {:project})
+							`
+							if _, err := tx.DB().
+								NewQuery(q).
+								Bind(dbx.Params{
+									"name":         fmt.Sprintf("%s - %s", new_project.Name, t.Name),
+									"stroke_width": t.StrokeWidth,
+									"stroke_color": t.StrokeColor,
+									"line_dash":    []byte{},
+									"project":      new_project.Id,
+								}).
+								Execute(); err != nil {
+								return err
+							}
+						}
+
+						return nil
+					})
+					if err != nil {
+						return apperr.Internal(err)
+					}
+					new_project.Permissions = graph.P_ALL
+
+					sse := datastar.NewSSE(w, r)
+					sse.MergeFragmentTempl(
+						dashboard.ProjectItem(new_project, user.Id, csrfToken(r)),
+						datastar.WithSelectorID("projects-list"),
+						datastar.WithMergeAppend(),
+					)
+					return nil
+				}))
+				r.Get("/project/{id}/node-select", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, 0)
+					if err != nil {
+						return err
+					}
+
+					node_types := []graph.NodeType{}
+					if err := app.DB().
+						Select("*").
+						From("node_types").
+						Where(
+							dbx.NewExp(
+								"project = {:project_id}",
+								dbx.Params{"project_id": project.Id}),
+						).
+						All(&node_types); err != nil {
+						return apperr.Internal(err)
+					}
+
+					sse := datastar.NewSSE(w, r)
+					sse.MergeFragmentTempl(
+						graph.NodeSelector(node_types),
+					)
+					return nil
+				}))
+				r.Get("/project/{id}/edge-select", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, 0)
+					if err != nil {
+						return err
+					}
+
+					edge_types := []graph.EdgeType{}
+					if err := app.DB().
+						Select("*").
+						From("edge_types").
+						Where(
+							dbx.NewExp(
+								"project = {:project_id}",
+								dbx.Params{"project_id": project.Id}),
+						).
+						All(&edge_types); err != nil {
+						return apperr.Internal(err)
+					}
+
+					sse := datastar.NewSSE(w, r)
+					sse.MergeFragmentTempl(
+						graph.EdgeSelector(edge_types),
+					)
+					return nil
+				}))
+				r.Post("/project/{id}/visibility", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, graph.P_MANAGE_PROJECT)
+					if err != nil {
+						return err
+					}
+
+					r.ParseMultipartForm(1024)
+					visibility := r.FormValue("visibility")
+					if !graph.IsVisibility(visibility) {
+						return apperr.Validation(fmt.Sprintf("Unknown visibility %q", visibility))
+					}
+
+					user, err := auth.GetSignedInUser(app, r)
+					if err != nil {
+						return apperr.Unauthorized("You need to sign in to change the visibility")
+					}
+
+					err = app.RunInTransaction(func(tx core.App) error {
+						if _, err := tx.DB().
+							Update(
+								"projects",
+								dbx.Params{"visibility": visibility},
+								dbx.HashExp{"id": project.Id},
+							).
+							Execute(); err != nil {
+							return err
+						}
+
+						return audit.Record(tx.DB(), project.Id, user.Id, audit.Change{
+							Operation: audit.O_UPDATE,
+							Entity:    audit.ENT_PROJECT,
+							EntityId:  project.Id,
+							Before:    map[string]string{"visibility": project.Visibility},
+							After:     map[string]string{"visibility": visibility},
+						})
+					})
+					if err != nil {
+						return apperr.Internal(err)
+					}
+					project.Visibility = visibility
+
+					sse := datastar.NewSSE(w, r)
+					sse.MergeFragmentTempl(
+						dashboard.ProjectItem(*project, user.Id, csrfToken(r)),
+					)
+					return nil
+				}))
+				r.Post("/project/{id}/rename", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, graph.P_MANAGE_PROJECT)
+					if err != nil {
+						return err
+					}
+
+					user, err := auth.GetSignedInUser(app, r)
+					if err != nil {
+						return apperr.Unauthorized("You need to sign in to rename a project")
+					}
+
+					r.ParseMultipartForm(1024)
+					if err := dashboard.RenameProject(app, project, user.Id, r.FormValue("name")); err != nil {
+						return err
+					}
+
+					sse := datastar.NewSSE(w, r)
+					sse.MergeFragmentTempl(
+						dashboard.ProjectItem(*project, user.Id, csrfToken(r)),
+					)
+					return nil
+				}))
+				// Members who can only view a project may not take a copy of
+				// it that they can do anything with.
+				r.Post("/project/{id}/duplicate", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, graph.P_EDIT_NODES)
+					if err != nil {
+						return err
+					}
+
+					user, err := auth.GetSignedInUser(app, r)
+					if err != nil {
+						return apperr.Unauthorized("You need to sign in to duplicate a project")
+					}
+
+					duplicate, err := dashboard.DuplicateProject(app, project, user.Id)
+					if err != nil {
+						return err
+					}
+					vapi.ScheduleThumbnail(app, duplicate.Id)
+
+					sse := datastar.NewSSE(w, r)
+					sse.MergeFragmentTempl(
+						dashboard.ProjectItem(*duplicate, user.Id, csrfToken(r)),
+						datastar.WithSelectorID("projects-list"),
+						datastar.WithMergeAppend(),
+					)
+					return nil
+				}))
+				r.Post("/project/{id}/delete", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, graph.P_MANAGE_PROJECT)
+					if err != nil {
+						return err
+					}
+
+					user, err := auth.GetSignedInUser(app, r)
+					if err != nil {
+						return apperr.Unauthorized("You need to sign in to delete a project")
+					}
+
+					if err := dashboard.TrashProject(app, project, user.Id); err != nil {
+						return err
+					}
+
+					trashed, err := dashboard.ListTrashedProjects(app, user.Id)
+					if err != nil {
+						return apperr.Internal(err)
+					}
+
+					sse := datastar.NewSSE(w, r)
+					sse.RemoveFragments(fmt.Sprintf("#project-%s", project.Id))
+					sse.MergeFragmentTempl(
+						dashboard.ProjectTrash(trashed, retentionDays(retention), csrfToken(r)),
+					)
+					return nil
+				}))
+				r.Post("/project/{id}/purge", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					user, err := auth.GetSignedInUser(app, r)
+					if err != nil {
+						return apperr.Unauthorized("You need to sign in to delete a project")
+					}
+
+					if err := dashboard.DeleteProject(app, chi.URLParam(r, "id"), user.Id); err != nil {
+						return err
+					}
+
+					trashed, err := dashboard.ListTrashedProjects(app, user.Id)
+					if err != nil {
+						return apperr.Internal(err)
+					}
+
+					sse := datastar.NewSSE(w, r)
+					sse.MergeFragmentTempl(
+						dashboard.ProjectTrash(trashed, retentionDays(retention), csrfToken(r)),
+					)
+					return nil
+				}))
+				r.Post("/project/{id}/restore", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					user, err := auth.GetSignedInUser(app, r)
+					if err != nil {
+						return apperr.Unauthorized("You need to sign in to restore a project")
+					}
+
+					project, err := dashboard.RestoreProject(app, chi.URLParam(r, "id"), user.Id)
+					if err != nil {
+						return err
+					}
+
+					trashed, err := dashboard.ListTrashedProjects(app, user.Id)
+					if err != nil {
+						return apperr.Internal(err)
+					}
+
+					sse := datastar.NewSSE(w, r)
+					sse.MergeFragmentTempl(
+						dashboard.ProjectItem(*project, user.Id, csrfToken(r)),
+						datastar.WithSelectorID("projects-list"),
+						datastar.WithMergeAppend(),
+					)
+					sse.MergeFragmentTempl(
+						dashboard.ProjectTrash(trashed, retentionDays(retention), csrfToken(r)),
+					)
+					return nil
+				}))
+				r.Get("/project/{id}/snapshots", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, 0)
+					if err != nil {
+						return err
+					}
+
+					return mergeSnapshots(app, w, r, project)
+				}))
+				r.Post("/project/{id}/snapshots", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, graph.P_MANAGE_PROJECT)
+					if err != nil {
+						return err
+					}
+
+					user, err := auth.GetSignedInUser(app, r)
+					if err != nil {
+						return apperr.Unauthorized("You need to sign in to save a snapshot")
+					}
+
+					r.ParseMultipartForm(1024)
+					if _, err := vapi.CaptureSnapshot(app, project, user.Id, r.FormValue("name")); err != nil {
+						return err
+					}
+
+					return mergeSnapshots(app, w, r, project)
+				}))
+				r.Post("/project/{id}/snapshots/{snapshot_id}/restore", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, graph.P_MANAGE_PROJECT)
+					if err != nil {
+						return err
+					}
+
+					user, err := auth.GetSignedInUser(app, r)
+					if err != nil {
+						return apperr.Unauthorized("You need to sign in to restore a snapshot")
+					}
+
+					if _, err := vapi.RestoreSnapshot(app, project, chi.URLParam(r, "snapshot_id"), user.Id); err != nil {
+						return err
+					}
+
+					// Every open editor, this one included, loads the restored
+					// project again.
+					live.Publish(project.Id, hub.Event{Type: hub.E_RESYNC})
+					return mergeSnapshots(app, w, r, project)
+				}))
+				r.Get("/project/{id}/types", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, 0)
+					if err != nil {
+						return err
+					}
+
+					return mergeTypes(app, w, r, project)
+				}))
+				r.Post("/project/{id}/node-types", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, graph.P_MANAGE_PROJECT)
+					if err != nil {
+						return err
+					}
+
+					r.ParseMultipartForm(1024)
+					node_type, err := vapi.NodeTypeFromForm(r.Form)
+					if err != nil {
+						return err
+					}
+					if _, err := vapi.Commit(app, live, r, project.Id, vapi.Changeset{
+						CreateNodeTypes: []graph.NodeType{node_type},
+					}); err != nil {
+						return err
+					}
+
+					return mergeTypes(app, w, r, project)
+				}))
+				r.Post("/project/{id}/node-types/{type_id}", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, graph.P_MANAGE_PROJECT)
+					if err != nil {
+						return err
+					}
+
+					r.ParseMultipartForm(1024)
+					node_type, err := vapi.NodeTypeFromForm(r.Form)
+					if err != nil {
+						return err
+					}
+					node_type.Id = chi.URLParam(r, "type_id")
+					// The editor has no field for metadata, keep what is stored.
+					if err := app.DB().
+						Select("metadata").
+						From("node_types").
+						Where(dbx.HashExp{"id": node_type.Id, "project": project.Id}).
+						Row(&node_type.Metadata); err != nil && !errors.Is(err, sql.ErrNoRows) {
+						return apperr.Internal(err)
+					}
+					if _, err := vapi.Commit(app, live, r, project.Id, vapi.Changeset{
+						UpdateNodeTypes: []graph.NodeType{node_type},
+					}); err != nil {
+						return err
+					}
+
+					return mergeTypes(app, w, r, project)
+				}))
+				r.Post("/project/{id}/node-types/{type_id}/delete", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, graph.P_MANAGE_PROJECT)
+					if err != nil {
+						return err
+					}
+
+					r.ParseMultipartForm(1024)
+					type_id := chi.URLParam(r, "type_id")
+					changeset := vapi.Changeset{DeleteNodeTypes: []string{type_id}}
+					if reassign := r.FormValue("reassign"); reassign != "" {
+						changeset.ReassignNodeTypes = map[string]string{type_id: reassign}
+					}
+					if _, err := vapi.Commit(app, live, r, project.Id, changeset); err != nil {
+						return err
+					}
+
+					return mergeTypes(app, w, r, project)
+				}))
+				r.Post("/project/{id}/edge-types", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, graph.P_MANAGE_PROJECT)
+					if err != nil {
+						return err
+					}
+
+					r.ParseMultipartForm(1024)
+					edge_type, err := vapi.EdgeTypeFromForm(r.Form)
+					if err != nil {
+						return err
+					}
+					if _, err := vapi.Commit(app, live, r, project.Id, vapi.Changeset{
+						CreateEdgeTypes: []graph.EdgeType{edge_type},
+					}); err != nil {
+						return err
+					}
+
+					return mergeTypes(app, w, r, project)
+				}))
+				r.Post("/project/{id}/edge-types/{type_id}", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, graph.P_MANAGE_PROJECT)
+					if err != nil {
+						return err
+					}
+
+					r.ParseMultipartForm(1024)
+					edge_type, err := vapi.EdgeTypeFromForm(r.Form)
+					if err != nil {
+						return err
+					}
+					edge_type.Id = chi.URLParam(r, "type_id")
+					// The editor has no field for metadata, keep what is stored.
+					if err := app.DB().
+						Select("metadata").
+						From("edge_types").
+						Where(dbx.HashExp{"id": edge_type.Id, "project": project.Id}).
+						Row(&edge_type.Metadata); err != nil && !errors.Is(err, sql.ErrNoRows) {
+						return apperr.Internal(err)
+					}
+					if _, err := vapi.Commit(app, live, r, project.Id, vapi.Changeset{
+						UpdateEdgeTypes: []graph.EdgeType{edge_type},
+					}); err != nil {
+						return err
+					}
+
+					return mergeTypes(app, w, r, project)
+				}))
+				r.Post("/project/{id}/edge-types/{type_id}/delete", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, graph.P_MANAGE_PROJECT)
+					if err != nil {
+						return err
+					}
+
+					r.ParseMultipartForm(1024)
+					type_id := chi.URLParam(r, "type_id")
+					changeset := vapi.Changeset{DeleteEdgeTypes: []string{type_id}}
+					if reassign := r.FormValue("reassign"); reassign != "" {
+						changeset.ReassignEdgeTypes = map[string]string{type_id: reassign}
+					}
+					if _, err := vapi.Commit(app, live, r, project.Id, changeset); err != nil {
+						return err
+					}
+
+					return mergeTypes(app, w, r, project)
+				}))
+				r.Get("/project/{id}/trash", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, 0)
+					if err != nil {
+						return err
+					}
+
+					return mergeTrash(app, w, r, project)
+				}))
+				r.Post("/project/{id}/trash/restore", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, 0)
+					if err != nil {
+						return err
+					}
+
+					r.ParseMultipartForm(1024)
+					changeset := vapi.Changeset{
+						RestoreNodes: r.Form["node"],
+						RestoreEdges: r.Form["edge"],
+					}
+					if !graph.HasPermission(project.Permissions, changeset.RequiredPermission()) {
+						return apperr.Forbidden("You do not have permission to restore this")
+					}
+
+					if _, err := vapi.Commit(app, live, r, project.Id, changeset); err != nil {
+						return err
+					}
+
+					return mergeTrash(app, w, r, project)
+				}))
+				r.Get("/project/{id}/members", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, graph.P_MANAGE_PROJECT)
+					if err != nil {
+						return err
+					}
+
+					members, err := dashboard.ListMembers(app, project.Id)
+					if err != nil {
+						return apperr.Internal(err)
+					}
+
+					sse := datastar.NewSSE(w, r)
+					sse.MergeFragmentTempl(
+						dashboard.MembersPanel(*project, members, csrfToken(r)),
+					)
+					return nil
+				}))
+				r.Post("/project/{id}/members", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, graph.P_MANAGE_PROJECT)
+					if err != nil {
+						return err
+					}
+
+					r.ParseMultipartForm(1024)
+					permissions := graph.PermissionConfig{
+						EditConnection: r.FormValue("edit_connection") != "",
+						EditNodes:      r.FormValue("edit_nodes") != "",
+						ManageProject:  r.FormValue("manage_project") != "",
+					}.Permission()
+
+					user, err := auth.GetSignedInUser(app, r)
+					if err != nil {
+						return apperr.Unauthorized("You need to sign in to invite members")
+					}
+
+					if err := dashboard.InviteMember(app, project, user.Id, r.FormValue("email"), permissions); err != nil {
+						return err
+					}
+
+					members, err := dashboard.ListMembers(app, project.Id)
+					if err != nil {
+						return apperr.Internal(err)
+					}
+
+					sse := datastar.NewSSE(w, r)
+					sse.MergeFragmentTempl(
+						dashboard.MembersPanel(*project, members, csrfToken(r)),
+					)
+					return nil
+				}))
+				r.Post("/project/{id}/members/{member_id}/remove", apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+					project, err := dashboard.ValidateProjectPermission(app, r, graph.P_MANAGE_PROJECT)
+					if err != nil {
+						return err
+					}
+
+					user, err := auth.GetSignedInUser(app, r)
+					if err != nil {
+						return apperr.Unauthorized("You need to sign in to remove members")
+					}
+
+					member, err := dashboard.RemoveMember(app, project.Id, user.Id, chi.URLParam(r, "member_id"))
+					if err != nil {
+						return err
+					}
+					// Open streams of the member would go on receiving changes.
+					live.Drop(project.Id, member.User)
+
+					members, err := dashboard.ListMembers(app, project.Id)
+					if err != nil {
+						return apperr.Internal(err)
+					}
+
+					sse := datastar.NewSSE(w, r)
+					sse.MergeFragmentTempl(
+						dashboard.MembersPanel(*project, members, csrfToken(r)),
+					)
+					return nil
+				}))
 			})
 		})
 	})
@@ -356,10 +894,19 @@ func main() {
 			return
 		}
 
+		public_projects := []graph.Project{}
+		err = app.DB().
+			NewQuery("SELECT * FROM projects WHERE visibility = {:visibility} AND deleted_at = '' ORDER BY updated DESC LIMIT 20").
+			Bind(dbx.Params{"visibility": graph.V_PUBLIC}).
+			All(&public_projects)
+		if err != nil {
+			log.Printf("Unable to list public projects: %v", err)
+		}
+
 		templ.Handler(
 			layout.Doc(
 				func() templ.Component {
-					return intro.Intro()
+					return intro.Intro(public_projects)
 				},
 				layout.NewStylesheet("/dist/intro.css"),
 			),
@@ -367,7 +914,7 @@ func main() {
 	})
 
 	auth.AuthRoutes(app, r)
-	vapi.RegisterVAPI(app, r)
+	vapi.RegisterVAPI(app, r, live)
 
 	if is_dev {
 		log.Println("Development mode: proxying to Vite server on http://localhost:5173")
@@ -411,6 +958,22 @@ func doc(child func() templ.Component, resources ...layout.Resource) *templ.Comp
 	return templ.Handler(layout.Doc(child, resources...))
 }
 
+// csrfToken returns the CSRF token of the session, for rendering it into
+// forms and meta tags.
+func csrfToken(r *http.Request) string {
+	cookie, err := r.Cookie(mw.SESSION_COOKIE_NAME)
+	if err != nil {
+		log.Println("Could not find session cookie for rendering form, CSRF token will be empty.")
+		return ""
+	}
+
+	sd, ok := mw.DecodeSignedCookie(cookie.Value)
+	if !ok {
+		return ""
+	}
+	return sd.CSRFToken
+}
+
 func newReverseProxy(target string) *httputil.ReverseProxy {
 	url, err := url.Parse(target)
 	if err != nil {
@@ -418,3 +981,99 @@ func newReverseProxy(target string) *httputil.ReverseProxy {
 	}
 	return httputil.NewSingleHostReverseProxy(url)
 }
+
+// retentionDays is how many days the trash is kept, for telling users.
+func retentionDays(retention time.Duration) int {
+	return int(retention.Hours() / 24)
+}
+
+// mergeTokens shows the access tokens of the user in the dialog of the user
+// card, with a token that was just created.
+func mergeTokens(app *pocketbase.PocketBase, w http.ResponseWriter, r *http.Request, user_id string, created string) error {
+	tokens, err := auth.ListAccessTokens(app, user_id)
+	if err != nil {
+		return apperr.Internal(err)
+	}
+	projects, err := auth.TokenProjects(app, user_id)
+	if err != nil {
+		return apperr.Internal(err)
+	}
+
+	sse := datastar.NewSSE(w, r)
+	sse.MergeFragmentTempl(auth.TokensPanel(tokens, projects, created, csrfToken(r)))
+	return nil
+}
+
+// mergeTrash renders the trash section of the editor.
+func mergeTrash(app *pocketbase.PocketBase, w http.ResponseWriter, r *http.Request, project *graph.Project) error {
+	trash, err := vapi.ListTrash(app.DB(), project.Id)
+	if err != nil {
+		return apperr.Internal(err)
+	}
+	signals, err := vapi.LoadGraph(app.DB(), project)
+	if err != nil {
+		return apperr.Internal(err)
+	}
+
+	names := map[string]string{}
+	for _, nodes := range [][]graph.Node{signals.Nodes, trash.Nodes} {
+		for _, n := range nodes {
+			names[n.Id] = n.Name
+		}
+	}
+
+	sse := datastar.NewSSE(w, r)
+	sse.MergeFragmentTempl(graph.TrashList(
+		project.Id,
+		trash.Nodes,
+		trash.Edges,
+		names,
+		project.Permissions,
+		csrfToken(r),
+	))
+	return nil
+}
+
+// mergeTypes renders the type editor along with the type selectors, which
+// list the same types.
+func mergeTypes(app *pocketbase.PocketBase, w http.ResponseWriter, r *http.Request, project *graph.Project) error {
+	by_project := dbx.HashExp{"project": project.Id}
+
+	node_types := []graph.NodeType{}
+	if err := app.DB().Select("*").From("node_types").Where(by_project).All(&node_types); err != nil {
+		return apperr.Internal(err)
+	}
+	edge_types := []graph.EdgeType{}
+	if err := app.DB().Select("*").From("edge_types").Where(by_project).All(&edge_types); err != nil {
+		return apperr.Internal(err)
+	}
+
+	sse := datastar.NewSSE(w, r)
+	sse.MergeFragmentTempl(graph.TypeEditor(
+		project.Id,
+		node_types,
+		edge_types,
+		graph.HasPermission(project.Permissions, graph.P_MANAGE_PROJECT),
+		csrfToken(r),
+	))
+	sse.MergeFragmentTempl(graph.NodeSelector(node_types))
+	sse.MergeFragmentTempl(graph.EdgeSelector(edge_types))
+	return nil
+}
+
+// mergeSnapshots renders the history section of the editor.
+func mergeSnapshots(app *pocketbase.PocketBase, w http.ResponseWriter, r *http.Request, project *graph.Project) error {
+	snapshots, err := vapi.ListSnapshots(app.DB(), project.Id)
+	if err != nil {
+		return err
+	}
+
+	sse := datastar.NewSSE(w, r)
+	sse.MergeFragmentTempl(graph.SnapshotList(
+		project.Id,
+		snapshots,
+		graph.HasPermission(project.Permissions, graph.P_MANAGE_PROJECT),
+		csrfToken(r),
+	))
+	return nil
+}
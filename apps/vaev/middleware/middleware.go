@@ -12,27 +12,28 @@ import (
 )
 
 type JSONErrorMessage struct {
-	Message string `db:"message" json:"message"`
+	Message   string `db:"message" json:"message"`
+	Status    int    `db:"status" json:"status,omitempty"`
+	RequestId string `db:"request_id" json:"request_id,omitempty"`
+	Details   any    `db:"details" json:"details,omitempty"`
 }
 
 func WriteJSONUnauthorized(w http.ResponseWriter) {
-	bytes, err := json.Marshal(JSONErrorMessage{
-		Message: "You are not authorized to access this resource",
-	})
-	if err != nil {
-		log.Fatal(err)
-	}
-	w.WriteHeader(http.StatusUnauthorized)
-	w.Write(bytes)
+	writeJSONMessage(w, http.StatusUnauthorized, "You are not authorized to access this resource")
 }
 
 func WriteJSONNotFound(w http.ResponseWriter) {
-	bytes, err := json.Marshal(JSONErrorMessage{
-		Message: "Not found",
-	})
+	writeJSONMessage(w, http.StatusNotFound, "Not found")
+}
+
+func writeJSONMessage(w http.ResponseWriter, status int, message string) {
+	bytes, err := json.Marshal(JSONErrorMessage{Message: message})
 	if err != nil {
-		log.Fatal(err)
+		log.Printf("Unable to marshal error message: %v", err)
+		http.Error(w, message, status)
+		return
 	}
+	w.WriteHeader(status)
 	w.Write(bytes)
 }
 
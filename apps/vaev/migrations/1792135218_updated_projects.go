@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("pbc_484305853")
+		if err != nil {
+			return err
+		}
+
+		// add field
+		if err := collection.Fields.AddMarshaledJSONAt(5, []byte(`{
+			"hidden": false,
+			"id": "date2863221484",
+			"max": "",
+			"min": "",
+			"name": "deleted_at",
+			"presentable": false,
+			"required": false,
+			"system": false,
+			"type": "date"
+		}`)); err != nil {
+			return err
+		}
+
+		// the trash is purged by deleted_at
+		collection.AddIndex("idx_deleted_at_pbc_484305853", false, "`deleted_at`", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("pbc_484305853")
+		if err != nil {
+			return err
+		}
+
+		collection.RemoveIndex("idx_deleted_at_pbc_484305853")
+
+		// remove field
+		collection.Fields.RemoveById("date2863221484")
+
+		return app.Save(collection)
+	})
+}
@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("pbc_3598433047")
+		if err != nil {
+			return err
+		}
+
+		// add field
+		if err := collection.Fields.AddMarshaledJSONAt(9, []byte(`{
+			"hidden": false,
+			"id": "date1830514739",
+			"max": "",
+			"min": "",
+			"name": "deleted_at",
+			"presentable": false,
+			"required": false,
+			"system": false,
+			"type": "date"
+		}`)); err != nil {
+			return err
+		}
+
+		// the trash is purged by deleted_at
+		collection.AddIndex("idx_deleted_at_pbc_3598433047", false, "`deleted_at`", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("pbc_3598433047")
+		if err != nil {
+			return err
+		}
+
+		collection.RemoveIndex("idx_deleted_at_pbc_3598433047")
+
+		// remove field
+		collection.Fields.RemoveById("date1830514739")
+
+		return app.Save(collection)
+	})
+}
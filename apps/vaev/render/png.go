@@ -0,0 +1,309 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"koppla/apps/vaev/views/graph"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// Curves are drawn as this many straight lines when they are stroked.
+const (
+	QUAD_STEPS = 8
+	CUBE_STEPS = 16
+)
+
+// PNG draws the scene as a PNG image of at most MAX_SIZE pixels a side.
+func (s *Scene) PNG(w io.Writer, opts Options) error {
+	return png.Encode(w, s.Image(opts))
+}
+
+// Image draws the scene.
+func (s *Scene) Image(opts Options) *image.RGBA {
+	scale, offset, width, height := s.transform(opts, MAX_SIZE)
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(BACKGROUND), image.Point{}, draw.Src)
+
+	at := func(p point) point {
+		return point{p.X*scale + offset.X, p.Y*scale + offset.Y}
+	}
+
+	for _, e := range s.edges {
+		path := transformPath(edgePath(e.Points), at)
+		lines := dashed(flatten(path), scaledDash(e.Style.LineDash, scale))
+		stroke(img, lines, e.Style.StrokeWidth*scale, e.Style.Stroke)
+	}
+
+	for _, n := range s.nodes {
+		path := transformPath(shapePath(n.Center, n.Style.Shape), at)
+		fill(img, path, n.Style.Fill)
+		stroke(img, flatten(path), n.Style.StrokeWidth*scale, n.Style.Stroke)
+	}
+
+	if opts.Labels {
+		drawer := &font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(TEXT),
+			Face: basicfont.Face7x13,
+		}
+		for _, n := range s.nodes {
+			if n.Name == "" {
+				continue
+			}
+			top := at(point{n.Center.X, n.Center.Y + graph.NODE_RADIUS + LABEL_GAP})
+			text_width := drawer.MeasureString(n.Name)
+			drawer.Dot = fixed.Point26_6{
+				X: fixed.Int26_6(top.X*64) - text_width/2,
+				Y: fixed.Int26_6(top.Y*64) + basicfont.Face7x13.Metrics().Ascent,
+			}
+			drawer.DrawString(n.Name)
+		}
+	}
+
+	return img
+}
+
+// polyline is a flattened path.
+type polyline struct {
+	Points []point
+	Closed bool
+}
+
+func transformPath(path []op, at func(point) point) []op {
+	out := make([]op, 0, len(path))
+	for _, o := range path {
+		points := make([]point, 0, len(o.Points))
+		for _, p := range o.Points {
+			points = append(points, at(p))
+		}
+		out = append(out, op{o.Kind, points})
+	}
+	return out
+}
+
+func fill(img *image.RGBA, path []op, c color.NRGBA) {
+	bounds := img.Bounds()
+	r := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	r.DrawOp = draw.Over
+	for _, o := range path {
+		p := o.Points
+		switch o.Kind {
+		case MOVE:
+			r.MoveTo(float32(p[0].X), float32(p[0].Y))
+		case LINE:
+			r.LineTo(float32(p[0].X), float32(p[0].Y))
+		case QUAD:
+			r.QuadTo(float32(p[0].X), float32(p[0].Y), float32(p[1].X), float32(p[1].Y))
+		case CUBE:
+			r.CubeTo(float32(p[0].X), float32(p[0].Y), float32(p[1].X), float32(p[1].Y), float32(p[2].X), float32(p[2].Y))
+		case CLOSE:
+			r.ClosePath()
+		}
+	}
+	r.Draw(img, bounds, image.NewUniform(c), image.Point{})
+}
+
+// stroke draws lines of the given width with round joins and caps. Every
+// part of a line is added to the rasterizer with the same winding, so that
+// where parts overlap they are drawn once.
+func stroke(img *image.RGBA, lines []polyline, width float64, c color.NRGBA) {
+	if width <= 0 || c.A == 0 {
+		return
+	}
+	bounds := img.Bounds()
+	r := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	r.DrawOp = draw.Over
+	half := width / 2
+
+	add := func(points []point) {
+		area := 0.0
+		for i, p := range points {
+			q := points[(i+1)%len(points)]
+			area += p.X*q.Y - q.X*p.Y
+		}
+		if area < 0 {
+			for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+				points[i], points[j] = points[j], points[i]
+			}
+		}
+		r.MoveTo(float32(points[0].X), float32(points[0].Y))
+		for _, p := range points[1:] {
+			r.LineTo(float32(p.X), float32(p.Y))
+		}
+		r.ClosePath()
+	}
+	dot := func(center point) {
+		if half < 1 {
+			return
+		}
+		steps := 12
+		points := make([]point, 0, steps)
+		for i := range steps {
+			angle := 2 * math.Pi * float64(i) / float64(steps)
+			points = append(points, point{center.X + half*math.Cos(angle), center.Y + half*math.Sin(angle)})
+		}
+		add(points)
+	}
+
+	for _, line := range lines {
+		points := line.Points
+		if line.Closed && len(points) > 1 {
+			points = append(points, points[0])
+		}
+		for i := 0; i+1 < len(points); i++ {
+			a, b := points[i], points[i+1]
+			length := distance(a, b)
+			if length == 0 {
+				continue
+			}
+			nx, ny := -(b.Y-a.Y)/length*half, (b.X-a.X)/length*half
+			add([]point{{a.X + nx, a.Y + ny}, {b.X + nx, b.Y + ny}, {b.X - nx, b.Y - ny}, {a.X - nx, a.Y - ny}})
+		}
+		for _, p := range points {
+			dot(p)
+		}
+	}
+	r.Draw(img, bounds, image.NewUniform(c), image.Point{})
+}
+
+// flatten turns a path into straight lines.
+func flatten(path []op) []polyline {
+	lines := []polyline{}
+	var current *polyline
+	last := point{}
+	for _, o := range path {
+		switch o.Kind {
+		case MOVE:
+			lines = append(lines, polyline{Points: []point{o.Points[0]}})
+			current = &lines[len(lines)-1]
+			last = o.Points[0]
+			continue
+		case CLOSE:
+			if current != nil {
+				current.Closed = true
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		switch o.Kind {
+		case LINE:
+			current.Points = append(current.Points, o.Points[0])
+		case QUAD:
+			for i := 1; i <= QUAD_STEPS; i++ {
+				t := float64(i) / QUAD_STEPS
+				current.Points = append(current.Points, quadAt(last, o.Points[0], o.Points[1], t))
+			}
+		case CUBE:
+			for i := 1; i <= CUBE_STEPS; i++ {
+				t := float64(i) / CUBE_STEPS
+				current.Points = append(current.Points, cubeAt(last, o.Points[0], o.Points[1], o.Points[2], t))
+			}
+		}
+		last = o.Points[len(o.Points)-1]
+	}
+	return lines
+}
+
+func quadAt(p0, p1, p2 point, t float64) point {
+	u := 1 - t
+	return point{
+		u*u*p0.X + 2*u*t*p1.X + t*t*p2.X,
+		u*u*p0.Y + 2*u*t*p1.Y + t*t*p2.Y,
+	}
+}
+
+func cubeAt(p0, p1, p2, p3 point, t float64) point {
+	u := 1 - t
+	return point{
+		u*u*u*p0.X + 3*u*u*t*p1.X + 3*u*t*t*p2.X + t*t*t*p3.X,
+		u*u*u*p0.Y + 3*u*u*t*p1.Y + 3*u*t*t*p2.Y + t*t*t*p3.Y,
+	}
+}
+
+// scaledDash scales a dash pattern, which like setLineDash repeats a
+// pattern of an odd number of lengths twice.
+func scaledDash(dash []float64, scale float64) []float64 {
+	total := 0.0
+	for _, length := range dash {
+		total += length
+	}
+	if total <= 0 {
+		return nil
+	}
+	if len(dash)%2 == 1 {
+		dash = append(dash[:len(dash):len(dash)], dash...)
+	}
+	scaled := make([]float64, 0, len(dash))
+	for _, length := range dash {
+		scaled = append(scaled, length*scale)
+	}
+	return scaled
+}
+
+// dashed splits lines into the dashes of a pattern, the gaps between them
+// left out. Lines that would need more than MAX_DASHES dashes are kept solid.
+func dashed(lines []polyline, dash []float64) []polyline {
+	if dash == nil {
+		return lines
+	}
+	total := 0.0
+	for _, length := range dash {
+		total += length
+	}
+
+	out := []polyline{}
+	for _, line := range lines {
+		points := line.Points
+		if line.Closed && len(points) > 1 {
+			points = append(points, points[0])
+		}
+
+		line_length := 0.0
+		for i := 0; i+1 < len(points); i++ {
+			line_length += distance(points[i], points[i+1])
+		}
+		if line_length/total*float64(len(dash)) > MAX_DASHES {
+			out = append(out, line)
+			continue
+		}
+
+		index, left, on := 0, dash[0], true
+		current := []point{}
+		if len(points) > 0 {
+			current = append(current, points[0])
+		}
+		for i := 0; i+1 < len(points); i++ {
+			a, b := points[i], points[i+1]
+			length := distance(a, b)
+			position := 0.0
+			for length-position > left {
+				position += left
+				p := towards(a, b, position)
+				if on {
+					out = append(out, polyline{Points: append(current, p)})
+				}
+				current = []point{p}
+				on = !on
+				index = (index + 1) % len(dash)
+				left = dash[index]
+			}
+			left -= length - position
+			current = append(current, b)
+		}
+		if on && len(current) > 1 {
+			out = append(out, polyline{Points: current})
+		}
+	}
+	return out
+}
@@ -0,0 +1,334 @@
+// Package render draws projects as the canvas of the editor does, to SVG
+// and PNG, without a browser.
+package render
+
+import (
+	"encoding/json"
+	"image/color"
+	"koppla/apps/vaev/views/graph"
+	"math"
+	"unicode/utf8"
+)
+
+const (
+	// PADDING is the space around the graph.
+	PADDING = 20
+	// GRID_SIZE matches grid_size of the canvas, edges in a bundle are this
+	// far apart and turn with half of it as the radius.
+	GRID_SIZE = 20
+	// LABEL_SIZE is the height of a node label, drawn below the node.
+	LABEL_SIZE = 12
+	LABEL_GAP  = 10
+	// CHAR_WIDTH is the width of a character of a label.
+	CHAR_WIDTH = 7
+	// MAX_SIZE is the most pixels a side of a PNG may have, larger graphs are
+	// scaled down to fit.
+	MAX_SIZE = 4096
+	// MAX_DASHES is the most dashes a line is split into, lines that would
+	// need more are drawn solid.
+	MAX_DASHES = 1024
+)
+
+var (
+	BACKGROUND = color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	TEXT       = color.NRGBA{0x00, 0x00, 0x00, 0xff}
+)
+
+// Options change how a scene is drawn.
+type Options struct {
+	// Width and Height fit the drawing into a box of that size, centered.
+	// It is never scaled up. Zero draws the graph at its own size.
+	Width  int
+	Height int
+	// Labels draws the names of nodes.
+	Labels bool
+}
+
+type point struct {
+	X, Y float64
+}
+
+type nodeStyle struct {
+	Fill        color.NRGBA
+	Stroke      color.NRGBA
+	StrokeWidth float64
+	Shape       uint8
+}
+
+type edgeStyle struct {
+	Stroke      color.NRGBA
+	StrokeWidth float64
+	LineDash    []float64
+}
+
+type node struct {
+	Center point
+	Name   string
+	Style  nodeStyle
+}
+
+type edge struct {
+	// Points is the route of the edge, it rounds every corner.
+	Points []point
+	Style  edgeStyle
+}
+
+// Scene is a project laid out for drawing.
+type Scene struct {
+	nodes []node
+	edges []edge
+	// min and max bound everything that is drawn.
+	min, max point
+}
+
+// NewScene lays out the nodes and edges of a project. Edges are routed like
+// the canvas routes them: between the sides of their nodes that face each
+// other, with a single bend, and edges between the same nodes spread apart.
+func NewScene(node_types []graph.NodeType, edge_types []graph.EdgeType, nodes []graph.Node, edges []graph.Edge) *Scene {
+	s := &Scene{
+		nodes: make([]node, 0, len(nodes)),
+		edges: make([]edge, 0, len(edges)),
+		min:   point{math.Inf(1), math.Inf(1)},
+		max:   point{math.Inf(-1), math.Inf(-1)},
+	}
+
+	node_styles := map[string]nodeStyle{}
+	for _, t := range node_types {
+		node_styles[t.Id] = nodeStyle{
+			Fill:        parseColor(t.FillColor, BACKGROUND),
+			Stroke:      parseColor(t.StrokeColor, TEXT),
+			StrokeWidth: float64(t.StrokeWidth),
+			Shape:       t.Shape,
+		}
+	}
+	edge_styles := map[string]edgeStyle{}
+	for _, t := range edge_types {
+		style := edgeStyle{
+			Stroke:      parseColor(t.StrokeColor, TEXT),
+			StrokeWidth: float64(t.StrokeWidth),
+		}
+		if len(t.LineDash) > 0 {
+			json.Unmarshal(t.LineDash, &style.LineDash)
+		}
+		edge_styles[t.Id] = style
+	}
+
+	centers := map[string]point{}
+	for _, n := range nodes {
+		style, ok := node_styles[n.Type]
+		if !ok {
+			style = nodeStyle{Fill: BACKGROUND, Stroke: TEXT, StrokeWidth: 1}
+		}
+		center := point{float64(n.X), float64(n.Y)}
+		centers[n.Id] = center
+		s.nodes = append(s.nodes, node{Center: center, Name: n.Name, Style: style})
+
+		r := float64(graph.NODE_RADIUS) + style.StrokeWidth/2
+		label := float64(utf8.RuneCountInString(n.Name)*CHAR_WIDTH) / 2
+		s.include(point{center.X - max(r, label), center.Y - r})
+		s.include(point{center.X + max(r, label), center.Y + r + LABEL_GAP + LABEL_SIZE})
+	}
+
+	// Edges between the same two nodes, in either direction, are drawn as
+	// a bundle.
+	bundles := map[[2]string][]graph.Edge{}
+	order := [][2]string{}
+	for _, e := range edges {
+		if _, ok := centers[e.StartId]; !ok {
+			continue
+		}
+		if _, ok := centers[e.EndId]; !ok {
+			continue
+		}
+		key := [2]string{e.StartId, e.EndId}
+		if key[0] > key[1] {
+			key[0], key[1] = key[1], key[0]
+		}
+		if _, ok := bundles[key]; !ok {
+			order = append(order, key)
+		}
+		bundles[key] = append(bundles[key], e)
+	}
+
+	for _, key := range order {
+		bundle := bundles[key]
+		for i, e := range bundle {
+			style, ok := edge_styles[e.Type]
+			if !ok {
+				style = edgeStyle{Stroke: TEXT, StrokeWidth: 1}
+			}
+			offset := (float64(i) - float64(len(bundle)-1)/2) * GRID_SIZE
+			points := route(centers[e.StartId], centers[e.EndId], offset)
+			for _, p := range points {
+				s.include(p)
+			}
+			s.edges = append(s.edges, edge{Points: points, Style: style})
+		}
+	}
+
+	if len(s.nodes) == 0 {
+		s.min, s.max = point{}, point{}
+	}
+	return s
+}
+
+func (s *Scene) include(p point) {
+	s.min.X, s.min.Y = min(s.min.X, p.X), min(s.min.Y, p.Y)
+	s.max.X, s.max.Y = max(s.max.X, p.X), max(s.max.Y, p.Y)
+}
+
+// route finds the path of an edge between two node centers, as
+// drawEdgeOrthogonal of the canvas does.
+func route(start point, end point, offset float64) []point {
+	r := float64(graph.NODE_RADIUS)
+	dx, dy := end.X-start.X, end.Y-start.Y
+
+	if math.Abs(dx) > math.Abs(dy) {
+		direction := math.Copysign(1, dx)
+		s := point{start.X + direction*r, start.Y}
+		e := point{end.X - direction*r, end.Y}
+		mid_x := s.X + (e.X-s.X)/2 + offset
+		return []point{s, {mid_x, s.Y}, {mid_x, e.Y}, e}
+	}
+
+	direction := 1.0
+	if dy < 0 {
+		direction = -1
+	}
+	s := point{start.X, start.Y + direction*r}
+	e := point{end.X, end.Y - direction*r}
+	mid_y := s.Y + (e.Y-s.Y)/2 + offset
+	return []point{s, {s.X, mid_y}, {e.X, mid_y}, e}
+}
+
+// transform maps the scene onto an image of the given options: the scale it
+// is drawn at, the offset of its origin and the size of the image.
+func (s *Scene) transform(opts Options, limit float64) (scale float64, offset point, width int, height int) {
+	content_width := s.max.X - s.min.X + 2*PADDING
+	content_height := s.max.Y - s.min.Y + 2*PADDING
+
+	box_width, box_height := content_width, content_height
+	if opts.Width > 0 && opts.Height > 0 {
+		box_width, box_height = float64(opts.Width), float64(opts.Height)
+	}
+	if limit > 0 {
+		box_width, box_height = min(box_width, limit), min(box_height, limit)
+	}
+	scale = min(1, box_width/content_width, box_height/content_height)
+
+	width, height = int(math.Ceil(box_width)), int(math.Ceil(box_height))
+	if opts.Width <= 0 || opts.Height <= 0 {
+		width = int(math.Ceil(content_width * scale))
+		height = int(math.Ceil(content_height * scale))
+	}
+	offset = point{
+		(float64(width)-content_width*scale)/2 + (PADDING-s.min.X)*scale,
+		(float64(height)-content_height*scale)/2 + (PADDING-s.min.Y)*scale,
+	}
+	return scale, offset, width, height
+}
+
+// op is a step of a path, in the manner of SVG path commands.
+type op struct {
+	Kind   byte
+	Points []point
+}
+
+const (
+	MOVE  = 'M'
+	LINE  = 'L'
+	QUAD  = 'Q'
+	CUBE  = 'C'
+	CLOSE = 'Z'
+)
+
+// KAPPA places the control points of a cubic curve that draws a quarter
+// of a circle.
+const KAPPA = 0.5522847498
+
+// shapePath outlines a node as the canvas draws it.
+func shapePath(center point, shape uint8) []op {
+	r := float64(graph.NODE_RADIUS)
+	x, y := center.X, center.Y
+
+	switch shape {
+	case graph.S_SQUARE:
+		return polygon([]point{{x - r, y - r}, {x + r, y - r}, {x + r, y + r}, {x - r, y + r}})
+	case graph.S_SQUARE_ROUNDED:
+		return roundedRect(x-r, y-r, 2*r, 2*r, 5)
+	case graph.S_DIAMOND:
+		return polygon([]point{{x, y - r}, {x + r, y}, {x, y + r}, {x - r, y}})
+	}
+
+	k := r * KAPPA
+	return []op{
+		{MOVE, []point{{x + r, y}}},
+		{CUBE, []point{{x + r, y + k}, {x + k, y + r}, {x, y + r}}},
+		{CUBE, []point{{x - k, y + r}, {x - r, y + k}, {x - r, y}}},
+		{CUBE, []point{{x - r, y - k}, {x - k, y - r}, {x, y - r}}},
+		{CUBE, []point{{x + k, y - r}, {x + r, y - k}, {x + r, y}}},
+		{CLOSE, nil},
+	}
+}
+
+func polygon(points []point) []op {
+	path := []op{{MOVE, []point{points[0]}}}
+	for _, p := range points[1:] {
+		path = append(path, op{LINE, []point{p}})
+	}
+	return append(path, op{CLOSE, nil})
+}
+
+func roundedRect(x, y, width, height, r float64) []op {
+	return []op{
+		{MOVE, []point{{x + r, y}}},
+		{LINE, []point{{x + width - r, y}}},
+		{QUAD, []point{{x + width, y}, {x + width, y + r}}},
+		{LINE, []point{{x + width, y + height - r}}},
+		{QUAD, []point{{x + width, y + height}, {x + width - r, y + height}}},
+		{LINE, []point{{x + r, y + height}}},
+		{QUAD, []point{{x, y + height}, {x, y + height - r}}},
+		{LINE, []point{{x, y + r}}},
+		{QUAD, []point{{x, y}, {x + r, y}}},
+		{CLOSE, nil},
+	}
+}
+
+// edgePath follows the route of an edge, rounding its corners like arcTo
+// does on the canvas.
+func edgePath(points []point) []op {
+	path := []op{{MOVE, []point{points[0]}}}
+	for i := 1; i < len(points)-1; i++ {
+		previous, corner, next := points[i-1], points[i], points[i+1]
+		r := min(GRID_SIZE/2, distance(previous, corner)/2, distance(corner, next)/2)
+		if r <= 0 {
+			path = append(path, op{LINE, []point{corner}})
+			continue
+		}
+		path = append(path,
+			op{LINE, []point{towards(corner, previous, r)}},
+			op{QUAD, []point{corner, towards(corner, next, r)}},
+		)
+	}
+	return append(path, op{LINE, []point{points[len(points)-1]}})
+}
+
+func distance(a point, b point) float64 {
+	return math.Hypot(b.X-a.X, b.Y-a.Y)
+}
+
+// towards moves from a point by d in the direction of another.
+func towards(from point, to point, d float64) point {
+	length := distance(from, to)
+	if length == 0 {
+		return from
+	}
+	return point{from.X + (to.X-from.X)/length*d, from.Y + (to.Y-from.Y)/length*d}
+}
+
+func parseColor(s string, fallback color.NRGBA) color.NRGBA {
+	if c, ok := graph.ParseColor(s); ok {
+		return c
+	}
+	return fallback
+}
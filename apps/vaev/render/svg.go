@@ -0,0 +1,83 @@
+package render
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"koppla/apps/vaev/views/graph"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SVG draws the scene as an SVG document.
+func (s *Scene) SVG(w io.Writer, opts Options) error {
+	scale, offset, width, height := s.transform(opts, 0)
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	fmt.Fprintf(out, `<rect width="100%%" height="100%%" %s/>`+"\n", svgPaint("fill", BACKGROUND))
+	fmt.Fprintf(out, `<g transform="translate(%s %s) scale(%s)">`+"\n", svgNumber(offset.X), svgNumber(offset.Y), svgNumber(scale))
+
+	for _, e := range s.edges {
+		dash := ""
+		if len(e.Style.LineDash) > 0 {
+			lengths := make([]string, 0, len(e.Style.LineDash))
+			for _, length := range e.Style.LineDash {
+				lengths = append(lengths, svgNumber(length))
+			}
+			dash = fmt.Sprintf(` stroke-dasharray="%s"`, strings.Join(lengths, " "))
+		}
+		fmt.Fprintf(out, `<path d="%s" fill="none" %s stroke-width="%s"%s/>`+"\n",
+			svgPath(edgePath(e.Points)), svgPaint("stroke", e.Style.Stroke), svgNumber(e.Style.StrokeWidth), dash)
+	}
+
+	for _, n := range s.nodes {
+		fmt.Fprintf(out, `<path d="%s" %s %s stroke-width="%s"/>`+"\n",
+			svgPath(shapePath(n.Center, n.Style.Shape)), svgPaint("fill", n.Style.Fill), svgPaint("stroke", n.Style.Stroke), svgNumber(n.Style.StrokeWidth))
+	}
+
+	if opts.Labels {
+		for _, n := range s.nodes {
+			if n.Name == "" {
+				continue
+			}
+			top := n.Center.Y + graph.NODE_RADIUS + LABEL_GAP
+			fmt.Fprintf(out, `<text x="%s" y="%s" text-anchor="middle" dominant-baseline="hanging" font-family="monospace" font-size="%d" %s>`,
+				svgNumber(n.Center.X), svgNumber(top), LABEL_SIZE, svgPaint("fill", TEXT))
+			xml.EscapeText(out, []byte(n.Name))
+			fmt.Fprintln(out, `</text>`)
+		}
+	}
+
+	fmt.Fprintln(out, `</g>`)
+	fmt.Fprintln(out, `</svg>`)
+	return out.Flush()
+}
+
+func svgPath(path []op) string {
+	parts := make([]string, 0, len(path))
+	for _, o := range path {
+		part := string(o.Kind)
+		for _, p := range o.Points {
+			part += svgNumber(p.X) + "," + svgNumber(p.Y) + " "
+		}
+		parts = append(parts, strings.TrimSpace(part))
+	}
+	return strings.Join(parts, " ")
+}
+
+// svgPaint sets a fill or stroke, with its opacity when it has one.
+func svgPaint(attribute string, c color.NRGBA) string {
+	paint := fmt.Sprintf(`%s="#%02x%02x%02x"`, attribute, c.R, c.G, c.B)
+	if c.A != 0xff {
+		paint += fmt.Sprintf(` %s-opacity="%s"`, attribute, svgNumber(float64(c.A)/0xff))
+	}
+	return paint
+}
+
+func svgNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
@@ -1,45 +1,69 @@
 package routing
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
+	"strings"
 
 	datastar "github.com/starfederation/datastar/sdk/go"
 )
 
-func BounceBack(w http.ResponseWriter, r *http.Request) {
-	next := r.URL.Query().Get("next")
-
-	next_unescaped, err := url.QueryUnescape(next)
+// NextPath returns where the next query parameter of the request points,
+// "/" when it has none. Only paths on this site are accepted, so that a link
+// to the login page cannot send users elsewhere.
+func NextPath(r *http.Request) (string, error) {
+	next, err := url.QueryUnescape(r.URL.Query().Get("next"))
 	if err != nil {
-		log.Fatalf("Could not parse bounce back query: %s", next)
+		return "", fmt.Errorf("Unable to parse next: %w", err)
+	}
+	if next == "" {
+		return "/", nil
 	}
 
-	if len(next_unescaped) > 0 {
-		log.Printf("Redirecting user to %s", next)
-		http.Redirect(w, r, next_unescaped, http.StatusSeeOther)
+	parsed, err := url.Parse(next)
+	if err != nil ||
+		!strings.HasPrefix(next, "/") ||
+		strings.HasPrefix(next, "//") ||
+		strings.HasPrefix(next, "/\\") ||
+		parsed.Scheme != "" ||
+		parsed.Host != "" {
+		return "", fmt.Errorf("next must be a path on this site")
 	}
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	return next, nil
 }
 
-func BounceBackSSE(w http.ResponseWriter, r *http.Request) {
-	sse := datastar.NewSSE(w, r)
-	next := r.URL.Query().Get("next")
+// BounceBack redirects to the next query parameter of the request.
+func BounceBack(w http.ResponseWriter, r *http.Request) error {
+	next, err := NextPath(r)
+	if err != nil {
+		return err
+	}
 
-	next_unescaped, err := url.QueryUnescape(next)
+	log.Printf("Redirecting user to %s", next)
+	http.Redirect(w, r, next, http.StatusSeeOther)
+	return nil
+}
+
+// BounceBackSSE sends the browser to the next query parameter of the
+// request.
+func BounceBackSSE(w http.ResponseWriter, r *http.Request) error {
+	next, err := NextPath(r)
 	if err != nil {
-		log.Fatalf("Could not parse bounce back query: %s", next)
+		return err
 	}
 
-	if len(next_unescaped) > 0 {
-		log.Printf("Redirecting user to %s", next_unescaped)
-		sse.ExecuteScript(fmt.Sprintf(`
-			window.location = "%s"
-		`, next_unescaped))
+	location, err := json.Marshal(next)
+	if err != nil {
+		return err
 	}
-	sse.ExecuteScript(`window.location = "/"`)
+
+	log.Printf("Redirecting user to %s", next)
+	sse := datastar.NewSSE(w, r)
+	sse.ExecuteScript(fmt.Sprintf(`window.location = %s`, location))
+	return nil
 }
 
 func RedirectTo(w http.ResponseWriter, r *http.Request, to string, add_next bool) {
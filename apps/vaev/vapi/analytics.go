@@ -0,0 +1,150 @@
+package vapi
+
+import (
+	"fmt"
+	"koppla/apps/vaev/analytics"
+	"koppla/apps/vaev/apperr"
+	"math"
+	"net/url"
+	"strconv"
+)
+
+const (
+	A_COMPONENTS    = "components"
+	A_DEGREE        = "degree"
+	A_SHORTEST_PATH = "shortest-path"
+	A_CYCLES        = "cycles"
+	A_BETWEENNESS   = "betweenness"
+	A_PAGERANK      = "pagerank"
+)
+
+// The largest graphs, counted in nodes and edges together, that the
+// expensive algorithms run on. Betweenness takes time in the number of nodes
+// times the number of edges, PageRank in the number of iterations times both.
+const (
+	MAX_BETWEENNESS_SIZE = 5000
+	MAX_PAGERANK_SIZE    = 100000
+)
+
+type ComponentsResult struct {
+	Count      int        `json:"count"`
+	Components [][]string `json:"components"`
+}
+
+type ShortestPathResult struct {
+	Found  bool     `json:"found"`
+	Length int      `json:"length"`
+	Path   []string `json:"path"`
+}
+
+type CyclesResult struct {
+	HasCycle bool     `json:"has_cycle"`
+	Cycle    []string `json:"cycle"`
+}
+
+// runAnalytics runs the named algorithm on g, reading its parameters from
+// the query string.
+func runAnalytics(g *analytics.Graph, algorithm string, query url.Values) (any, error) {
+	switch algorithm {
+	case A_COMPONENTS:
+		components := g.ConnectedComponents()
+		return ComponentsResult{
+			Count:      len(components),
+			Components: components,
+		}, nil
+	case A_DEGREE:
+		return g.Degrees(), nil
+	case A_SHORTEST_PATH:
+		from := query.Get("from")
+		to := query.Get("to")
+		if _, ok := g.Index(from); !ok {
+			return nil, apperr.Validation(fmt.Sprintf("Unknown start node %q", from))
+		}
+		if _, ok := g.Index(to); !ok {
+			return nil, apperr.Validation(fmt.Sprintf("Unknown end node %q", to))
+		}
+
+		path := g.ShortestPath(from, to, query.Get("directed") == "true")
+		if path == nil {
+			return ShortestPathResult{Path: []string{}}, nil
+		}
+		return ShortestPathResult{
+			Found:  true,
+			Length: len(path) - 1,
+			Path:   path,
+		}, nil
+	case A_CYCLES:
+		cycle := g.FindCycle()
+		if cycle == nil {
+			return CyclesResult{Cycle: []string{}}, nil
+		}
+		return CyclesResult{HasCycle: true, Cycle: cycle}, nil
+	case A_BETWEENNESS:
+		if err := requireSize(g, algorithm, MAX_BETWEENNESS_SIZE); err != nil {
+			return nil, err
+		}
+		return g.Betweenness(), nil
+	case A_PAGERANK:
+		if err := requireSize(g, algorithm, MAX_PAGERANK_SIZE); err != nil {
+			return nil, err
+		}
+		damping, err := floatParam(query, "damping", 0.85)
+		if err != nil {
+			return nil, err
+		}
+		if damping < 0 || damping > 1 {
+			return nil, apperr.Validation("damping must be between 0 and 1")
+		}
+
+		iterations, err := intParam(query, "iterations", 50)
+		if err != nil {
+			return nil, err
+		}
+		if iterations < 1 || iterations > 1000 {
+			return nil, apperr.Validation("iterations must be between 1 and 1000")
+		}
+
+		return g.PageRank(damping, iterations), nil
+	default:
+		return nil, apperr.NotFound(fmt.Sprintf("Unknown algorithm %q", algorithm))
+	}
+}
+
+// requireSize refuses to run an algorithm on a graph of more than limit nodes
+// and edges.
+func requireSize(g *analytics.Graph, algorithm string, limit int) error {
+	size := g.Len()
+	for _, targets := range g.Out {
+		size += len(targets)
+	}
+	if size > limit {
+		return apperr.Validation(fmt.Sprintf("%s can run on at most %d nodes and edges, this project has %d", algorithm, limit, size))
+	}
+	return nil
+}
+
+func floatParam(query url.Values, name string, fallback float64) (float64, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, apperr.Validation(fmt.Sprintf("%s must be a number", name))
+	}
+	return v, nil
+}
+
+func intParam(query url.Values, name string, fallback int) (int, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, apperr.Validation(fmt.Sprintf("%s must be an integer", name))
+	}
+	return v, nil
+}
@@ -0,0 +1,75 @@
+package vapi
+
+import (
+	"fmt"
+	"koppla/apps/vaev/analytics"
+	"koppla/apps/vaev/apperr"
+	"koppla/apps/vaev/arrange"
+	"koppla/apps/vaev/views/graph"
+	"net/url"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// ApplyLayout computes the named layout for a project and stores the new
+// node positions in one transaction. Nodes that were moved while the layout
+// was computed make it fail with a conflict. The moves are audited as made by
+// actor.
+func ApplyLayout(app core.App, project_id string, actor string, name string, opts arrange.Options) (map[string]arrange.Point, error) {
+	signals, err := LoadGraph(app.DB(), &graph.Project{Id: project_id})
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+
+	current := make(map[string]arrange.Point, len(signals.Nodes))
+	revisions := make(map[string]int, len(signals.Nodes))
+	for _, n := range signals.Nodes {
+		current[n.Id] = arrange.Point{X: n.X, Y: n.Y}
+		revisions[n.Id] = n.Revision
+	}
+
+	points, ok := arrange.Compute(name, analytics.New(signals.Nodes, signals.Edges), current, opts)
+	if !ok {
+		return nil, apperr.NotFound(fmt.Sprintf("Unknown layout %q", name))
+	}
+
+	patches := make([]NodePatch, 0, len(points))
+	for id, p := range points {
+		patches = append(patches, NodePatch{Id: id, Revision: revisions[id], X: &p.X, Y: &p.Y})
+	}
+
+	if _, err := ApplyChangeset(app, project_id, Changeset{
+		UpdateNodes:  patches,
+		SkipRejected: true,
+		Actor:        actor,
+	}); err != nil {
+		return nil, err
+	}
+
+	return points, nil
+}
+
+func layoutOptions(query url.Values) (arrange.Options, error) {
+	opts := arrange.DefaultOptions()
+
+	spacing, err := intParam(query, "spacing", opts.Spacing)
+	if err != nil {
+		return opts, err
+	}
+	if spacing < 10 || spacing > 2000 {
+		return opts, apperr.Validation("spacing must be between 10 and 2000")
+	}
+
+	iterations, err := intParam(query, "iterations", opts.Iterations)
+	if err != nil {
+		return opts, err
+	}
+	if iterations < 1 || iterations > 5000 {
+		return opts, apperr.Validation("iterations must be between 1 and 5000")
+	}
+
+	opts.Spacing = spacing
+	opts.Iterations = iterations
+	opts.Root = query.Get("root")
+	return opts, nil
+}
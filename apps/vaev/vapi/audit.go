@@ -0,0 +1,57 @@
+package vapi
+
+import (
+	"fmt"
+	"koppla/apps/vaev/apperr"
+	"koppla/apps/vaev/audit"
+	"net/url"
+	"time"
+)
+
+// auditFilter reads the filter of the audit route from the query. Times may
+// be given as RFC 3339 timestamps or as dates, a date in "to" includes the
+// whole day.
+func auditFilter(query url.Values) (audit.Filter, error) {
+	filter := audit.Filter{
+		User:      query.Get("user"),
+		Entity:    query.Get("entity"),
+		EntityId:  query.Get("entity_id"),
+		Operation: query.Get("operation"),
+	}
+
+	limit, err := intParam(query, "limit", 100)
+	if err != nil {
+		return filter, err
+	}
+	if limit < 1 || limit > audit.MAX_LIMIT {
+		return filter, apperr.Validation(fmt.Sprintf("limit must be between 1 and %d", audit.MAX_LIMIT))
+	}
+	filter.Limit = limit
+
+	if filter.From, err = timeParam(query, "from", 0); err != nil {
+		return filter, err
+	}
+	if filter.To, err = timeParam(query, "to", 24*time.Hour-time.Millisecond); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// timeParam formats a time from the query the way PocketBase stores them.
+// day_offset is added to plain dates.
+func timeParam(query url.Values, name string, day_offset time.Duration) (string, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return "", nil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		day, day_err := time.Parse(time.DateOnly, raw)
+		if day_err != nil {
+			return "", apperr.Validation(fmt.Sprintf("%s must be a date or an RFC 3339 timestamp", name))
+		}
+		t = day.Add(day_offset)
+	}
+	return t.UTC().Format("2006-01-02 15:04:05.000Z"), nil
+}
@@ -0,0 +1,977 @@
+package vapi
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"koppla/apps/vaev/apperr"
+	"koppla/apps/vaev/audit"
+	"koppla/apps/vaev/views/graph"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Changeset is a batch of graph mutations for a single project that is
+// applied in one transaction. Records created in the same changeset can
+// reference each other by the temporary id the client gave them, e.g. an
+// edge whose start_id is the id of a node in CreateNodes.
+type Changeset struct {
+	CreateNodeTypes []graph.NodeType `json:"create_node_types"`
+	UpdateNodeTypes []graph.NodeType `json:"update_node_types"`
+	DeleteNodeTypes []string         `json:"delete_node_types"`
+	CreateEdgeTypes []graph.EdgeType `json:"create_edge_types"`
+	UpdateEdgeTypes []graph.EdgeType `json:"update_edge_types"`
+	DeleteEdgeTypes []string         `json:"delete_edge_types"`
+	CreateNodes     []graph.Node     `json:"create_nodes"`
+	UpdateNodes     []NodePatch      `json:"update_nodes"`
+	DeleteNodes     []string         `json:"delete_nodes"`
+	CreateEdges     []graph.Edge     `json:"create_edges"`
+	UpdateEdges     []EdgePatch      `json:"update_edges"`
+	DeleteEdges     []string         `json:"delete_edges"`
+	// Deleted nodes and edges stay in the trash for a while, see trash.go,
+	// and can be restored from there.
+	RestoreNodes []string `json:"restore_nodes"`
+	RestoreEdges []string `json:"restore_edges"`
+
+	// Types that are still in use are only deleted when they map to the
+	// type their nodes or edges are moved to.
+	ReassignNodeTypes map[string]string `json:"reassign_node_types"`
+	ReassignEdgeTypes map[string]string `json:"reassign_edge_types"`
+
+	// SkipRejected makes updates and deletes of ids that are not part of
+	// the project get listed in ChangesetResult.Rejected instead of failing
+	// the whole changeset.
+	SkipRejected bool `json:"skip_rejected"`
+
+	// DryRun rolls the transaction back once every operation has been
+	// applied, so that the result tells what the changeset would do.
+	DryRun bool `json:"dry_run"`
+
+	// Actor is the user the changeset is applied for, every change is
+	// recorded in the audit log under their id. It is set by the server and
+	// never read from a request.
+	Actor string `json:"-"`
+}
+
+// RequiredPermission returns the permissions a member needs to apply the
+// changeset: types belong to the project, nodes and edges each have their own
+// permission.
+func (cs *Changeset) RequiredPermission() graph.Permission {
+	var required graph.Permission
+	if len(cs.CreateNodeTypes)+len(cs.UpdateNodeTypes)+len(cs.DeleteNodeTypes)+
+		len(cs.CreateEdgeTypes)+len(cs.UpdateEdgeTypes)+len(cs.DeleteEdgeTypes) > 0 {
+		required |= graph.P_MANAGE_PROJECT
+	}
+	if len(cs.CreateNodes)+len(cs.UpdateNodes)+len(cs.DeleteNodes)+len(cs.RestoreNodes) > 0 {
+		required |= graph.P_EDIT_NODES
+	}
+	if len(cs.CreateEdges)+len(cs.UpdateEdges)+len(cs.DeleteEdges)+len(cs.RestoreEdges) > 0 {
+		required |= graph.P_EDIT_CONNECTION
+	}
+	return required
+}
+
+// NodePatch updates the fields of a node that are set, leaving the rest
+// untouched. Revision is the revision the client last saw and must be set;
+// when the node has moved on the changeset fails with a *Conflict.
+type NodePatch struct {
+	Id       string  `json:"id"`
+	Revision int     `json:"revision"`
+	Name     *string `json:"name,omitempty"`
+	Type     *string `json:"type,omitempty"`
+	Metadata []byte  `json:"metadata,omitempty"`
+	X        *int    `json:"x,omitempty"`
+	Y        *int    `json:"y,omitempty"`
+}
+
+// EdgePatch updates the fields of an edge that are set, leaving the rest
+// untouched. Revision works as for NodePatch.
+type EdgePatch struct {
+	Id       string  `json:"id"`
+	Revision int     `json:"revision"`
+	StartId  *string `json:"start_id,omitempty"`
+	EndId    *string `json:"end_id,omitempty"`
+	Type     *string `json:"type,omitempty"`
+	Name     *string `json:"name,omitempty"`
+	Metadata []byte  `json:"metadata,omitempty"`
+}
+
+// ChangesetResult holds the records created by a changeset and maps every
+// temporary id to the id it was persisted with.
+type ChangesetResult struct {
+	Ids          map[string]string `json:"ids"`
+	NodeTypes    []graph.NodeType  `json:"node_types"`
+	EdgeTypes    []graph.EdgeType  `json:"edge_types"`
+	Nodes        []graph.Node      `json:"nodes"`
+	Edges        []graph.Edge      `json:"edges"`
+	UpdatedEdges []graph.Edge      `json:"updated_edges"`
+	Deleted      int               `json:"deleted"`
+	Restored     int               `json:"restored"`
+	// CascadedEdges lists the edges that were deleted along with their nodes,
+	// RestoredEdges the ones that were restored along with them.
+	CascadedEdges []string `json:"cascaded_edges"`
+	RestoredEdges []string `json:"restored_edges"`
+	// Reassigned lists the nodes and edges that were moved to another type
+	// because theirs was deleted, Migrated the ones whose metadata was moved
+	// over to a new schema of their type.
+	Reassigned []string `json:"reassigned"`
+	Migrated   []string `json:"migrated"`
+	Rejected   []string `json:"rejected"`
+	// Revisions holds the new revision of every updated node and edge.
+	Revisions map[string]int `json:"revisions"`
+}
+
+// errDryRun rolls back the transaction of a dry run.
+var errDryRun = errors.New("dry run")
+
+// ChangesetError points at the operation in a changeset that made the whole
+// batch roll back.
+type ChangesetError struct {
+	Message string `json:"message"`
+	Op      string `json:"op"`
+	Index   int    `json:"index"`
+	Id      string `json:"id,omitempty"`
+}
+
+func (e *ChangesetError) Error() string {
+	return fmt.Sprintf("%s[%d]: %s", e.Op, e.Index, e.Message)
+}
+
+// Conflict lists the records a changeset tried to update from a revision
+// that is no longer current, as they are stored now.
+type Conflict struct {
+	Nodes []graph.Node `json:"nodes"`
+	Edges []graph.Edge `json:"edges"`
+}
+
+func (c *Conflict) Error() string {
+	return fmt.Sprintf("%d nodes and %d edges were changed by someone else", len(c.Nodes), len(c.Edges))
+}
+
+func (c *Conflict) empty() bool {
+	return len(c.Nodes) == 0 && len(c.Edges) == 0
+}
+
+func newChangesetResult() *ChangesetResult {
+	return &ChangesetResult{
+		Ids:       map[string]string{},
+		NodeTypes: []graph.NodeType{},
+		EdgeTypes: []graph.EdgeType{},
+		Nodes:     []graph.Node{},
+		Edges:     []graph.Edge{},
+
+		UpdatedEdges:  []graph.Edge{},
+		CascadedEdges: []string{},
+		RestoredEdges: []string{},
+		Reassigned:    []string{},
+		Migrated:      []string{},
+		Rejected:      []string{},
+		Revisions:     map[string]int{},
+	}
+}
+
+// ApplyChangeset applies cs to the project inside a transaction. Either every
+// operation is committed or none is; when a specific operation was rejected
+// the returned validation error carries its *ChangesetError as details, when
+// records were stale the conflict error carries the *Conflict. Once it is
+// committed the thumbnail of the project is drawn again.
+func ApplyChangeset(app core.App, project_id string, cs Changeset) (*ChangesetResult, error) {
+	result := newChangesetResult()
+
+	err := app.RunInTransaction(func(tx core.App) error {
+		m := &mutation{
+			db:            tx.DB(),
+			project_id:    project_id,
+			skip_rejected: cs.SkipRejected,
+			actor:         cs.Actor,
+			now:           time.Now().UTC().Format("2006-01-02 15:04:05.000Z"),
+			result:        result,
+			conflict:      &Conflict{Nodes: []graph.Node{}, Edges: []graph.Edge{}},
+		}
+		if err := m.apply(cs); err != nil {
+			return err
+		}
+		if cs.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+	}
+
+	var cs_err *ChangesetError
+	if errors.As(err, &cs_err) {
+		return nil, apperr.Validation(cs_err.Message).WithDetails(cs_err)
+	}
+	var conflict *Conflict
+	if errors.As(err, &conflict) {
+		return nil, apperr.Conflict(conflict.Error()).WithDetails(conflict)
+	}
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+
+	if !cs.DryRun {
+		ScheduleThumbnail(app, project_id)
+	}
+	return result, nil
+}
+
+type mutation struct {
+	db            dbx.Builder
+	project_id    string
+	skip_rejected bool
+	actor         string
+	// now is when the changeset is applied, records it deletes are moved to
+	// the trash at that time.
+	now      string
+	result   *ChangesetResult
+	conflict *Conflict
+	changes  []audit.Change
+}
+
+// entities maps the tables a changeset writes to to the entities of the
+// audit log.
+var entities = map[string]string{
+	"node_types": audit.ENT_NODE_TYPE,
+	"edge_types": audit.ENT_EDGE_TYPE,
+	"nodes":      audit.ENT_NODE,
+	"edges":      audit.ENT_EDGE,
+}
+
+func (m *mutation) apply(cs Changeset) error {
+	steps := []func() error{
+		func() error { return m.createNodeTypes(cs.CreateNodeTypes) },
+		func() error { return m.updateNodeTypes(cs.UpdateNodeTypes) },
+		func() error { return m.createEdgeTypes(cs.CreateEdgeTypes) },
+		func() error { return m.updateEdgeTypes(cs.UpdateEdgeTypes) },
+		func() error { return m.createNodes(cs.CreateNodes) },
+		func() error { return m.updateNodes(cs.UpdateNodes) },
+		func() error { return m.restoreNodes(cs.RestoreNodes) },
+		func() error { return m.restoreEdges(cs.RestoreEdges) },
+		func() error { return m.createEdges(cs.CreateEdges) },
+		func() error { return m.updateEdges(cs.UpdateEdges) },
+		func() error { return m.delete("edges", "delete_edges", cs.DeleteEdges) },
+		func() error { return m.delete("nodes", "delete_nodes", cs.DeleteNodes) },
+		func() error {
+			return m.deleteTypes("edge_types", "delete_edge_types", cs.DeleteEdgeTypes, cs.ReassignEdgeTypes)
+		},
+		func() error {
+			return m.deleteTypes("node_types", "delete_node_types", cs.DeleteNodeTypes, cs.ReassignNodeTypes)
+		},
+	}
+
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+
+	if !m.conflict.empty() {
+		return m.conflict
+	}
+	if err := audit.Record(m.db, m.project_id, m.actor, m.changes...); err != nil {
+		return err
+	}
+	return pushJournal(m.db, m.project_id, m.actor, m.changes)
+}
+
+// record adds a change to the audit log of the changeset.
+func (m *mutation) record(operation string, table string, id string, before any, after any) {
+	m.changes = append(m.changes, audit.Change{
+		Operation: operation,
+		Entity:    entities[table],
+		EntityId:  id,
+		Before:    before,
+		After:     after,
+	})
+}
+
+// load reads a record of the project as it is stored, for the audit log.
+// Records that do not exist or are in the trash are nil.
+func (m *mutation) load(table string, id string) (any, error) {
+	var record any
+	switch table {
+	case "node_types":
+		record = &graph.NodeType{}
+	case "edge_types":
+		record = &graph.EdgeType{}
+	case "nodes":
+		record = &graph.Node{}
+	case "edges":
+		record = &graph.Edge{}
+	default:
+		return nil, fmt.Errorf("Unknown table %q", table)
+	}
+
+	err := m.db.
+		Select("*").
+		From(table).
+		Where(alive(table, dbx.HashExp{"id": id, "project": m.project_id})).
+		One(record)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// resolve returns the persisted id for an id that may be a temporary id
+// created earlier in the same changeset.
+func (m *mutation) resolve(id string) string {
+	if real_id, ok := m.result.Ids[id]; ok {
+		return real_id
+	}
+	return id
+}
+
+func (m *mutation) opError(op string, index int, id string, err error) error {
+	var cs_err *ChangesetError
+	if errors.As(err, &cs_err) {
+		return err
+	}
+	return &ChangesetError{
+		Message: err.Error(),
+		Op:      op,
+		Index:   index,
+		Id:      id,
+	}
+}
+
+func (m *mutation) createNodeTypes(node_types []graph.NodeType) error {
+	query := `
+	INSERT INTO node_types (name, fill_color, stroke_color, stroke_width, shape, metadata, schema, project)
+	VALUES ({:name}, {:fill_color}, {:stroke_color}, {:stroke_width}, {:shape}, {:metadata}, {:schema}, {:project})
+	RETURNING id
+	`
+
+	for i, t := range node_types {
+		if err := validateNodeType(t); err != nil {
+			return m.opError("create_node_types", i, t.Id, err)
+		}
+
+		var id string
+		if err := m.db.
+			NewQuery(query).
+			Bind(dbx.Params{
+				"name":         t.Name,
+				"fill_color":   t.FillColor,
+				"stroke_color": t.StrokeColor,
+				"stroke_width": t.StrokeWidth,
+				"shape":        t.Shape,
+				"metadata":     t.Metadata,
+				"schema":       t.Schema,
+				"project":      m.project_id,
+			}).
+			Row(&id); err != nil {
+			return m.opError("create_node_types", i, t.Id, err)
+		}
+
+		if t.Id != "" {
+			m.result.Ids[t.Id] = id
+		}
+		t.Id = id
+		t.Project = m.project_id
+		m.result.NodeTypes = append(m.result.NodeTypes, t)
+		m.record(audit.O_CREATE, "node_types", id, nil, t)
+	}
+	return nil
+}
+
+func (m *mutation) updateNodeTypes(node_types []graph.NodeType) error {
+	for i, t := range node_types {
+		if err := validateNodeType(t); err != nil {
+			return m.opError("update_node_types", i, t.Id, err)
+		}
+
+		type_id := m.resolve(t.Id)
+		schema, err := m.schemaOf("node_types", type_id)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return m.opError("update_node_types", i, t.Id, err)
+		}
+
+		updated, err := m.update("node_types", type_id, dbx.Params{
+			"name":         t.Name,
+			"fill_color":   t.FillColor,
+			"stroke_color": t.StrokeColor,
+			"stroke_width": t.StrokeWidth,
+			"shape":        t.Shape,
+			"metadata":     t.Metadata,
+			"schema":       t.Schema,
+		})
+		if err != nil {
+			return m.opError("update_node_types", i, t.Id, err)
+		}
+		if updated {
+			if err := m.updateSchema("node_types", "nodes", type_id, schema); err != nil {
+				return m.opError("update_node_types", i, t.Id, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *mutation) createEdgeTypes(edge_types []graph.EdgeType) error {
+	query := `
+	INSERT INTO edge_types (name, stroke_width, stroke_color, line_dash, metadata, schema, project)
+	VALUES ({:name}, {:stroke_width}, {:stroke_color}, {:line_dash}, {:metadata}, {:schema}, {:project})
+	RETURNING id
+	`
+
+	for i, t := range edge_types {
+		if err := validateEdgeType(t); err != nil {
+			return m.opError("create_edge_types", i, t.Id, err)
+		}
+
+		var id string
+		if err := m.db.
+			NewQuery(query).
+			Bind(dbx.Params{
+				"name":         t.Name,
+				"stroke_width": t.StrokeWidth,
+				"stroke_color": t.StrokeColor,
+				"line_dash":    t.LineDash,
+				"metadata":     t.Metadata,
+				"schema":       t.Schema,
+				"project":      m.project_id,
+			}).
+			Row(&id); err != nil {
+			return m.opError("create_edge_types", i, t.Id, err)
+		}
+
+		if t.Id != "" {
+			m.result.Ids[t.Id] = id
+		}
+		t.Id = id
+		t.Project = m.project_id
+		m.result.EdgeTypes = append(m.result.EdgeTypes, t)
+		m.record(audit.O_CREATE, "edge_types", id, nil, t)
+	}
+	return nil
+}
+
+func (m *mutation) updateEdgeTypes(edge_types []graph.EdgeType) error {
+	for i, t := range edge_types {
+		if err := validateEdgeType(t); err != nil {
+			return m.opError("update_edge_types", i, t.Id, err)
+		}
+
+		type_id := m.resolve(t.Id)
+		schema, err := m.schemaOf("edge_types", type_id)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return m.opError("update_edge_types", i, t.Id, err)
+		}
+
+		updated, err := m.update("edge_types", type_id, dbx.Params{
+			"name":         t.Name,
+			"stroke_width": t.StrokeWidth,
+			"stroke_color": t.StrokeColor,
+			"line_dash":    t.LineDash,
+			"metadata":     t.Metadata,
+			"schema":       t.Schema,
+		})
+		if err != nil {
+			return m.opError("update_edge_types", i, t.Id, err)
+		}
+		if updated {
+			if err := m.updateSchema("edge_types", "edges", type_id, schema); err != nil {
+				return m.opError("update_edge_types", i, t.Id, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *mutation) createNodes(nodes []graph.Node) error {
+	query := `
+	INSERT INTO nodes (x, y, name, project, type, metadata, revision)
+	VALUES ({:x}, {:y}, {:name}, {:project}, {:type}, {:metadata}, 1)
+	RETURNING x, y, name, type, id, metadata, revision
+	`
+
+	for i, node := range nodes {
+		type_id := m.resolve(node.Type)
+		if err := m.requireInProject("node_types", type_id, "Node type"); err != nil {
+			return m.opError("create_nodes", i, node.Id, err)
+		}
+		schema, err := m.schemaOf("node_types", type_id)
+		if err != nil {
+			return m.opError("create_nodes", i, node.Id, err)
+		}
+		metadata, err := checkMetadata(schema, node.Metadata)
+		if err != nil {
+			return m.opError("create_nodes", i, node.Id, err)
+		}
+
+		created := graph.Node{
+			Project: m.project_id,
+			TempId:  node.Id,
+		}
+		if err := m.db.
+			NewQuery(query).
+			Bind(dbx.Params{
+				"x":        node.X,
+				"y":        node.Y,
+				"name":     node.Name,
+				"metadata": metadata,
+				"project":  m.project_id,
+				"type":     type_id,
+			}).
+			Row(&created.X, &created.Y, &created.Name, &created.Type, &created.Id, &created.Metadata, &created.Revision); err != nil {
+			return m.opError("create_nodes", i, node.Id, err)
+		}
+
+		if node.Id != "" {
+			m.result.Ids[node.Id] = created.Id
+		}
+		m.result.Nodes = append(m.result.Nodes, created)
+		m.record(audit.O_CREATE, "nodes", created.Id, nil, created)
+	}
+	return nil
+}
+
+func (m *mutation) updateNodes(patches []NodePatch) error {
+	for i, patch := range patches {
+		if patch.Revision <= 0 {
+			return m.opError("update_nodes", i, patch.Id, fmt.Errorf("node %q needs the revision it was last seen at", patch.Id))
+		}
+		params := dbx.Params{}
+		if patch.Name != nil {
+			params["name"] = *patch.Name
+		}
+		if patch.Type != nil {
+			params["type"] = m.resolve(*patch.Type)
+			if err := m.requireInProject("node_types", params["type"].(string), "Node type"); err != nil {
+				return m.opError("update_nodes", i, patch.Id, err)
+			}
+		}
+		if patch.Metadata != nil {
+			params["metadata"] = patch.Metadata
+		}
+		if err := m.patchMetadata("node_types", "nodes", m.resolve(patch.Id), params); err != nil {
+			return m.opError("update_nodes", i, patch.Id, err)
+		}
+		if patch.X != nil {
+			params["x"] = *patch.X
+		}
+		if patch.Y != nil {
+			params["y"] = *patch.Y
+		}
+
+		if _, err := m.updateRevision("nodes", m.resolve(patch.Id), patch.Revision, params); err != nil {
+			return m.opError("update_nodes", i, patch.Id, err)
+		}
+	}
+	return nil
+}
+
+func (m *mutation) createEdges(edges []graph.Edge) error {
+	query := `
+	INSERT INTO edges (start_id, end_id, type, name, metadata, project, revision)
+	VALUES ({:start_id}, {:end_id}, {:type}, {:name}, {:metadata}, {:project}, 1)
+	RETURNING id, start_id, end_id, type, name, metadata, revision
+	`
+
+	for i, edge := range edges {
+		start_id := m.resolve(edge.StartId)
+		end_id := m.resolve(edge.EndId)
+		type_id := m.resolve(edge.Type)
+
+		if err := m.requireEdgeRefs(start_id, end_id, type_id); err != nil {
+			return m.opError("create_edges", i, edge.Id, err)
+		}
+		schema, err := m.schemaOf("edge_types", type_id)
+		if err != nil {
+			return m.opError("create_edges", i, edge.Id, err)
+		}
+		metadata, err := checkMetadata(schema, edge.Metadata)
+		if err != nil {
+			return m.opError("create_edges", i, edge.Id, err)
+		}
+
+		created := graph.Edge{TempId: edge.Id}
+		if err := m.db.
+			NewQuery(query).
+			Bind(dbx.Params{
+				"start_id": start_id,
+				"end_id":   end_id,
+				"type":     type_id,
+				"name":     edge.Name,
+				"metadata": metadata,
+				"project":  m.project_id,
+			}).
+			Row(&created.Id, &created.StartId, &created.EndId, &created.Type, &created.Name, &created.Metadata, &created.Revision); err != nil {
+			return m.opError("create_edges", i, edge.Id, err)
+		}
+
+		if edge.Id != "" {
+			m.result.Ids[edge.Id] = created.Id
+		}
+		m.result.Edges = append(m.result.Edges, created)
+		m.record(audit.O_CREATE, "edges", created.Id, nil, created)
+	}
+	return nil
+}
+
+func (m *mutation) updateEdges(patches []EdgePatch) error {
+	for i, patch := range patches {
+		if patch.Revision <= 0 {
+			return m.opError("update_edges", i, patch.Id, fmt.Errorf("edge %q needs the revision it was last seen at", patch.Id))
+		}
+		params := dbx.Params{}
+		if patch.StartId != nil {
+			params["start_id"] = m.resolve(*patch.StartId)
+		}
+		if patch.EndId != nil {
+			params["end_id"] = m.resolve(*patch.EndId)
+		}
+		if patch.Type != nil {
+			params["type"] = m.resolve(*patch.Type)
+		}
+		if patch.Name != nil {
+			params["name"] = *patch.Name
+		}
+		if patch.Metadata != nil {
+			params["metadata"] = patch.Metadata
+		}
+
+		start_id, _ := params["start_id"].(string)
+		end_id, _ := params["end_id"].(string)
+		type_id, _ := params["type"].(string)
+		if err := m.requireEdgeRefs(start_id, end_id, type_id); err != nil {
+			return m.opError("update_edges", i, patch.Id, err)
+		}
+		if err := m.patchMetadata("edge_types", "edges", m.resolve(patch.Id), params); err != nil {
+			return m.opError("update_edges", i, patch.Id, err)
+		}
+
+		id := m.resolve(patch.Id)
+		updated_any, err := m.updateRevision("edges", id, patch.Revision, params)
+		if err != nil {
+			return m.opError("update_edges", i, patch.Id, err)
+		}
+		if !updated_any {
+			continue
+		}
+
+		updated := graph.Edge{}
+		if err := m.db.
+			Select("*").
+			From("edges").
+			Where(dbx.HashExp{"id": id}).
+			One(&updated); err != nil {
+			return m.opError("update_edges", i, patch.Id, err)
+		}
+		m.result.UpdatedEdges = append(m.result.UpdatedEdges, updated)
+	}
+	return nil
+}
+
+// requireEdgeRefs checks that the endpoints and type an edge points at belong
+// to the project. Empty ids are not checked.
+func (m *mutation) requireEdgeRefs(start_id string, end_id string, type_id string) error {
+	if err := m.requireInProject("nodes", start_id, "Start node"); err != nil {
+		return err
+	}
+	if err := m.requireInProject("nodes", end_id, "End node"); err != nil {
+		return err
+	}
+	return m.requireInProject("edge_types", type_id, "Edge type")
+}
+
+func (m *mutation) requireInProject(table string, id string, label string) error {
+	if id == "" {
+		return nil
+	}
+
+	ok, err := m.exists(table, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%s %q does not belong to this project", label, id)
+	}
+	return nil
+}
+
+// exists reports whether the record is part of the project and not in the
+// trash.
+func (m *mutation) exists(table string, id string) (bool, error) {
+	var count int
+	if err := m.db.
+		Select("count(*)").
+		From(table).
+		Where(alive(table, dbx.HashExp{"id": id, "project": m.project_id})).
+		Row(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// update writes params to the record with the given id, provided it belongs
+// to the project being mutated, and reports whether a record was written.
+func (m *mutation) update(table string, id string, params dbx.Params) (bool, error) {
+	if len(params) == 0 {
+		return false, nil
+	}
+
+	before, err := m.load(table, id)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := m.db.
+		Update(table, params, alive(table, dbx.HashExp{"id": id, "project": m.project_id})).
+		Execute()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, m.reject(id)
+	}
+	return true, m.recordUpdate(table, id, before)
+}
+
+func (m *mutation) recordUpdate(table string, id string, before any) error {
+	after, err := m.load(table, id)
+	if err != nil {
+		return err
+	}
+	m.record(audit.O_UPDATE, table, id, before, after)
+	return nil
+}
+
+// updateRevision is update for records that carry a revision. The revision
+// is bumped on every write, and when expected is set the write only happens
+// if the record is still at that revision. Records that moved on are added to
+// the conflict instead.
+func (m *mutation) updateRevision(table string, id string, expected int, params dbx.Params) (bool, error) {
+	if len(params) == 0 {
+		return false, nil
+	}
+	before, err := m.load(table, id)
+	if err != nil {
+		return false, err
+	}
+	params["revision"] = dbx.NewExp("revision + 1")
+
+	where := alive(table, dbx.HashExp{"id": id, "project": m.project_id})
+	if expected > 0 {
+		where["revision"] = expected
+	}
+
+	res, err := m.db.Update(table, params, where).Execute()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, m.rejectOrConflict(table, id)
+	}
+
+	var revision int
+	if err := m.db.
+		Select("revision").
+		From(table).
+		Where(dbx.HashExp{"id": id}).
+		Row(&revision); err != nil {
+		return false, err
+	}
+	m.result.Revisions[id] = revision
+	return true, m.recordUpdate(table, id, before)
+}
+
+// rejectOrConflict tells a record that is not part of the project apart from
+// one that is at another revision than the client expected.
+func (m *mutation) rejectOrConflict(table string, id string) error {
+	q := m.db.
+		Select("*").
+		From(table).
+		Where(alive(table, dbx.HashExp{"id": id, "project": m.project_id}))
+
+	var err error
+	switch table {
+	case "nodes":
+		current := graph.Node{}
+		if err = q.One(&current); err == nil {
+			m.conflict.Nodes = append(m.conflict.Nodes, current)
+		}
+	case "edges":
+		current := graph.Edge{}
+		if err = q.One(&current); err == nil {
+			m.conflict.Edges = append(m.conflict.Edges, current)
+		}
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return m.reject(id)
+	}
+	return err
+}
+
+// reject records that id is not part of the project, which fails the
+// changeset unless it was told to skip rejected ids.
+func (m *mutation) reject(id string) error {
+	if !m.skip_rejected {
+		return fmt.Errorf("No record with id %q in this project", id)
+	}
+	m.result.Rejected = append(m.result.Rejected, id)
+	return nil
+}
+
+// delete removes records from the project. Nodes and edges are moved to the
+// trash, types are deleted right away.
+func (m *mutation) delete(table string, op string, ids []string) error {
+	for i, id := range ids {
+		before, err := m.load(table, m.resolve(id))
+		if err != nil {
+			return m.opError(op, i, id, err)
+		}
+		if table == "nodes" && before != nil {
+			if err := m.deleteEdgesOf(m.resolve(id)); err != nil {
+				return m.opError(op, i, id, err)
+			}
+		}
+
+		deleted, err := m.remove(table, m.resolve(id))
+		if err != nil {
+			return m.opError(op, i, id, err)
+		}
+		if !deleted {
+			if err := m.reject(id); err != nil {
+				return m.opError(op, i, id, err)
+			}
+			continue
+		}
+		m.result.Deleted++
+		m.record(audit.O_DELETE, table, m.resolve(id), before, nil)
+	}
+	return nil
+}
+
+// remove deletes a record of the project, or moves it to the trash, and
+// reports whether there was one.
+func (m *mutation) remove(table string, id string) (bool, error) {
+	if trashable[table] {
+		return m.trash(table, id)
+	}
+
+	res, err := m.db.
+		Delete(table, dbx.HashExp{"id": id, "project": m.project_id}).
+		Execute()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// deleteEdgesOf moves the edges of a node that is about to be deleted to the
+// trash along with it, so that no edge is left pointing at it and undoing or
+// restoring the delete brings both back.
+func (m *mutation) deleteEdgesOf(node_id string) error {
+	edges := []graph.Edge{}
+	if err := m.db.
+		Select("*").
+		From("edges").
+		Where(dbx.And(
+			alive("edges", dbx.HashExp{"project": m.project_id}),
+			dbx.Or(dbx.HashExp{"start_id": node_id}, dbx.HashExp{"end_id": node_id}),
+		)).
+		All(&edges); err != nil {
+		return err
+	}
+
+	for _, edge := range edges {
+		if _, err := m.trash("edges", edge.Id); err != nil {
+			return err
+		}
+		m.record(audit.O_DELETE, "edges", edge.Id, edge, nil)
+		m.result.CascadedEdges = append(m.result.CascadedEdges, edge.Id)
+	}
+	return nil
+}
+
+// deleteTypes deletes node or edge types. A type that nodes or edges still
+// use, in the trash as well, is refused unless reassign names the type to
+// move them to first. Types are not trashed themselves, so deleting one that
+// the trash uses would leave records that cannot be restored.
+func (m *mutation) deleteTypes(table string, op string, ids []string, reassign map[string]string) error {
+	records, label := "nodes", "Node type"
+	if table == "edge_types" {
+		records, label = "edges", "Edge type"
+	}
+
+	for i, id := range ids {
+		type_id := m.resolve(id)
+
+		used := []string{}
+		if err := m.db.
+			Select("id").
+			From(records).
+			Where(alive(records, dbx.HashExp{"project": m.project_id, "type": type_id})).
+			Column(&used); err != nil {
+			return m.opError(op, i, id, err)
+		}
+
+		replacement, ok := reassign[id]
+		if !ok {
+			if len(used) > 0 {
+				return m.opError(op, i, id, fmt.Errorf("%s %q is still used by %d %s, choose a type to move them to", label, id, len(used), records))
+			}
+			var in_trash int
+			if err := m.db.
+				Select("COUNT(*)").
+				From(records).
+				Where(dbx.And(dbx.HashExp{"project": m.project_id, "type": type_id}, trashed)).
+				Row(&in_trash); err != nil {
+				return m.opError(op, i, id, err)
+			}
+			if in_trash > 0 {
+				return m.opError(op, i, id, fmt.Errorf("%s %q is still used by %d %s in the trash, choose a type to move them to", label, id, in_trash, records))
+			}
+			continue
+		}
+
+		replacement = m.resolve(replacement)
+		if replacement == type_id {
+			return m.opError(op, i, id, fmt.Errorf("%s %q cannot be replaced by itself", label, id))
+		}
+		if err := m.requireInProject(table, replacement, label); err != nil {
+			return m.opError(op, i, id, err)
+		}
+
+		for _, record_id := range used {
+			if _, err := m.updateRevision(records, record_id, 0, dbx.Params{"type": replacement}); err != nil {
+				return m.opError(op, i, id, err)
+			}
+			m.result.Reassigned = append(m.result.Reassigned, record_id)
+		}
+		// Records in the trash move along, so that they can be restored.
+		if _, err := m.db.
+			Update(records, dbx.Params{"type": replacement}, dbx.And(dbx.HashExp{"project": m.project_id, "type": type_id}, trashed)).
+			Execute(); err != nil {
+			return m.opError(op, i, id, err)
+		}
+	}
+
+	return m.delete(table, op, ids)
+}
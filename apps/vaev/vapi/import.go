@@ -0,0 +1,354 @@
+package vapi
+
+import (
+	"fmt"
+	"io"
+	"koppla/apps/vaev/analytics"
+	"koppla/apps/vaev/apperr"
+	"koppla/apps/vaev/arrange"
+	"koppla/apps/vaev/hub"
+	"koppla/apps/vaev/interchange"
+	"koppla/apps/vaev/views/graph"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+)
+
+// MAX_IMPORT_SIZE is the largest file the import routes accept.
+const MAX_IMPORT_SIZE = 32 << 20
+
+// ImportOptions change how ImportGraph writes to a project.
+type ImportOptions struct {
+	// Replace deletes the nodes, edges and types of the project first.
+	// Nodes and edges go to the trash like any other delete, the types they
+	// use are kept so that they can still be restored. Types of the file that
+	// are the same as a kept one are merged into it.
+	Replace bool
+	// DryRun reports what the import would create without creating it.
+	DryRun bool
+}
+
+// importOptions reads the options of an import from its query:
+// ?mode=merge|replace and ?dry_run=true.
+func importOptions(query url.Values) (ImportOptions, error) {
+	opts := ImportOptions{}
+	switch mode := query.Get("mode"); mode {
+	case "", "merge":
+	case "replace":
+		opts.Replace = true
+	default:
+		return opts, apperr.Validation(fmt.Sprintf("Unknown import mode %q, use merge or replace", mode))
+	}
+
+	if raw := query.Get("dry_run"); raw != "" {
+		dry_run, err := strconv.ParseBool(raw)
+		if err != nil {
+			return opts, apperr.Validation("dry_run must be true or false")
+		}
+		opts.DryRun = dry_run
+	}
+	return opts, nil
+}
+
+// ImportReport tells what an import created, or would have created in a dry
+// run, and which records of the file it skipped and why.
+type ImportReport struct {
+	DryRun    bool                   `json:"dry_run"`
+	NodeTypes int                    `json:"node_types"`
+	EdgeTypes int                    `json:"edge_types"`
+	Nodes     int                    `json:"nodes"`
+	Edges     int                    `json:"edges"`
+	Errors    []interchange.RowError `json:"errors"`
+}
+
+// readUpload opens the file a multipart form was posted with in the field
+// file.
+func readUpload(w http.ResponseWriter, r *http.Request) (multipart.File, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, MAX_IMPORT_SIZE)
+	if err := r.ParseMultipartForm(MAX_IMPORT_SIZE); err != nil {
+		return nil, apperr.Validation(fmt.Sprintf("Upload a file of at most %d MB as a multipart form", MAX_IMPORT_SIZE>>20))
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		return nil, apperr.Validation("The form has no file")
+	}
+	return file, nil
+}
+
+// readImport opens a file that was either uploaded in a multipart form or
+// posted as the body of the request.
+func readImport(w http.ResponseWriter, r *http.Request) (io.ReadCloser, error) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		return readUpload(w, r)
+	}
+	return http.MaxBytesReader(w, r.Body, MAX_IMPORT_SIZE), nil
+}
+
+// loadTypes reads the types of a project for an import to map onto.
+func loadTypes(db dbx.Builder, project_id string) (*interchange.Types, map[string][]graph.Attribute, error) {
+	node_types := []graph.NodeType{}
+	if err := db.Select("*").From("node_types").Where(dbx.HashExp{"project": project_id}).All(&node_types); err != nil {
+		return nil, nil, err
+	}
+	edge_types := []graph.EdgeType{}
+	if err := db.Select("*").From("edge_types").Where(dbx.HashExp{"project": project_id}).All(&edge_types); err != nil {
+		return nil, nil, err
+	}
+
+	schemas := map[string][]graph.Attribute{}
+	for _, t := range node_types {
+		schemas[t.Id], _ = decodeSchema(t.Schema)
+	}
+	for _, t := range edge_types {
+		schemas[t.Id], _ = decodeSchema(t.Schema)
+	}
+	return interchange.NewTypes(node_types, edge_types), schemas, nil
+}
+
+// ImportGraph creates what was read from a file in the project, all of it or
+// nothing. Values are converted to the attribute types of their type's
+// schema first, nodes whose metadata does not fit are skipped along with
+// their edges and reported like rows the file could not be read from.
+func ImportGraph(app *pocketbase.PocketBase, live *hub.Hub, r *http.Request, project *graph.Project, g *interchange.Graph, schemas map[string][]graph.Attribute, row_errors []interchange.RowError, opts ImportOptions) (*ImportReport, error) {
+	report := &ImportReport{DryRun: opts.DryRun, Errors: row_errors}
+	skip := func(id string, kind string, name string, err error) {
+		if len(report.Errors) < interchange.MAX_ROW_ERRORS {
+			report.Errors = append(report.Errors, interchange.RowError{
+				Row:     g.Rows[id],
+				Message: fmt.Sprintf("%s %q: %s", kind, name, err),
+			})
+		}
+	}
+
+	// Replacing deletes the project first, apart from the types that records
+	// in the trash use. File types that are the same as one of those are
+	// merged into it, so that importing the same file again does not pile up
+	// types of the same name.
+	replaced := Changeset{}
+	if opts.Replace {
+		signals, err := LoadGraph(app.DB(), project)
+		if err != nil {
+			return nil, apperr.Internal(err)
+		}
+		node_types, err := typesInUse(app.DB(), "nodes", project.Id)
+		if err != nil {
+			return nil, apperr.Internal(err)
+		}
+		edge_types, err := typesInUse(app.DB(), "edges", project.Id)
+		if err != nil {
+			return nil, apperr.Internal(err)
+		}
+
+		kept_node_types := []graph.NodeType{}
+		for _, t := range signals.NodeTypes {
+			if slices.Contains(node_types, t.Id) {
+				kept_node_types = append(kept_node_types, t)
+			} else {
+				replaced.DeleteNodeTypes = append(replaced.DeleteNodeTypes, t.Id)
+			}
+		}
+		kept_edge_types := []graph.EdgeType{}
+		for _, t := range signals.EdgeTypes {
+			if slices.Contains(edge_types, t.Id) {
+				kept_edge_types = append(kept_edge_types, t)
+			} else {
+				replaced.DeleteEdgeTypes = append(replaced.DeleteEdgeTypes, t.Id)
+			}
+		}
+		for _, n := range signals.Nodes {
+			replaced.DeleteNodes = append(replaced.DeleteNodes, n.Id)
+		}
+		for _, e := range signals.Edges {
+			replaced.DeleteEdges = append(replaced.DeleteEdges, e.Id)
+		}
+
+		mergeKeptTypes(g, kept_node_types, kept_edge_types)
+		for _, t := range kept_node_types {
+			schemas[t.Id], _ = decodeSchema(t.Schema)
+		}
+		for _, t := range kept_edge_types {
+			schemas[t.Id], _ = decodeSchema(t.Schema)
+		}
+	}
+
+	for _, t := range g.NodeTypes {
+		schemas[t.Id], _ = decodeSchema(t.Schema)
+	}
+	for _, t := range g.EdgeTypes {
+		schemas[t.Id], _ = decodeSchema(t.Schema)
+	}
+
+	skipped := map[string]bool{}
+	nodes := make([]graph.Node, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		metadata := fitMetadata(schemas[n.Type], n.Metadata)
+		if _, err := checkMetadata(schemas[n.Type], metadata); err != nil {
+			skip(n.Id, "Node", n.Name, err)
+			skipped[n.Id] = true
+			continue
+		}
+		n.Metadata = metadata
+		nodes = append(nodes, n)
+	}
+
+	edges := make([]graph.Edge, 0, len(g.Edges))
+	for _, e := range g.Edges {
+		if skipped[e.StartId] || skipped[e.EndId] {
+			continue
+		}
+		metadata := fitMetadata(schemas[e.Type], e.Metadata)
+		if _, err := checkMetadata(schemas[e.Type], metadata); err != nil {
+			skip(e.Id, "Connection", e.Name, err)
+			continue
+		}
+		e.Metadata = metadata
+		edges = append(edges, e)
+	}
+
+	if !opts.DryRun {
+		if err := placeImported(app.DB(), project.Id, opts.Replace, nodes, edges, g.Unplaced); err != nil {
+			return nil, apperr.Internal(err)
+		}
+	}
+
+	changeset := Changeset{
+		CreateNodeTypes: g.NodeTypes,
+		CreateEdgeTypes: g.EdgeTypes,
+		CreateNodes:     nodes,
+		CreateEdges:     edges,
+		DeleteNodeTypes: replaced.DeleteNodeTypes,
+		DeleteEdgeTypes: replaced.DeleteEdgeTypes,
+		DeleteNodes:     replaced.DeleteNodes,
+		DeleteEdges:     replaced.DeleteEdges,
+		DryRun:          opts.DryRun,
+	}
+	if !graph.HasPermission(project.Permissions, changeset.RequiredPermission()) {
+		return nil, apperr.Forbidden("You do not have permission to import this")
+	}
+
+	result, err := Commit(app, live, r, project.Id, changeset)
+	if err != nil {
+		return nil, err
+	}
+
+	report.NodeTypes = len(result.NodeTypes)
+	report.EdgeTypes = len(result.EdgeTypes)
+	report.Nodes = len(result.Nodes)
+	report.Edges = len(result.Edges)
+	return report, nil
+}
+
+// mergeKeptTypes points the nodes and edges of g whose type is the same as
+// one of the kept types at that type instead, and leaves the file's copy out.
+func mergeKeptTypes(g *interchange.Graph, kept_node_types []graph.NodeType, kept_edge_types []graph.EdgeType) {
+	merged := map[string]string{}
+
+	node_types := []graph.NodeType{}
+	for _, t := range g.NodeTypes {
+		i := slices.IndexFunc(kept_node_types, func(kept graph.NodeType) bool { return sameNodeType(kept, t) })
+		if i < 0 {
+			node_types = append(node_types, t)
+			continue
+		}
+		merged[t.Id] = kept_node_types[i].Id
+	}
+	edge_types := []graph.EdgeType{}
+	for _, t := range g.EdgeTypes {
+		i := slices.IndexFunc(kept_edge_types, func(kept graph.EdgeType) bool { return sameEdgeType(kept, t) })
+		if i < 0 {
+			edge_types = append(edge_types, t)
+			continue
+		}
+		merged[t.Id] = kept_edge_types[i].Id
+	}
+	g.NodeTypes, g.EdgeTypes = node_types, edge_types
+
+	for i, n := range g.Nodes {
+		if id, ok := merged[n.Type]; ok {
+			g.Nodes[i].Type = id
+		}
+	}
+	for i, e := range g.Edges {
+		if id, ok := merged[e.Type]; ok {
+			g.Edges[i].Type = id
+		}
+	}
+}
+
+// placeImported lays out the nodes a file gave no position with
+// arrange.Auto, below the nodes that have one. Those are the other nodes of
+// the file and, unless the import replaces them, the nodes of the project.
+func placeImported(db dbx.Builder, project_id string, replace bool, nodes []graph.Node, edges []graph.Edge, unplaced map[string]bool) error {
+	loose := []graph.Node{}
+	left, bottom, placed := 0, 0, false
+	extend := func(x, y int) {
+		if !placed {
+			left, bottom, placed = x, y, true
+		}
+		left, bottom = min(left, x), max(bottom, y)
+	}
+	for _, n := range nodes {
+		if unplaced[n.Id] {
+			loose = append(loose, n)
+		} else {
+			extend(n.X, n.Y)
+		}
+	}
+	if len(loose) == 0 {
+		return nil
+	}
+
+	if !replace {
+		var count, x, y int
+		if err := db.
+			Select("COUNT(*)", "COALESCE(MIN(x), 0)", "COALESCE(MAX(y), 0)").
+			From("nodes").
+			Where(alive("nodes", dbx.HashExp{"project": project_id})).
+			Row(&count, &x, &y); err != nil {
+			return err
+		}
+		if count > 0 {
+			extend(x, y)
+		}
+	}
+
+	opts := arrange.DefaultOptions()
+	points := arrange.Auto(analytics.New(loose, edges), opts)
+
+	top := 0
+	if placed {
+		top = bottom + 2*opts.Spacing
+	}
+	min_x, min_y := math.MaxInt, math.MaxInt
+	for _, p := range points {
+		min_x, min_y = min(min_x, p.X), min(min_y, p.Y)
+	}
+	for i, n := range nodes {
+		if p, ok := points[n.Id]; ok {
+			nodes[i].X = p.X - min_x + left
+			nodes[i].Y = p.Y - min_y + top
+		}
+	}
+	return nil
+}
+
+// typesInUse returns the types that nodes or edges of the project use,
+// including the ones in the trash.
+func typesInUse(db dbx.Builder, table string, project_id string) ([]string, error) {
+	types := []string{}
+	err := db.
+		Select("type").
+		Distinct(true).
+		From(table).
+		Where(dbx.HashExp{"project": project_id}).
+		Column(&types)
+	return types, err
+}
@@ -0,0 +1,415 @@
+package vapi
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"koppla/apps/vaev/apperr"
+	"koppla/apps/vaev/audit"
+	"koppla/apps/vaev/views/graph"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// JOURNAL_SIZE is how many changesets a user can undo in a project.
+const JOURNAL_SIZE = 100
+
+// journalChange is an audit.Change read back from the journal.
+type journalChange struct {
+	Operation string          `json:"operation"`
+	Entity    string          `json:"entity"`
+	EntityId  string          `json:"entity_id"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+}
+
+// journalConflict stops an undo or redo when a record is no longer in the
+// state the journal left it in.
+type journalConflict struct {
+	entity string
+	id     string
+}
+
+func (c *journalConflict) Error() string {
+	return fmt.Sprintf("The %s %s was changed by someone else", strings.ReplaceAll(c.entity, "_", " "), c.id)
+}
+
+// pushJournal adds the changes of a changeset to the journal of the user.
+// Anything the user undid before can no longer be redone.
+func pushJournal(db dbx.Builder, project_id string, user_id string, changes []audit.Change) error {
+	if user_id == "" || len(changes) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	params := dbx.Params{
+		"project": project_id,
+		"user":    user_id,
+		"changes": string(data),
+		"now":     time.Now().UTC().Format("2006-01-02 15:04:05.000Z"),
+		"size":    JOURNAL_SIZE,
+	}
+
+	queries := []string{
+		`DELETE FROM journal_entries
+		WHERE project = {:project} AND user = {:user} AND undone = TRUE`,
+		`INSERT INTO journal_entries (project, user, changes, undone, created, updated)
+		VALUES ({:project}, {:user}, {:changes}, FALSE, {:now}, {:now})`,
+		`DELETE FROM journal_entries
+		WHERE project = {:project} AND user = {:user} AND id NOT IN (
+			SELECT id FROM journal_entries
+			WHERE project = {:project} AND user = {:user}
+			ORDER BY created DESC, rowid DESC
+			LIMIT {:size}
+		)`,
+	}
+	for _, query := range queries {
+		if _, err := db.NewQuery(query).Bind(params).Execute(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Undo reverts the latest changeset of the user that has not been undone,
+// and returns the records it changed.
+func Undo(app core.App, project *graph.Project, user_id string) (*Changes, error) {
+	return travel(app, project, user_id, true)
+}
+
+// Redo applies the changeset the user undid last again.
+func Redo(app core.App, project *graph.Project, user_id string) (*Changes, error) {
+	return travel(app, project, user_id, false)
+}
+
+// travel moves the project one step back or forth through the journal of the
+// user. When someone else changed one of the records since, the step is
+// dropped from the journal, so that the next one can be tried.
+func travel(app core.App, project *graph.Project, user_id string, undo bool) (*Changes, error) {
+	query := `
+	SELECT id, changes FROM journal_entries
+	WHERE project = {:project} AND user = {:user} AND undone = FALSE
+	ORDER BY created DESC, rowid DESC
+	LIMIT 1
+	`
+	nothing := "Nothing to undo"
+	if !undo {
+		query = `
+		SELECT id, changes FROM journal_entries
+		WHERE project = {:project} AND user = {:user} AND undone = TRUE
+		ORDER BY created ASC, rowid ASC
+		LIMIT 1
+		`
+		nothing = "Nothing to redo"
+	}
+
+	var entry_id string
+	changes := &Changes{
+		NodeTypes:        []graph.NodeType{},
+		EdgeTypes:        []graph.EdgeType{},
+		Nodes:            []graph.Node{},
+		Edges:            []graph.Edge{},
+		DeletedNodeTypes: []string{},
+		DeletedEdgeTypes: []string{},
+		DeletedNodes:     []string{},
+		DeletedEdges:     []string{},
+	}
+
+	err := app.RunInTransaction(func(tx core.App) error {
+		var data string
+		err := tx.DB().
+			NewQuery(query).
+			Bind(dbx.Params{"project": project.Id, "user": user_id}).
+			Row(&entry_id, &data)
+		if errors.Is(err, sql.ErrNoRows) {
+			return apperr.NotFound(nothing)
+		}
+		if err != nil {
+			return err
+		}
+
+		journal := []journalChange{}
+		if err := json.Unmarshal([]byte(data), &journal); err != nil {
+			return err
+		}
+
+		if !graph.HasPermission(project.Permissions, journalPermission(journal)) {
+			return apperr.Forbidden("You no longer have permission to change these records")
+		}
+
+		m := &mutation{
+			db:         tx.DB(),
+			project_id: project.Id,
+			actor:      user_id,
+			now:        time.Now().UTC().Format("2006-01-02 15:04:05.000Z"),
+			result:     newChangesetResult(),
+		}
+		if undo {
+			slices.Reverse(journal)
+		}
+		for _, c := range journal {
+			from, to := c.Before, c.After
+			if undo {
+				from, to = c.After, c.Before
+			}
+			if err := m.travel(c.Entity, c.EntityId, from, to, changes); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.DB().
+			Update("journal_entries", dbx.Params{"undone": undo}, dbx.HashExp{"id": entry_id}).
+			Execute(); err != nil {
+			return err
+		}
+
+		return audit.Record(tx.DB(), project.Id, user_id, m.changes...)
+	})
+
+	var conflict *journalConflict
+	if errors.As(err, &conflict) {
+		if _, err := app.DB().
+			Delete("journal_entries", dbx.HashExp{"id": entry_id}).
+			Execute(); err != nil {
+			return nil, apperr.Internal(err)
+		}
+		return nil, apperr.Conflict(fmt.Sprintf("%s since, the change was dropped from your history", conflict.Error()))
+	}
+	var app_err *apperr.Error
+	if errors.As(err, &app_err) {
+		return nil, app_err
+	}
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+
+	ScheduleThumbnail(app, project.Id)
+	return changes, nil
+}
+
+// journalPermission is the permission needed to change the records of a
+// journal entry, matching Changeset.RequiredPermission.
+func journalPermission(journal []journalChange) graph.Permission {
+	var required graph.Permission
+	for _, c := range journal {
+		switch c.Entity {
+		case audit.ENT_NODE_TYPE, audit.ENT_EDGE_TYPE:
+			required |= graph.P_MANAGE_PROJECT
+		case audit.ENT_NODE:
+			required |= graph.P_EDIT_NODES
+		case audit.ENT_EDGE:
+			required |= graph.P_EDIT_CONNECTION
+		}
+	}
+	return required
+}
+
+// travel moves a single record from one state to another, where null stands
+// for a record that does not exist.
+func (m *mutation) travel(entity string, id string, from json.RawMessage, to json.RawMessage, changes *Changes) error {
+	switch entity {
+	case audit.ENT_NODE_TYPE:
+		t, err := moveRecord(m, "node_types", id, from, to, sameNodeType, nodeTypeParams)
+		if err != nil {
+			return err
+		}
+		if t == nil {
+			changes.DeletedNodeTypes = append(changes.DeletedNodeTypes, id)
+		} else {
+			changes.NodeTypes = append(changes.NodeTypes, *t)
+		}
+	case audit.ENT_EDGE_TYPE:
+		t, err := moveRecord(m, "edge_types", id, from, to, sameEdgeType, edgeTypeParams)
+		if err != nil {
+			return err
+		}
+		if t == nil {
+			changes.DeletedEdgeTypes = append(changes.DeletedEdgeTypes, id)
+		} else {
+			changes.EdgeTypes = append(changes.EdgeTypes, *t)
+		}
+	case audit.ENT_NODE:
+		n, err := moveRecord(m, "nodes", id, from, to, sameNode, nodeParams)
+		if err != nil {
+			return err
+		}
+		if n == nil {
+			changes.DeletedNodes = append(changes.DeletedNodes, id)
+		} else {
+			changes.Nodes = append(changes.Nodes, *n)
+		}
+	case audit.ENT_EDGE:
+		e, err := moveRecord(m, "edges", id, from, to, sameEdge, edgeParams)
+		if err != nil {
+			return err
+		}
+		if e == nil {
+			changes.DeletedEdges = append(changes.DeletedEdges, id)
+		} else {
+			changes.Edges = append(changes.Edges, *e)
+		}
+	default:
+		return fmt.Errorf("Unknown journal entity %q", entity)
+	}
+	return nil
+}
+
+// moveRecord checks that the record is still in state from and writes state
+// to, keeping the id it had. It returns the record as stored afterwards, nil
+// when it was deleted.
+func moveRecord[T any](m *mutation, table string, id string, from json.RawMessage, to json.RawMessage, same func(T, T) bool, params func(T) dbx.Params) (*T, error) {
+	expected, err := decodeState[T](from)
+	if err != nil {
+		return nil, err
+	}
+	target, err := decodeState[T](to)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded, err := m.load(table, id)
+	if err != nil {
+		return nil, err
+	}
+	var current *T
+	if loaded != nil {
+		current = loaded.(*T)
+	}
+
+	if (expected == nil) != (current == nil) ||
+		(expected != nil && !same(*expected, *current)) {
+		return nil, &journalConflict{entity: entities[table], id: id}
+	}
+
+	if target == nil {
+		if current == nil {
+			return nil, nil
+		}
+		if _, err := m.remove(table, id); err != nil {
+			return nil, err
+		}
+		m.record(audit.O_DELETE, table, id, current, nil)
+		return nil, nil
+	}
+
+	values := params(*target)
+	operation := audit.O_UPDATE
+	if current == nil {
+		// Records that come back get a revision past the one they were
+		// deleted at, so that stale editors cannot overwrite them.
+		if revision, ok := values["revision"].(int); ok {
+			values["revision"] = revision + 1
+		}
+		values["id"] = id
+		values["project"] = m.project_id
+		if err := m.reinsert(table, values); err != nil {
+			return nil, err
+		}
+		operation = audit.O_CREATE
+	} else {
+		if _, ok := values["revision"]; ok {
+			values["revision"] = dbx.NewExp("revision + 1")
+		}
+		if _, err := m.db.Update(table, values, dbx.HashExp{"id": id, "project": m.project_id}).Execute(); err != nil {
+			return nil, err
+		}
+	}
+
+	stored, err := m.load(table, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var before any
+	if current != nil {
+		before = current
+	}
+	m.record(operation, table, id, before, stored)
+	return stored.(*T), nil
+}
+
+// reinsert writes a record that was deleted back. Records that are still in
+// the trash are taken out of it rather than inserted a second time.
+func (m *mutation) reinsert(table string, values dbx.Params) error {
+	if trashable[table] {
+		values["deleted_at"] = ""
+		res, err := m.db.
+			Update(table, values, dbx.And(dbx.HashExp{"id": values["id"], "project": m.project_id}, trashed)).
+			Execute()
+		if err != nil {
+			return err
+		}
+		if affected, err := res.RowsAffected(); err != nil || affected > 0 {
+			return err
+		}
+	}
+
+	_, err := m.db.Insert(table, values).Execute()
+	return err
+}
+
+func decodeState[T any](raw json.RawMessage) (*T, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	state := new(T)
+	if err := json.Unmarshal(raw, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func nodeTypeParams(t graph.NodeType) dbx.Params {
+	return dbx.Params{
+		"name":         t.Name,
+		"fill_color":   t.FillColor,
+		"stroke_color": t.StrokeColor,
+		"stroke_width": t.StrokeWidth,
+		"shape":        t.Shape,
+		"metadata":     t.Metadata,
+		"schema":       t.Schema,
+	}
+}
+
+func edgeTypeParams(t graph.EdgeType) dbx.Params {
+	return dbx.Params{
+		"name":         t.Name,
+		"stroke_width": t.StrokeWidth,
+		"stroke_color": t.StrokeColor,
+		"line_dash":    t.LineDash,
+		"metadata":     t.Metadata,
+		"schema":       t.Schema,
+	}
+}
+
+func nodeParams(n graph.Node) dbx.Params {
+	return dbx.Params{
+		"name":     n.Name,
+		"type":     n.Type,
+		"x":        n.X,
+		"y":        n.Y,
+		"metadata": n.Metadata,
+		"revision": n.Revision,
+	}
+}
+
+func edgeParams(e graph.Edge) dbx.Params {
+	return dbx.Params{
+		"start_id": e.StartId,
+		"end_id":   e.EndId,
+		"type":     e.Type,
+		"name":     e.Name,
+		"metadata": e.Metadata,
+		"revision": e.Revision,
+	}
+}
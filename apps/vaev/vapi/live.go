@@ -0,0 +1,180 @@
+package vapi
+
+import (
+	"koppla/apps/vaev/arrange"
+	"koppla/apps/vaev/hub"
+	"koppla/apps/vaev/views/auth"
+	"koppla/apps/vaev/views/graph"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+)
+
+// H_CLIENT_ID is the request header the editor sends the id of its live
+// stream in, so that it does not get its own changes echoed back.
+const H_CLIENT_ID = "X-Client-Id"
+
+// Changes is what other editors receive after a write: the current state of
+// every record that was created or updated and the ids of deleted records.
+type Changes struct {
+	NodeTypes        []graph.NodeType `json:"node_types"`
+	EdgeTypes        []graph.EdgeType `json:"edge_types"`
+	Nodes            []graph.Node     `json:"nodes"`
+	Edges            []graph.Edge     `json:"edges"`
+	DeletedNodeTypes []string         `json:"deleted_node_types"`
+	DeletedEdgeTypes []string         `json:"deleted_edge_types"`
+	DeletedNodes     []string         `json:"deleted_nodes"`
+	DeletedEdges     []string         `json:"deleted_edges"`
+}
+
+// Commit applies a changeset on behalf of the signed in user and pushes what
+// it changed to everyone who has the project open.
+func Commit(app *pocketbase.PocketBase, live *hub.Hub, r *http.Request, project_id string, cs Changeset) (*ChangesetResult, error) {
+	if user, err := auth.GetSignedInUser(app, r); err == nil {
+		cs.Actor = user.Id
+	}
+
+	result, err := ApplyChangeset(app, project_id, cs)
+	if err != nil || cs.DryRun {
+		return result, err
+	}
+
+	changes, err := collectChanges(app.DB(), project_id, cs, result)
+	if err != nil {
+		log.Printf("Unable to collect changes of project %s: %v", project_id, err)
+		return result, nil
+	}
+
+	publishChanges(live, r, project_id, changes)
+	return result, nil
+}
+
+func publishChanges(live *hub.Hub, r *http.Request, project_id string, changes *Changes) {
+	live.Publish(project_id, hub.Event{
+		Type:   hub.E_CHANGES,
+		Origin: r.Header.Get(H_CLIENT_ID),
+		Data:   changes,
+	})
+}
+
+// collectChanges reads back every record a changeset touched, so that
+// editors get the stored values rather than what the client sent.
+func collectChanges(db dbx.Builder, project_id string, cs Changeset, result *ChangesetResult) (*Changes, error) {
+	kept := func(ids []string) []string {
+		res := []string{}
+		for _, id := range ids {
+			if slices.Contains(result.Rejected, id) {
+				continue
+			}
+			if real_id, ok := result.Ids[id]; ok {
+				id = real_id
+			}
+			res = append(res, id)
+		}
+		return res
+	}
+
+	node_type_ids := []string{}
+	for _, t := range result.NodeTypes {
+		node_type_ids = append(node_type_ids, t.Id)
+	}
+	for _, t := range cs.UpdateNodeTypes {
+		node_type_ids = append(node_type_ids, t.Id)
+	}
+
+	edge_type_ids := []string{}
+	for _, t := range result.EdgeTypes {
+		edge_type_ids = append(edge_type_ids, t.Id)
+	}
+	for _, t := range cs.UpdateEdgeTypes {
+		edge_type_ids = append(edge_type_ids, t.Id)
+	}
+
+	node_ids := []string{}
+	for _, n := range result.Nodes {
+		node_ids = append(node_ids, n.Id)
+	}
+	for _, n := range cs.UpdateNodes {
+		node_ids = append(node_ids, n.Id)
+	}
+	node_ids = append(node_ids, cs.RestoreNodes...)
+	// Reassigned and Migrated hold nodes and edges alike, selectByIds only
+	// finds those in its table.
+	node_ids = append(node_ids, result.Reassigned...)
+	node_ids = append(node_ids, result.Migrated...)
+
+	edge_ids := []string{}
+	for _, e := range result.Edges {
+		edge_ids = append(edge_ids, e.Id)
+	}
+	for _, e := range cs.UpdateEdges {
+		edge_ids = append(edge_ids, e.Id)
+	}
+	edge_ids = append(edge_ids, cs.RestoreEdges...)
+	edge_ids = append(edge_ids, result.RestoredEdges...)
+	edge_ids = append(edge_ids, result.Reassigned...)
+	edge_ids = append(edge_ids, result.Migrated...)
+
+	changes := &Changes{
+		NodeTypes:        []graph.NodeType{},
+		EdgeTypes:        []graph.EdgeType{},
+		Nodes:            []graph.Node{},
+		Edges:            []graph.Edge{},
+		DeletedNodeTypes: kept(cs.DeleteNodeTypes),
+		DeletedEdgeTypes: kept(cs.DeleteEdgeTypes),
+		DeletedNodes:     kept(cs.DeleteNodes),
+		DeletedEdges:     append(kept(cs.DeleteEdges), result.CascadedEdges...),
+	}
+
+	if err := selectByIds(db, "node_types", project_id, kept(node_type_ids), &changes.NodeTypes); err != nil {
+		return nil, err
+	}
+	if err := selectByIds(db, "edge_types", project_id, kept(edge_type_ids), &changes.EdgeTypes); err != nil {
+		return nil, err
+	}
+	if err := selectByIds(db, "nodes", project_id, kept(node_ids), &changes.Nodes); err != nil {
+		return nil, err
+	}
+	if err := selectByIds(db, "edges", project_id, kept(edge_ids), &changes.Edges); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func selectByIds(db dbx.Builder, table string, project_id string, ids []string, dest any) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	values := make([]any, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, id)
+	}
+
+	return db.
+		Select("*").
+		From(table).
+		Where(dbx.And(
+			alive(table, dbx.HashExp{"project": project_id}),
+			dbx.In("id", values...),
+		)).
+		All(dest)
+}
+
+// movedNodes reads back nodes that were moved by a layout.
+func movedNodes(db dbx.Builder, project_id string, points map[string]arrange.Point) (*Changes, error) {
+	ids := make([]string, 0, len(points))
+	for id := range points {
+		ids = append(ids, id)
+	}
+
+	changes := &Changes{Nodes: []graph.Node{}}
+	if err := selectByIds(db, "nodes", project_id, ids, &changes.Nodes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
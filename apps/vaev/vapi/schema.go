@@ -0,0 +1,371 @@
+package vapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"koppla/apps/vaev/views/graph"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+)
+
+// MAX_ATTRIBUTES is the most attributes the schema of a type may have.
+const MAX_ATTRIBUTES = 64
+
+// decodeSchema reads and checks the attribute schema of a type. Types
+// without a schema accept any metadata.
+func decodeSchema(raw []byte) ([]graph.Attribute, error) {
+	if isEmptyJSON(raw) {
+		return nil, nil
+	}
+
+	schema := []graph.Attribute{}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, fmt.Errorf("Schema must be a list of attributes")
+	}
+	if len(schema) > MAX_ATTRIBUTES {
+		return nil, fmt.Errorf("Schema may have at most %d attributes", MAX_ATTRIBUTES)
+	}
+
+	names := map[string]bool{}
+	for _, a := range schema {
+		if strings.TrimSpace(a.Name) == "" {
+			return nil, fmt.Errorf("Every attribute needs a name")
+		}
+		if names[a.Name] {
+			return nil, fmt.Errorf("Attribute %q is listed twice", a.Name)
+		}
+		names[a.Name] = true
+
+		switch a.Type {
+		case graph.A_STRING, graph.A_NUMBER, graph.A_BOOL, graph.A_DATE, graph.A_URL:
+		case graph.A_ENUM:
+			if len(a.Options) == 0 {
+				return nil, fmt.Errorf("Attribute %q needs the options to choose from", a.Name)
+			}
+		default:
+			return nil, fmt.Errorf("Attribute %q has unknown type %q", a.Name, a.Type)
+		}
+
+		if a.Default != nil {
+			if err := checkValue(a, a.Default); err != nil {
+				return nil, fmt.Errorf("Default of attribute %q does not match its type", a.Name)
+			}
+		}
+	}
+	return schema, nil
+}
+
+// checkMetadata validates metadata against the schema of its type and fills
+// in the defaults of missing attributes. Without a schema it is kept as is.
+func checkMetadata(schema []graph.Attribute, raw []byte) ([]byte, error) {
+	if len(schema) == 0 {
+		return raw, nil
+	}
+
+	values, err := decodeMetadata(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range values {
+		if !slices.ContainsFunc(schema, func(a graph.Attribute) bool { return a.Name == name }) {
+			return nil, fmt.Errorf("Unknown attribute %q", name)
+		}
+	}
+
+	for _, a := range schema {
+		v, ok := values[a.Name]
+		if !ok || v == nil || v == "" {
+			delete(values, a.Name)
+			if a.Default != nil {
+				values[a.Name] = a.Default
+			} else if a.Required {
+				return nil, fmt.Errorf("Attribute %q is required", a.Name)
+			}
+			continue
+		}
+		if err := checkValue(a, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(values)
+}
+
+// migrateMetadata fits metadata written for an earlier schema into the
+// current one. Attributes that were dropped are removed, values are converted
+// to the new type where they can be and removed where they cannot, and
+// missing attributes get their default.
+func migrateMetadata(schema []graph.Attribute, raw []byte) []byte {
+	if len(schema) == 0 {
+		return raw
+	}
+
+	values, err := decodeMetadata(raw)
+	if err != nil {
+		return raw
+	}
+
+	migrated := map[string]any{}
+	for _, a := range schema {
+		if v, ok := coerceValue(a, values[a.Name]); ok {
+			migrated[a.Name] = v
+		} else if a.Default != nil {
+			migrated[a.Name] = a.Default
+		}
+	}
+
+	data, err := json.Marshal(migrated)
+	if err != nil {
+		return raw
+	}
+	return data
+}
+
+// fitMetadata converts imported values, which are often all text, to the
+// types of the schema where they can be. Values that cannot be converted are
+// left for checkMetadata to report.
+func fitMetadata(schema []graph.Attribute, raw []byte) []byte {
+	if len(schema) == 0 {
+		return raw
+	}
+
+	values, err := decodeMetadata(raw)
+	if err != nil {
+		return raw
+	}
+	for _, a := range schema {
+		if v, ok := coerceValue(a, values[a.Name]); ok {
+			values[a.Name] = v
+		}
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return raw
+	}
+	return data
+}
+
+// migrateRecords moves the metadata of every node or edge of a type, in the
+// trash or not, over to a new schema of the type.
+func (m *mutation) migrateRecords(table string, type_id string, schema []graph.Attribute) error {
+	type record struct {
+		Id        string `db:"id"`
+		Metadata  []byte `db:"metadata"`
+		DeletedAt string `db:"deleted_at"`
+	}
+	records := []record{}
+	if err := m.db.
+		Select("id", "metadata", "deleted_at").
+		From(table).
+		Where(dbx.HashExp{"project": m.project_id, "type": type_id}).
+		All(&records); err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		metadata := migrateMetadata(schema, r.Metadata)
+		if bytes.Equal(metadata, r.Metadata) {
+			continue
+		}
+
+		if r.DeletedAt != "" {
+			if _, err := m.db.
+				Update(table, dbx.Params{"metadata": metadata}, dbx.HashExp{"id": r.Id}).
+				Execute(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := m.updateRevision(table, r.Id, 0, dbx.Params{"metadata": metadata}); err != nil {
+			return err
+		}
+		m.result.Migrated = append(m.result.Migrated, r.Id)
+	}
+	return nil
+}
+
+// schemaOf reads the schema of a node or edge type of the project.
+func (m *mutation) schemaOf(table string, type_id string) ([]graph.Attribute, error) {
+	var raw []byte
+	if err := m.db.
+		Select("schema").
+		From(table).
+		Where(dbx.HashExp{"id": type_id, "project": m.project_id}).
+		Row(&raw); err != nil {
+		return nil, err
+	}
+	return decodeSchema(raw)
+}
+
+// updateSchema migrates the records of a type when an update changes its
+// schema. before is the schema the type had.
+func (m *mutation) updateSchema(table string, records string, type_id string, before []graph.Attribute) error {
+	after, err := m.schemaOf(table, type_id)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+	return m.migrateRecords(records, type_id, after)
+}
+
+// patchMetadata checks the metadata a node or edge is updated with against
+// the schema of its type. When only the type changes, the metadata it has is
+// moved over to the schema of the new type.
+func (m *mutation) patchMetadata(types string, table string, id string, params dbx.Params) error {
+	type_id, type_changed := params["type"].(string)
+	metadata, has_metadata := params["metadata"].([]byte)
+	if !type_changed && !has_metadata {
+		return nil
+	}
+
+	current, err := m.load(table, id)
+	if err != nil || current == nil {
+		// Records that are not there are rejected by the update.
+		return err
+	}
+	var current_type string
+	var current_metadata []byte
+	switch r := current.(type) {
+	case *graph.Node:
+		current_type, current_metadata = r.Type, r.Metadata
+	case *graph.Edge:
+		current_type, current_metadata = r.Type, r.Metadata
+	}
+	if !type_changed {
+		type_id = current_type
+	}
+
+	schema, err := m.schemaOf(types, type_id)
+	if err != nil {
+		return err
+	}
+	if !has_metadata {
+		if type_id == current_type {
+			return nil
+		}
+		metadata = migrateMetadata(schema, current_metadata)
+	}
+
+	checked, err := checkMetadata(schema, metadata)
+	if err != nil {
+		return err
+	}
+	params["metadata"] = checked
+	return nil
+}
+
+func checkValue(a graph.Attribute, v any) error {
+	ok := false
+	switch a.Type {
+	case graph.A_STRING:
+		_, ok = v.(string)
+	case graph.A_NUMBER:
+		_, ok = v.(float64)
+	case graph.A_BOOL:
+		_, ok = v.(bool)
+	case graph.A_DATE:
+		s, is_string := v.(string)
+		ok = is_string && isDate(s)
+	case graph.A_ENUM:
+		s, is_string := v.(string)
+		ok = is_string && slices.Contains(a.Options, s)
+	case graph.A_URL:
+		s, is_string := v.(string)
+		u, err := url.Parse(s)
+		ok = is_string && err == nil && u.Scheme != "" && (u.Host != "" || u.Opaque != "")
+	}
+	if ok {
+		return nil
+	}
+
+	expected := map[string]string{
+		graph.A_STRING: "text",
+		graph.A_NUMBER: "a number",
+		graph.A_BOOL:   "true or false",
+		graph.A_DATE:   "a date such as 2025-07-16",
+		graph.A_ENUM:   fmt.Sprintf("one of %s", strings.Join(a.Options, ", ")),
+		graph.A_URL:    "a URL such as https://example.com",
+	}
+	return fmt.Errorf("Attribute %q must be %s", a.Name, expected[a.Type])
+}
+
+// coerceValue converts a value to the type of an attribute, reporting false
+// when it cannot be.
+func coerceValue(a graph.Attribute, v any) (any, bool) {
+	if v == nil {
+		return nil, false
+	}
+	if checkValue(a, v) == nil {
+		return v, true
+	}
+
+	var converted any
+	switch a.Type {
+	case graph.A_STRING:
+		switch v := v.(type) {
+		case float64:
+			converted = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			converted = strconv.FormatBool(v)
+		}
+	case graph.A_NUMBER:
+		if s, ok := v.(string); ok {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+				converted = f
+			}
+		}
+	case graph.A_BOOL:
+		if s, ok := v.(string); ok {
+			if b, err := strconv.ParseBool(strings.TrimSpace(s)); err == nil {
+				converted = b
+			}
+		}
+	case graph.A_DATE, graph.A_ENUM, graph.A_URL:
+		if s, ok := v.(string); ok {
+			converted = strings.TrimSpace(s)
+		}
+	}
+
+	if converted == nil || checkValue(a, converted) != nil {
+		return nil, false
+	}
+	return converted, true
+}
+
+func decodeMetadata(raw []byte) (map[string]any, error) {
+	values := map[string]any{}
+	if isEmptyJSON(raw) {
+		return values, nil
+	}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, fmt.Errorf("Metadata must be a JSON object")
+	}
+	return values, nil
+}
+
+func isDate(s string) bool {
+	if _, err := time.Parse(time.DateOnly, s); err == nil {
+		return true
+	}
+	_, err := time.Parse(time.RFC3339, s)
+	return err == nil
+}
+
+// isEmptyJSON reports whether a JSON column holds nothing, which is how
+// PocketBase stores an empty json field.
+func isEmptyJSON(raw []byte) bool {
+	trimmed := strings.TrimSpace(string(raw))
+	return trimmed == "" || trimmed == "null" || trimmed == `""`
+}
@@ -0,0 +1,401 @@
+package vapi
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"koppla/apps/vaev/apperr"
+	"koppla/apps/vaev/audit"
+	"koppla/apps/vaev/views/graph"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// SnapshotDiff lists what changed between two versions of a project.
+type SnapshotDiff struct {
+	NodeTypes RecordDiff[graph.NodeType] `json:"node_types"`
+	EdgeTypes RecordDiff[graph.EdgeType] `json:"edge_types"`
+	Nodes     RecordDiff[graph.Node]     `json:"nodes"`
+	Edges     RecordDiff[graph.Edge]     `json:"edges"`
+}
+
+type RecordDiff[T any] struct {
+	Added   []T         `json:"added"`
+	Removed []T         `json:"removed"`
+	Changed []Change[T] `json:"changed"`
+}
+
+type Change[T any] struct {
+	Before T `json:"before"`
+	After  T `json:"after"`
+}
+
+const snapshotSummary = `
+SELECT
+	s.id, s.project, s.author, s.name, s.created,
+	COALESCE(u.name, '') AS author_name,
+	COALESCE(json_array_length(s.data, '$.nodes'), 0) AS nodes,
+	COALESCE(json_array_length(s.data, '$.edges'), 0) AS edges
+FROM project_snapshots s
+LEFT JOIN users u ON u.id = s.author
+`
+
+// CaptureSnapshot saves the current graph of the project. Snapshots are never
+// updated, a project is changed back to one through RestoreSnapshot.
+func CaptureSnapshot(app core.App, project *graph.Project, author string, name string) (*graph.Snapshot, error) {
+	signals, err := LoadGraph(app.DB(), project)
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+	// Permissions belong to whoever took the snapshot, not to the snapshot.
+	signals.Project.Permissions = 0
+
+	data, err := json.Marshal(signals)
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+
+	now := time.Now().UTC()
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = fmt.Sprintf("Snapshot of %s", now.Format("2006-01-02 15:04"))
+	}
+
+	query := `
+	INSERT INTO project_snapshots (project, author, name, data, created, updated)
+	VALUES ({:project}, {:author}, {:name}, {:data}, {:now}, {:now})
+	RETURNING id
+	`
+	snapshot := graph.Snapshot{}
+	err = app.RunInTransaction(func(tx core.App) error {
+		var id string
+		if err := tx.DB().
+			NewQuery(query).
+			Bind(dbx.Params{
+				"project": project.Id,
+				"author":  author,
+				"name":    name,
+				"data":    string(data),
+				"now":     now.Format("2006-01-02 15:04:05.000Z"),
+			}).
+			Row(&id); err != nil {
+			return err
+		}
+
+		if err := tx.DB().
+			NewQuery(snapshotSummary + "WHERE s.id = {:id}").
+			Bind(dbx.Params{"id": id}).
+			One(&snapshot); err != nil {
+			return err
+		}
+
+		return audit.Record(tx.DB(), project.Id, author, audit.Change{
+			Operation: audit.O_CREATE,
+			Entity:    audit.ENT_SNAPSHOT,
+			EntityId:  id,
+			After:     snapshot,
+		})
+	})
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+
+	return &snapshot, nil
+}
+
+// ListSnapshots returns the snapshots of the project, newest first.
+func ListSnapshots(db dbx.Builder, project_id string) ([]graph.Snapshot, error) {
+	snapshots := []graph.Snapshot{}
+	if err := db.
+		NewQuery(snapshotSummary + "WHERE s.project = {:project} ORDER BY s.created DESC").
+		Bind(dbx.Params{"project": project_id}).
+		All(&snapshots); err != nil {
+		return nil, apperr.Internal(err)
+	}
+
+	return snapshots, nil
+}
+
+// LoadSnapshot reads a snapshot of the project together with the graph it
+// holds.
+func LoadSnapshot(db dbx.Builder, project_id string, snapshot_id string) (*graph.Snapshot, *GraphSignals, error) {
+	snapshot := graph.Snapshot{}
+	err := db.
+		NewQuery(snapshotSummary + "WHERE s.id = {:id} AND s.project = {:project}").
+		Bind(dbx.Params{"id": snapshot_id, "project": project_id}).
+		One(&snapshot)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, apperr.NotFound("Snapshot not found")
+	}
+	if err != nil {
+		return nil, nil, apperr.Internal(err)
+	}
+
+	var data string
+	if err := db.
+		NewQuery("SELECT data FROM project_snapshots WHERE id = {:id}").
+		Bind(dbx.Params{"id": snapshot_id}).
+		Row(&data); err != nil {
+		return nil, nil, apperr.Internal(err)
+	}
+
+	signals := &GraphSignals{}
+	if err := json.Unmarshal([]byte(data), signals); err != nil {
+		return nil, nil, apperr.Internal(err)
+	}
+
+	return &snapshot, signals, nil
+}
+
+// RestoreSnapshot replaces the graph of the project with the one in the
+// snapshot. The graph as it was before is saved as a snapshot of its own, so
+// a restore can be undone by restoring that one.
+func RestoreSnapshot(app core.App, project *graph.Project, snapshot_id string, author string) (*graph.Snapshot, error) {
+	var backup *graph.Snapshot
+
+	err := app.RunInTransaction(func(tx core.App) error {
+		snapshot, signals, err := LoadSnapshot(tx.DB(), project.Id, snapshot_id)
+		if err != nil {
+			return err
+		}
+
+		backup, err = CaptureSnapshot(tx, project, author, fmt.Sprintf("Before restoring %s", snapshot.Name))
+		if err != nil {
+			return err
+		}
+
+		// Records that survive the restore move on to a new revision, so that
+		// editors holding the old one cannot overwrite the restored state.
+		revisions := map[string]int{}
+		for _, table := range []string{"nodes", "edges"} {
+			rows := []struct {
+				Id       string `db:"id"`
+				Revision int    `db:"revision"`
+			}{}
+			if err := tx.DB().
+				Select("id", "revision").
+				From(table).
+				Where(dbx.HashExp{"project": project.Id}).
+				All(&rows); err != nil {
+				return err
+			}
+			for _, row := range rows {
+				revisions[row.Id] = row.Revision
+			}
+		}
+
+		// The trash is kept, apart from records the snapshot brings back, and
+		// so are the types it uses that the snapshot does not have.
+		restored := map[string][]any{}
+		for _, t := range signals.NodeTypes {
+			restored["node_types"] = append(restored["node_types"], t.Id)
+		}
+		for _, t := range signals.EdgeTypes {
+			restored["edge_types"] = append(restored["edge_types"], t.Id)
+		}
+		for _, n := range signals.Nodes {
+			restored["nodes"] = append(restored["nodes"], n.Id)
+		}
+		for _, e := range signals.Edges {
+			restored["edges"] = append(restored["edges"], e.Id)
+		}
+		records := map[string]string{"edge_types": "edges", "node_types": "nodes"}
+		for _, table := range []string{"edges", "nodes", "edge_types", "node_types"} {
+			where := dbx.Expression(dbx.HashExp{"project": project.Id})
+			if trashable[table] {
+				where = dbx.And(where, dbx.Or(
+					alive(table, dbx.HashExp{}),
+					dbx.In("id", restored[table]...),
+				))
+			} else {
+				in_trash, err := typesInUse(tx.DB(), records[table], project.Id)
+				if err != nil {
+					return err
+				}
+				kept := []any{}
+				for _, id := range in_trash {
+					if !slices.Contains(restored[table], any(id)) {
+						kept = append(kept, id)
+					}
+				}
+				where = dbx.And(where, dbx.NotIn("id", kept...))
+			}
+			if _, err := tx.DB().
+				Delete(table, where).
+				Execute(); err != nil {
+				return err
+			}
+		}
+
+		for _, t := range signals.NodeTypes {
+			if _, err := tx.DB().Insert("node_types", dbx.Params{
+				"id":           t.Id,
+				"name":         t.Name,
+				"fill_color":   t.FillColor,
+				"stroke_color": t.StrokeColor,
+				"stroke_width": t.StrokeWidth,
+				"shape":        t.Shape,
+				"metadata":     t.Metadata,
+				"schema":       t.Schema,
+				"project":      project.Id,
+			}).Execute(); err != nil {
+				return err
+			}
+		}
+		for _, t := range signals.EdgeTypes {
+			if _, err := tx.DB().Insert("edge_types", dbx.Params{
+				"id":           t.Id,
+				"name":         t.Name,
+				"stroke_width": t.StrokeWidth,
+				"stroke_color": t.StrokeColor,
+				"line_dash":    t.LineDash,
+				"metadata":     t.Metadata,
+				"schema":       t.Schema,
+				"project":      project.Id,
+			}).Execute(); err != nil {
+				return err
+			}
+		}
+		for _, n := range signals.Nodes {
+			if _, err := tx.DB().Insert("nodes", dbx.Params{
+				"id":       n.Id,
+				"name":     n.Name,
+				"type":     n.Type,
+				"x":        n.X,
+				"y":        n.Y,
+				"metadata": n.Metadata,
+				"created":  n.Created,
+				"updated":  n.Updated,
+				"revision": revisions[n.Id] + 1,
+				"project":  project.Id,
+			}).Execute(); err != nil {
+				return err
+			}
+		}
+		for _, e := range signals.Edges {
+			if _, err := tx.DB().Insert("edges", dbx.Params{
+				"id":       e.Id,
+				"start_id": e.StartId,
+				"end_id":   e.EndId,
+				"type":     e.Type,
+				"name":     e.Name,
+				"metadata": e.Metadata,
+				"created":  e.Created,
+				"updated":  e.Updated,
+				"revision": revisions[e.Id] + 1,
+				"project":  project.Id,
+			}).Execute(); err != nil {
+				return err
+			}
+		}
+
+		return audit.Record(tx.DB(), project.Id, author, audit.Change{
+			Operation: audit.O_RESTORE,
+			Entity:    audit.ENT_PROJECT,
+			EntityId:  project.Id,
+			Before:    map[string]string{"snapshot": backup.Id},
+			After:     map[string]string{"snapshot": snapshot.Id},
+		})
+	})
+
+	var app_err *apperr.Error
+	if errors.As(err, &app_err) {
+		return nil, app_err
+	}
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+
+	ScheduleThumbnail(app, project.Id)
+	return backup, nil
+}
+
+// DiffGraphs compares two versions of a project by record id.
+func DiffGraphs(before *GraphSignals, after *GraphSignals) *SnapshotDiff {
+	return &SnapshotDiff{
+		NodeTypes: diffRecords(before.NodeTypes, after.NodeTypes, func(t graph.NodeType) string { return t.Id }, sameNodeType),
+		EdgeTypes: diffRecords(before.EdgeTypes, after.EdgeTypes, func(t graph.EdgeType) string { return t.Id }, sameEdgeType),
+		Nodes:     diffRecords(before.Nodes, after.Nodes, func(n graph.Node) string { return n.Id }, sameNode),
+		Edges:     diffRecords(before.Edges, after.Edges, func(e graph.Edge) string { return e.Id }, sameEdge),
+	}
+}
+
+// The same* functions compare what a user can change about a record, leaving
+// out timestamps and revisions.
+
+func sameNodeType(a, b graph.NodeType) bool {
+	return a.Name == b.Name &&
+		a.FillColor == b.FillColor &&
+		a.StrokeColor == b.StrokeColor &&
+		a.StrokeWidth == b.StrokeWidth &&
+		a.Shape == b.Shape &&
+		bytes.Equal(a.Metadata, b.Metadata) &&
+		bytes.Equal(a.Schema, b.Schema)
+}
+
+func sameEdgeType(a, b graph.EdgeType) bool {
+	return a.Name == b.Name &&
+		a.StrokeColor == b.StrokeColor &&
+		a.StrokeWidth == b.StrokeWidth &&
+		bytes.Equal(a.LineDash, b.LineDash) &&
+		bytes.Equal(a.Metadata, b.Metadata) &&
+		bytes.Equal(a.Schema, b.Schema)
+}
+
+func sameNode(a, b graph.Node) bool {
+	return a.Name == b.Name &&
+		a.Type == b.Type &&
+		a.X == b.X &&
+		a.Y == b.Y &&
+		bytes.Equal(a.Metadata, b.Metadata)
+}
+
+func sameEdge(a, b graph.Edge) bool {
+	return a.StartId == b.StartId &&
+		a.EndId == b.EndId &&
+		a.Type == b.Type &&
+		a.Name == b.Name &&
+		bytes.Equal(a.Metadata, b.Metadata)
+}
+
+// diffRecords uses same to decide whether a record that is in both versions
+// changed.
+func diffRecords[T any](before []T, after []T, id func(T) string, same func(T, T) bool) RecordDiff[T] {
+	diff := RecordDiff[T]{
+		Added:   []T{},
+		Removed: []T{},
+		Changed: []Change[T]{},
+	}
+
+	by_id := make(map[string]T, len(before))
+	for _, record := range before {
+		by_id[id(record)] = record
+	}
+
+	seen := make(map[string]bool, len(after))
+	for _, record := range after {
+		seen[id(record)] = true
+		old, ok := by_id[id(record)]
+		if !ok {
+			diff.Added = append(diff.Added, record)
+			continue
+		}
+		if !same(old, record) {
+			diff.Changed = append(diff.Changed, Change[T]{Before: old, After: record})
+		}
+	}
+
+	for _, record := range before {
+		if !seen[id(record)] {
+			diff.Removed = append(diff.Removed, record)
+		}
+	}
+
+	return diff
+}
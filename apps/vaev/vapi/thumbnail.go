@@ -0,0 +1,175 @@
+package vapi
+
+import (
+	"bytes"
+	"koppla/apps/vaev/apperr"
+	"koppla/apps/vaev/render"
+	"koppla/apps/vaev/views/graph"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+// The size of the thumbnail shown for a project on the dashboard.
+const (
+	THUMBNAIL_WIDTH  = 480
+	THUMBNAIL_HEIGHT = 270
+)
+
+// UpdateThumbnail draws the current graph of a project and stores it as the
+// thumbnail of the project. Only the thumbnail column is written, so that
+// edits made to the project in the meantime are kept and updated is left
+// alone.
+func UpdateThumbnail(app core.App, project_id string) error {
+	record, err := app.FindRecordById("projects", project_id)
+	if err != nil {
+		return err
+	}
+
+	signals, err := LoadGraph(app.DB(), &graph.Project{Id: project_id})
+	if err != nil {
+		return err
+	}
+
+	data := bytes.Buffer{}
+	scene := render.NewScene(signals.NodeTypes, signals.EdgeTypes, signals.Nodes, signals.Edges)
+	if err := scene.PNG(&data, render.Options{Width: THUMBNAIL_WIDTH, Height: THUMBNAIL_HEIGHT}); err != nil {
+		return err
+	}
+
+	file, err := filesystem.NewFileFromBytes(data.Bytes(), "thumbnail.png")
+	if err != nil {
+		return err
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	base := record.BaseFilesPath()
+	if err := fsys.UploadFile(file, base+"/"+file.Name); err != nil {
+		return err
+	}
+	if _, err := app.DB().
+		Update("projects", dbx.Params{"thumbnail": file.Name}, dbx.HashExp{"id": project_id}).
+		Execute(); err != nil {
+		fsys.Delete(base + "/" + file.Name)
+		return err
+	}
+
+	if old := record.GetString("thumbnail"); old != "" {
+		if err := fsys.Delete(base + "/" + old); err != nil {
+			log.Printf("Unable to delete the old thumbnail of project %s: %v", project_id, err)
+		}
+		fsys.DeletePrefix(base + "/thumbs_" + old + "/")
+	}
+	return nil
+}
+
+// THUMBNAIL_DELAY is how long the graph of a project has to stay unchanged
+// before its thumbnail is drawn, so that a burst of edits draws it once.
+const THUMBNAIL_DELAY = 5 * time.Second
+
+// S_THUMBNAILS is the key the thumbnail worker is kept under in the store of
+// the app.
+const S_THUMBNAILS = "vaev.thumbnails"
+
+// Thumbnails draws the thumbnails of projects in the background. Every
+// project waits for its changes to settle, then a single worker draws them
+// one at a time.
+type Thumbnails struct {
+	delay   time.Duration
+	mu      sync.Mutex
+	pending map[string]*time.Timer
+	queue   chan string
+}
+
+// StartThumbnails starts the thumbnail worker of the app.
+func StartThumbnails(app core.App, delay time.Duration) *Thumbnails {
+	t := &Thumbnails{
+		delay:   delay,
+		pending: map[string]*time.Timer{},
+		queue:   make(chan string, 64),
+	}
+	app.Store().Set(S_THUMBNAILS, t)
+
+	go func() {
+		for project_id := range t.queue {
+			if err := UpdateThumbnail(app, project_id); err != nil {
+				log.Printf("Unable to update the thumbnail of project %s: %v", project_id, err)
+			}
+		}
+	}()
+	return t
+}
+
+// Schedule draws the thumbnail of a project once it has not changed for the
+// delay of the worker.
+func (t *Thumbnails) Schedule(project_id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if timer, ok := t.pending[project_id]; ok && timer.Stop() {
+		timer.Reset(t.delay)
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(t.delay, func() {
+		t.mu.Lock()
+		if t.pending[project_id] == timer {
+			delete(t.pending, project_id)
+		}
+		t.mu.Unlock()
+		t.queue <- project_id
+	})
+	t.pending[project_id] = timer
+}
+
+// ScheduleThumbnail draws the thumbnail of a project again after its graph
+// changed. It does nothing when the app has no thumbnail worker.
+func ScheduleThumbnail(app core.App, project_id string) {
+	if t, ok := app.Store().Get(S_THUMBNAILS).(*Thumbnails); ok {
+		t.Schedule(project_id)
+	}
+}
+
+func renderOptions(query url.Values) (render.Options, error) {
+	opts := render.Options{Labels: query.Get("labels") != "false"}
+
+	width, err := intParam(query, "width", 0)
+	if err != nil {
+		return opts, err
+	}
+	height, err := intParam(query, "height", 0)
+	if err != nil {
+		return opts, err
+	}
+	if width < 0 || width > render.MAX_SIZE || height < 0 || height > render.MAX_SIZE {
+		return opts, apperr.Validation("width and height must be between 0 and 4096")
+	}
+	if (width == 0) != (height == 0) {
+		return opts, apperr.Validation("width and height must be given together")
+	}
+
+	opts.Width = width
+	opts.Height = height
+	return opts, nil
+}
+
+// renderGraph writes a drawing of a graph in the format of an image export.
+func renderGraph(w http.ResponseWriter, signals *GraphSignals, format string, opts render.Options) error {
+	scene := render.NewScene(signals.NodeTypes, signals.EdgeTypes, signals.Nodes, signals.Edges)
+	if format == "png" {
+		return scene.PNG(w, opts)
+	}
+	return scene.SVG(w, opts)
+}
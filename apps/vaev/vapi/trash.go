@@ -0,0 +1,260 @@
+package vapi
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"koppla/apps/vaev/audit"
+	"koppla/apps/vaev/views/dashboard"
+	"koppla/apps/vaev/views/graph"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// DEFAULT_TRASH_RETENTION is how long deleted projects, nodes and edges stay
+// in the trash when TRASH_RETENTION_DAYS is not set.
+const DEFAULT_TRASH_RETENTION = 30 * 24 * time.Hour
+
+// trashable lists the tables whose records are moved to the trash instead of
+// being deleted right away.
+var trashable = map[string]bool{
+	"nodes": true,
+	"edges": true,
+}
+
+// alive narrows where down to records that are not in the trash.
+func alive(table string, where dbx.HashExp) dbx.HashExp {
+	if trashable[table] {
+		where["deleted_at"] = ""
+	}
+	return where
+}
+
+var trashed = dbx.NewExp("deleted_at != ''")
+
+// Trash holds the nodes and edges of a project that were deleted but not yet
+// purged, most recently deleted first.
+type Trash struct {
+	Nodes []graph.Node `json:"nodes"`
+	Edges []graph.Edge `json:"edges"`
+}
+
+// TrashRetention reads how long the trash is kept from TRASH_RETENTION_DAYS.
+func TrashRetention() time.Duration {
+	raw := os.Getenv("TRASH_RETENTION_DAYS")
+	if raw == "" {
+		return DEFAULT_TRASH_RETENTION
+	}
+
+	days, err := strconv.Atoi(raw)
+	if err != nil || days < 1 {
+		log.Printf("Ignoring TRASH_RETENTION_DAYS=%q, it must be a positive number of days", raw)
+		return DEFAULT_TRASH_RETENTION
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// ListTrash returns what is in the trash of the project.
+func ListTrash(db dbx.Builder, project_id string) (*Trash, error) {
+	trash := &Trash{
+		Nodes: []graph.Node{},
+		Edges: []graph.Edge{},
+	}
+
+	by_project := dbx.And(dbx.HashExp{"project": project_id}, trashed)
+	if err := db.Select("*").From("nodes").Where(by_project).OrderBy("deleted_at DESC").All(&trash.Nodes); err != nil {
+		return nil, err
+	}
+	if err := db.Select("*").From("edges").Where(by_project).OrderBy("deleted_at DESC").All(&trash.Edges); err != nil {
+		return nil, err
+	}
+
+	return trash, nil
+}
+
+// trash moves a record of the project to the trash and reports whether there
+// was one to move.
+func (m *mutation) trash(table string, id string) (bool, error) {
+	res, err := m.db.
+		Update(table, dbx.Params{"deleted_at": m.now}, alive(table, dbx.HashExp{"id": id, "project": m.project_id})).
+		Execute()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// untrash takes a record of the project out of the trash, moving it on to a
+// new revision so that editors that still hold it cannot overwrite it.
+func (m *mutation) untrash(table string, id string) error {
+	if _, err := m.db.
+		Update(
+			table,
+			dbx.Params{"deleted_at": "", "revision": dbx.NewExp("revision + 1")},
+			dbx.HashExp{"id": id, "project": m.project_id},
+		).
+		Execute(); err != nil {
+		return err
+	}
+
+	restored, err := m.load(table, id)
+	if err != nil {
+		return err
+	}
+	switch r := restored.(type) {
+	case *graph.Node:
+		m.result.Revisions[id] = r.Revision
+	case *graph.Edge:
+		m.result.Revisions[id] = r.Revision
+	}
+
+	m.result.Restored++
+	m.record(audit.O_RESTORE, table, id, nil, restored)
+	return nil
+}
+
+// restoreNodes takes nodes out of the trash. The edges that were deleted
+// along with a node come back with it, as long as their other end is in the
+// project.
+func (m *mutation) restoreNodes(ids []string) error {
+	for i, id := range ids {
+		node := graph.Node{}
+		err := m.db.
+			Select("*").
+			From("nodes").
+			Where(dbx.And(dbx.HashExp{"id": id, "project": m.project_id}, trashed)).
+			One(&node)
+		if errors.Is(err, sql.ErrNoRows) {
+			if err := m.reject(id); err != nil {
+				return m.opError("restore_nodes", i, id, err)
+			}
+			continue
+		}
+		if err != nil {
+			return m.opError("restore_nodes", i, id, err)
+		}
+
+		if err := m.requireInProject("node_types", node.Type, "Node type"); err != nil {
+			return m.opError("restore_nodes", i, id, err)
+		}
+		if err := m.untrash("nodes", id); err != nil {
+			return m.opError("restore_nodes", i, id, err)
+		}
+
+		edges := []graph.Edge{}
+		if err := m.db.
+			Select("*").
+			From("edges").
+			Where(dbx.And(
+				dbx.HashExp{"project": m.project_id, "deleted_at": node.DeletedAt},
+				dbx.Or(dbx.HashExp{"start_id": id}, dbx.HashExp{"end_id": id}),
+			)).
+			All(&edges); err != nil {
+			return m.opError("restore_nodes", i, id, err)
+		}
+
+		for _, edge := range edges {
+			ok, err := m.edgeRestorable(edge)
+			if err != nil {
+				return m.opError("restore_nodes", i, id, err)
+			}
+			if !ok {
+				continue
+			}
+			if err := m.untrash("edges", edge.Id); err != nil {
+				return m.opError("restore_nodes", i, id, err)
+			}
+			m.result.RestoredEdges = append(m.result.RestoredEdges, edge.Id)
+		}
+	}
+	return nil
+}
+
+func (m *mutation) restoreEdges(ids []string) error {
+	for i, id := range ids {
+		edge := graph.Edge{}
+		err := m.db.
+			Select("*").
+			From("edges").
+			Where(dbx.And(dbx.HashExp{"id": id, "project": m.project_id}, trashed)).
+			One(&edge)
+		if errors.Is(err, sql.ErrNoRows) {
+			if err := m.reject(id); err != nil {
+				return m.opError("restore_edges", i, id, err)
+			}
+			continue
+		}
+		if err != nil {
+			return m.opError("restore_edges", i, id, err)
+		}
+
+		ok, err := m.edgeRestorable(edge)
+		if err != nil {
+			return m.opError("restore_edges", i, id, err)
+		}
+		if !ok {
+			return m.opError("restore_edges", i, id, fmt.Errorf("Restore the nodes and the type of connection %q first", id))
+		}
+		if err := m.untrash("edges", id); err != nil {
+			return m.opError("restore_edges", i, id, err)
+		}
+	}
+	return nil
+}
+
+// edgeRestorable reports whether the nodes and the type of an edge are all
+// in the project, outside the trash.
+func (m *mutation) edgeRestorable(edge graph.Edge) (bool, error) {
+	for _, ref := range []struct{ table, id string }{
+		{"nodes", edge.StartId},
+		{"nodes", edge.EndId},
+		{"edge_types", edge.Type},
+	} {
+		ok, err := m.exists(ref.table, ref.id)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// PurgeTrash deletes everything that was moved to the trash before the given
+// time for good.
+func PurgeTrash(app core.App, before time.Time) error {
+	cutoff := before.UTC().Format("2006-01-02 15:04:05.000Z")
+
+	project_ids := []string{}
+	if err := app.DB().
+		Select("id").
+		From("projects").
+		Where(dbx.And(trashed, dbx.NewExp("deleted_at < {:before}", dbx.Params{"before": cutoff}))).
+		Column(&project_ids); err != nil {
+		return err
+	}
+	for _, project_id := range project_ids {
+		if err := dashboard.PurgeProject(app, project_id); err != nil {
+			return fmt.Errorf("Unable to purge project %s: %w", project_id, err)
+		}
+	}
+
+	return app.RunInTransaction(func(tx core.App) error {
+		for _, table := range []string{"edges", "nodes"} {
+			if _, err := tx.DB().
+				Delete(table, dbx.And(trashed, dbx.NewExp("deleted_at < {:before}", dbx.Params{"before": cutoff}))).
+				Execute(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
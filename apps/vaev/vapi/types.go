@@ -0,0 +1,161 @@
+package vapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"koppla/apps/vaev/apperr"
+	"koppla/apps/vaev/views/graph"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var color_pattern = regexp.MustCompile(`^#([0-9a-fA-F]{3,4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+// validateColor accepts hex colors: #rgb, #rgba, #rrggbb and #rrggbbaa.
+func validateColor(label string, color string) error {
+	if !color_pattern.MatchString(color) {
+		return fmt.Errorf("%s %q is not a hex color such as #4a90d9", label, color)
+	}
+	return nil
+}
+
+func validateNodeType(t graph.NodeType) error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("A node type needs a name")
+	}
+	if err := validateColor("Fill color", t.FillColor); err != nil {
+		return err
+	}
+	if err := validateColor("Stroke color", t.StrokeColor); err != nil {
+		return err
+	}
+	if t.StrokeWidth > graph.MAX_STROKE_WIDTH {
+		return fmt.Errorf("Stroke width must be at most %d", graph.MAX_STROKE_WIDTH)
+	}
+	if int(t.Shape) >= len(graph.SHAPES) {
+		return fmt.Errorf("Unknown shape %d", t.Shape)
+	}
+	_, err := decodeSchema(t.Schema)
+	return err
+}
+
+func validateEdgeType(t graph.EdgeType) error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("An edge type needs a name")
+	}
+	if err := validateColor("Stroke color", t.StrokeColor); err != nil {
+		return err
+	}
+	if t.StrokeWidth > graph.MAX_STROKE_WIDTH {
+		return fmt.Errorf("Stroke width must be at most %d", graph.MAX_STROKE_WIDTH)
+	}
+	if _, err := decodeLineDash(t.LineDash); err != nil {
+		return err
+	}
+	_, err := decodeSchema(t.Schema)
+	return err
+}
+
+// decodeLineDash reads the dash pattern of an edge type, a JSON array of
+// segment lengths. An empty pattern draws a solid line.
+func decodeLineDash(raw []byte) ([]float64, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	dash := []float64{}
+	if err := json.Unmarshal(raw, &dash); err != nil {
+		return nil, fmt.Errorf("Line dash must be a list of lengths")
+	}
+	if len(dash) > graph.MAX_LINE_DASH {
+		return nil, fmt.Errorf("Line dash may have at most %d lengths", graph.MAX_LINE_DASH)
+	}
+	total := 0.0
+	for _, length := range dash {
+		if length < 0 {
+			return nil, fmt.Errorf("Line dash lengths cannot be negative")
+		}
+		total += length
+	}
+	if len(dash) > 0 && total < graph.MIN_LINE_DASH {
+		return nil, fmt.Errorf("Line dash lengths must add up to at least %d", graph.MIN_LINE_DASH)
+	}
+	return dash, nil
+}
+
+// NodeTypeFromForm reads a node type from the type editor.
+func NodeTypeFromForm(form url.Values) (graph.NodeType, error) {
+	t := graph.NodeType{
+		Name:        strings.TrimSpace(form.Get("name")),
+		FillColor:   form.Get("fill_color"),
+		StrokeColor: form.Get("stroke_color"),
+		Schema:      schemaParam(form),
+	}
+
+	stroke_width, err := uint8Param(form, "stroke_width")
+	if err != nil {
+		return t, err
+	}
+	t.StrokeWidth = stroke_width
+
+	shape, err := uint8Param(form, "shape")
+	if err != nil {
+		return t, err
+	}
+	t.Shape = shape
+
+	return t, nil
+}
+
+// EdgeTypeFromForm reads an edge type from the type editor, where the line
+// dash is written as lengths separated by commas or spaces.
+func EdgeTypeFromForm(form url.Values) (graph.EdgeType, error) {
+	t := graph.EdgeType{
+		Name:        strings.TrimSpace(form.Get("name")),
+		StrokeColor: form.Get("stroke_color"),
+		LineDash:    []byte{},
+		Schema:      schemaParam(form),
+	}
+
+	stroke_width, err := uint8Param(form, "stroke_width")
+	if err != nil {
+		return t, err
+	}
+	t.StrokeWidth = stroke_width
+
+	dash := []float64{}
+	for _, field := range strings.FieldsFunc(form.Get("line_dash"), func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		length, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return t, apperr.Validation(fmt.Sprintf("Line dash length %q is not a number", field))
+		}
+		dash = append(dash, length)
+	}
+	if len(dash) > 0 {
+		t.LineDash, _ = json.Marshal(dash)
+	}
+
+	return t, nil
+}
+
+// schemaParam reads the attribute schema, written as JSON, of the type
+// editor.
+func schemaParam(form url.Values) []byte {
+	schema := strings.TrimSpace(form.Get("schema"))
+	if schema == "" {
+		return nil
+	}
+	return []byte(schema)
+}
+
+func uint8Param(form url.Values, name string) (uint8, error) {
+	v, err := strconv.ParseUint(form.Get(name), 10, 8)
+	if err != nil {
+		return 0, apperr.Validation(fmt.Sprintf("%s must be a number from 0 to 255", strings.ReplaceAll(name, "_", " ")))
+	}
+	return uint8(v), nil
+}
@@ -4,12 +4,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"koppla/apps/vaev/analytics"
+	"koppla/apps/vaev/apperr"
+	"koppla/apps/vaev/audit"
+	"koppla/apps/vaev/hub"
+	"koppla/apps/vaev/interchange"
 	"koppla/apps/vaev/middleware"
 	"koppla/apps/vaev/views/auth"
 	"koppla/apps/vaev/views/dashboard"
 	"koppla/apps/vaev/views/graph"
 	"log"
 	"net/http"
+	"strings"
+	"unicode"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/pocketbase/dbx"
@@ -25,64 +32,65 @@ type GraphSignals struct {
 	CurrentEdgeType string           `json:"currentedgetype"`
 }
 
-func RegisterVAPI(app *pocketbase.PocketBase, r *chi.Mux) {
-	r.Group(func(r chi.Router) {
-		r.Use(auth.WithAuthJSONGuard(app))
-		r.Use(middleware.WithCSRF)
-		r.Route("/v-api/project", func(r chi.Router) {
-			r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
-				is_owner := dashboard.ValidateProjectOwner(app, w, r)
-				if !is_owner {
-					middleware.WriteJSONUnauthorized(w)
-					return
-				}
+// LoadGraph reads everything the editor needs to render a project.
+func LoadGraph(db dbx.Builder, project *graph.Project) (*GraphSignals, error) {
+	signals := &GraphSignals{
+		Project:   *project,
+		NodeTypes: []graph.NodeType{},
+		EdgeTypes: []graph.EdgeType{},
+		Nodes:     []graph.Node{},
+		Edges:     []graph.Edge{},
+	}
 
-				project := dashboard.GetProject(app, r)
-				if project == nil {
-					log.Fatal("Project was nil")
-				}
+	by_project := dbx.NewExp(
+		"project = {:project_id}",
+		dbx.Params{"project_id": project.Id},
+	)
 
-				data, err := json.Marshal(*project)
-				if err != nil {
-					log.Fatal(err)
-				}
+	if err := db.Select("*").From("node_types").Where(by_project).All(&signals.NodeTypes); err != nil {
+		return nil, err
+	}
+	if err := db.Select("*").From("edge_types").Where(by_project).All(&signals.EdgeTypes); err != nil {
+		return nil, err
+	}
+	if err := db.Select("*").From("nodes").Where(dbx.And(by_project, alive("nodes", dbx.HashExp{}))).All(&signals.Nodes); err != nil {
+		return nil, err
+	}
+	if err := db.Select("*").From("edges").Where(dbx.And(by_project, alive("edges", dbx.HashExp{}))).All(&signals.Edges); err != nil {
+		return nil, err
+	}
 
-				w.Write(data)
-			})
-			r.Post("/{id}/save", func(w http.ResponseWriter, r *http.Request) {
-				is_owner := dashboard.ValidateProjectOwner(app, w, r)
-				if !is_owner {
-					middleware.WriteJSONUnauthorized(w)
-					return
+	return signals, nil
+}
+
+// MutationReport is the response of the single purpose update and delete
+// routes. Rejected lists the ids that were skipped because they are not part
+// of the project, Revisions the new revision of every updated record.
+type MutationReport struct {
+	Message   string         `json:"message"`
+	Rejected  []string       `json:"rejected"`
+	Revisions map[string]int `json:"revisions,omitempty"`
+}
+
+func RegisterVAPI(app *pocketbase.PocketBase, r *chi.Mux, live *hub.Hub) {
+	r.Route("/v-api/project", func(r chi.Router) {
+		// Reads are open to guests for shared projects.
+		r.Group(func(r chi.Router) {
+			r.Use(auth.WithAccessToken(app))
+			r.Get("/{id}", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project, err := dashboard.ValidateProjectViewer(app, r)
+				if err != nil {
+					return err
 				}
 
-				body, err := io.ReadAll(r.Body)
+				return writeJSON(w, *project)
+			}))
+			r.Get("/{id}/node-types", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project, err := dashboard.ValidateProjectViewer(app, r)
 				if err != nil {
-					log.Fatal(err)
+					return err
 				}
 
-				signals := GraphSignals{}
-				json.Unmarshal(body, &signals)
-
-				for _, node := range signals.Nodes {
-					app.DB().
-						NewQuery("UPDATE nodes SET x = {:x}, y = {:y} WHERE id = {:id}").
-						Bind(dbx.Params{
-							"x":  node.X,
-							"y":  node.Y,
-							"id": node.Id,
-						}).
-						Execute()
-				}
-			})
-			r.Get("/{id}/node-types", func(w http.ResponseWriter, r *http.Request) {
-				is_owner := dashboard.ValidateProjectOwner(app, w, r)
-				if !is_owner {
-					middleware.WriteJSONUnauthorized(w)
-					return
-				}
-
-				project_id := chi.URLParam(r, "id")
 				node_types := []graph.NodeType{}
 				if err := app.DB().
 					Select("*").
@@ -90,26 +98,20 @@ func RegisterVAPI(app *pocketbase.PocketBase, r *chi.Mux) {
 					Where(
 						dbx.NewExp(
 							"project = {:project_id}",
-							dbx.Params{"project_id": project_id}),
+							dbx.Params{"project_id": project.Id}),
 					).
 					All(&node_types); err != nil {
-					log.Fatal(err)
+					return apperr.Internal(err)
 				}
 
-				data, err := json.Marshal(&node_types)
+				return writeJSON(w, &node_types)
+			}))
+			r.Get("/{id}/edge-types", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project, err := dashboard.ValidateProjectViewer(app, r)
 				if err != nil {
-					log.Fatal(err)
-				}
-				w.Write(data)
-			})
-			r.Get("/{id}/edge-types", func(w http.ResponseWriter, r *http.Request) {
-				is_owner := dashboard.ValidateProjectOwner(app, w, r)
-				if !is_owner {
-					middleware.WriteJSONUnauthorized(w)
-					return
+					return err
 				}
 
-				project_id := chi.URLParam(r, "id")
 				edge_types := []graph.EdgeType{}
 				if err := app.DB().
 					Select("*").
@@ -117,304 +119,933 @@ func RegisterVAPI(app *pocketbase.PocketBase, r *chi.Mux) {
 					Where(
 						dbx.NewExp(
 							"project = {:project_id}",
-							dbx.Params{"project_id": project_id}),
+							dbx.Params{"project_id": project.Id}),
 					).
 					All(&edge_types); err != nil {
-					log.Fatal(err)
+					return apperr.Internal(err)
 				}
 
-				data, err := json.Marshal(&edge_types)
+				return writeJSON(w, &edge_types)
+			}))
+			// Exports the project as json, the Vaev format, or as graphml,
+			// gexf or dot for other graph tools.
+			r.Get("/{id}/export.{format}", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project, err := dashboard.ValidateProjectViewer(app, r)
 				if err != nil {
-					log.Fatal(err)
+					return err
 				}
-				w.Write(data)
-			})
-			r.Get("/{id}/nodes", func(w http.ResponseWriter, r *http.Request) {
-				is_owner := dashboard.ValidateProjectOwner(app, w, r)
-				if !is_owner {
-					middleware.WriteJSONUnauthorized(w)
-					return
+
+				format := chi.URLParam(r, "format")
+				content_type, ok := EXPORT_TYPES[format]
+				if !ok {
+					return apperr.NotFound("Unknown export format")
 				}
 
-				project_id := chi.URLParam(r, "id")
-				nodes := []graph.Node{}
-				err := app.DB().
-					Select("*").
-					From("nodes").
-					Where(
-						dbx.NewExp(
-							"project = {:project_id}",
-							dbx.Params{"project_id": project_id}),
-					).
-					All(&nodes)
+				opts, err := renderOptions(r.URL.Query())
 				if err != nil {
-					log.Fatal(err)
+					return err
 				}
 
-				data, err := json.Marshal(&nodes)
+				signals, err := LoadGraph(app.DB(), project)
 				if err != nil {
-					log.Fatal(err)
+					return apperr.Internal(err)
 				}
+				doc := interchange.NewDocument(*project, signals.NodeTypes, signals.EdgeTypes, signals.Nodes, signals.Edges)
 
-				record, err := app.FindRecordById("projects", project_id)
+				attachment(w, project, format)
+				w.Header().Set("Content-Type", content_type)
+				switch format {
+				case "graphml":
+					return doc.WriteGraphML(w)
+				case "gexf":
+					return doc.WriteGEXF(w)
+				case "dot":
+					return doc.WriteDOT(w)
+				case "svg", "png":
+					return renderGraph(w, signals, format, opts)
+				}
+				return writeJSON(w, doc)
+			}))
+			r.Get("/{id}/nodes", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project, err := dashboard.ValidateProjectViewer(app, r)
 				if err != nil {
-					log.Fatal(err)
+					return err
 				}
 
-				fmt.Printf("\nRECORD: %+v\n", record)
+				nodes := []graph.Node{}
+				if err := app.DB().
+					Select("*").
+					From("nodes").
+					Where(alive("nodes", dbx.HashExp{"project": project.Id})).
+					All(&nodes); err != nil {
+					return apperr.Internal(err)
+				}
 
-				w.Write(data)
-			})
-			r.Get("/{id}/edges", func(w http.ResponseWriter, r *http.Request) {
-				is_owner := dashboard.ValidateProjectOwner(app, w, r)
-				if !is_owner {
-					middleware.WriteJSONUnauthorized(w)
-					return
+				return writeJSON(w, &nodes)
+			}))
+			r.Get("/{id}/edges", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project, err := dashboard.ValidateProjectViewer(app, r)
+				if err != nil {
+					return err
 				}
 
-				project_id := chi.URLParam(r, "id")
 				edges := []graph.Edge{}
-				app.DB().
+				if err := app.DB().
 					Select("*").
 					From("edges").
-					Where(
-						dbx.NewExp(
-							"project = {:project_id}",
-							dbx.Params{"project_id": project_id}),
-					).
-					All(&edges)
+					Where(alive("edges", dbx.HashExp{"project": project.Id})).
+					All(&edges); err != nil {
+					return apperr.Internal(err)
+				}
 
-				data, err := json.Marshal(&edges)
+				return writeJSON(w, &edges)
+			}))
+			r.Get("/{id}/analytics/{algorithm}", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project, err := dashboard.ValidateProjectViewer(app, r)
 				if err != nil {
-					log.Fatal(err)
+					return err
 				}
-				w.Write(data)
-			})
-			r.Put("/{id}/update-nodes", func(w http.ResponseWriter, r *http.Request) {
-				is_owner := dashboard.ValidateProjectOwner(app, w, r)
-				if !is_owner {
-					middleware.WriteJSONUnauthorized(w)
-					return
+
+				signals, err := LoadGraph(app.DB(), project)
+				if err != nil {
+					return apperr.Internal(err)
 				}
 
-				body, err := io.ReadAll(r.Body)
-				defer r.Body.Close()
+				result, err := runAnalytics(
+					analytics.New(signals.Nodes, signals.Edges),
+					chi.URLParam(r, "algorithm"),
+					r.URL.Query(),
+				)
 				if err != nil {
-					log.Fatal(err)
+					return err
 				}
 
-				nodes := []graph.Node{}
-				if err := json.Unmarshal(body, &nodes); err != nil {
-					log.Fatal(err)
+				return writeJSON(w, result)
+			}))
+			// The audit log names users, so it is kept from guests.
+			r.Get("/{id}/audit", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project, err := dashboard.ValidateProjectPermission(app, r, 0)
+				if err != nil {
+					return err
 				}
 
-				for _, node := range nodes {
-					app.DB().
-						NewQuery("UPDATE nodes SET x = {:x}, y = {:y} WHERE id = {:id}").
-						Bind(dbx.Params{
-							"x":  node.X,
-							"y":  node.Y,
-							"id": node.Id,
-						}).
-						Execute()
+				filter, err := auditFilter(r.URL.Query())
+				if err != nil {
+					return err
 				}
-			})
-			r.Delete("/{id}/delete-nodes", func(w http.ResponseWriter, r *http.Request) {
-				is_owner := dashboard.ValidateProjectOwner(app, w, r)
-				if !is_owner {
-					middleware.WriteJSONUnauthorized(w)
-					return
+
+				events, err := audit.List(app.DB(), project.Id, filter)
+				if err != nil {
+					return apperr.Internal(err)
 				}
 
-				body, err := io.ReadAll(r.Body)
-				defer r.Body.Close()
+				return writeJSON(w, events)
+			}))
+			// Deleted records are kept from guests as well.
+			r.Get("/{id}/trash", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project, err := dashboard.ValidateProjectPermission(app, r, 0)
 				if err != nil {
-					log.Fatal(err)
+					return err
 				}
 
-				node_ids := []string{}
-				if err := json.Unmarshal(body, &node_ids); err != nil {
-					log.Fatal(err)
+				trash, err := ListTrash(app.DB(), project.Id)
+				if err != nil {
+					return apperr.Internal(err)
 				}
 
-				count := 0
-				for _, id := range node_ids {
-					_, err := app.DB().
-						Delete("nodes", dbx.NewExp("id = {:id}", dbx.Params{
-							"id": id,
-						})).
-						Execute()
-					if err != nil {
-						log.Fatal(err)
-					}
-					count += 1
+				return writeJSON(w, trash)
+			}))
+			r.Get("/{id}/snapshots", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project, err := dashboard.ValidateProjectViewer(app, r)
+				if err != nil {
+					return err
 				}
 
-				w.Write(fmt.Appendf(nil, `{"message": "Deteted %d nodes"}`, count))
-			})
-			r.Delete("/{id}/delete-edges", func(w http.ResponseWriter, r *http.Request) {
-				is_owner := dashboard.ValidateProjectOwner(app, w, r)
-				if !is_owner {
-					middleware.WriteJSONUnauthorized(w)
-					return
+				snapshots, err := ListSnapshots(app.DB(), project.Id)
+				if err != nil {
+					return err
 				}
 
-				body, err := io.ReadAll(r.Body)
-				defer r.Body.Close()
+				return writeJSON(w, snapshots)
+			}))
+			// Compares snapshot "from" with snapshot "to", or with the project
+			// as it is now when "to" is left out.
+			r.Get("/{id}/snapshots/diff", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project, err := dashboard.ValidateProjectViewer(app, r)
 				if err != nil {
-					log.Fatal(err)
+					return err
 				}
 
-				edge_ids := []string{}
-				if err := json.Unmarshal(body, &edge_ids); err != nil {
-					log.Fatal(err)
+				from_id := r.URL.Query().Get("from")
+				if from_id == "" {
+					return apperr.Validation("Choose a snapshot to compare")
+				}
+
+				_, from, err := LoadSnapshot(app.DB(), project.Id, from_id)
+				if err != nil {
+					return err
 				}
 
-				count := 0
-				for _, id := range edge_ids {
-					_, err := app.DB().
-						Delete("edges", dbx.NewExp("id = {:id}", dbx.Params{
-							"id": id,
-						})).
-						Execute()
+				var to *GraphSignals
+				if to_id := r.URL.Query().Get("to"); to_id != "" {
+					_, to, err = LoadSnapshot(app.DB(), project.Id, to_id)
+					if err != nil {
+						return err
+					}
+				} else {
+					to, err = LoadGraph(app.DB(), project)
 					if err != nil {
-						log.Fatal(err)
+						return apperr.Internal(err)
 					}
-					count += 1
 				}
 
-				w.Write(fmt.Appendf(nil, `{"message": "Deteted %d edges"}`, count))
-			})
-			r.Post("/{id}/create-nodes", func(w http.ResponseWriter, r *http.Request) {
-				is_owner := dashboard.ValidateProjectOwner(app, w, r)
-				if !is_owner {
-					middleware.WriteJSONUnauthorized(w)
-					return
+				return writeJSON(w, DiffGraphs(from, to))
+			}))
+			r.Get("/{id}/snapshots/{snapshot_id}", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project, err := dashboard.ValidateProjectViewer(app, r)
+				if err != nil {
+					return err
+				}
+
+				_, signals, err := LoadSnapshot(app.DB(), project.Id, chi.URLParam(r, "snapshot_id"))
+				if err != nil {
+					return err
+				}
+
+				return writeJSON(w, signals)
+			}))
+			// Mirrors the project routes above, so that the editor can open a
+			// snapshot the same way it opens a project.
+			r.Get("/{id}/snapshots/{snapshot_id}/{records}", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project, err := dashboard.ValidateProjectViewer(app, r)
+				if err != nil {
+					return err
+				}
+
+				_, signals, err := LoadSnapshot(app.DB(), project.Id, chi.URLParam(r, "snapshot_id"))
+				if err != nil {
+					return err
+				}
+
+				switch chi.URLParam(r, "records") {
+				case "node-types":
+					return writeJSON(w, signals.NodeTypes)
+				case "edge-types":
+					return writeJSON(w, signals.EdgeTypes)
+				case "nodes":
+					return writeJSON(w, signals.Nodes)
+				case "edges":
+					return writeJSON(w, signals.Edges)
+				}
+				return apperr.NotFound("Unknown snapshot records")
+			}))
+		})
+		// Writes require the permission that matches what they touch.
+		r.Group(func(r chi.Router) {
+			r.Use(auth.WithAuthJSONGuard(app))
+			r.Use(middleware.WithCSRF)
+			r.With(dashboard.WithProjectPermission(app, graph.P_EDIT_NODES)).Post("/{id}/save", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				signals := GraphSignals{}
+				if err := readJSON(r, &signals); err != nil {
+					return err
+				}
+
+				result, err := Commit(app, live, r, project.Id, Changeset{
+					UpdateNodes:  positionPatches(signals.Nodes),
+					SkipRejected: true,
+				})
+				if err != nil {
+					return err
+				}
+
+				return writeJSON(w, MutationReport{
+					Message:   "Saved project",
+					Rejected:  result.Rejected,
+					Revisions: result.Revisions,
+				})
+			}))
+			r.With(dashboard.WithProjectPermission(app, graph.P_EDIT_NODES)).Post("/{id}/layout/{algorithm}", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				opts, err := layoutOptions(r.URL.Query())
+				if err != nil {
+					return err
+				}
+
+				user, err := auth.GetSignedInUser(app, r)
+				if err != nil {
+					return apperr.Unauthorized("You need to sign in to arrange a project")
+				}
+
+				points, err := ApplyLayout(app, project.Id, user.Id, chi.URLParam(r, "algorithm"), opts)
+				if err != nil {
+					return err
+				}
+
+				changes, err := movedNodes(app.DB(), project.Id, points)
+				if err != nil {
+					log.Printf("Unable to collect changes of project %s: %v", project.Id, err)
+				} else {
+					publishChanges(live, r, project.Id, changes)
 				}
 
-				project := dashboard.GetProject(app, r)
+				return writeJSON(w, points)
+			}))
+			r.With(dashboard.WithProjectPermission(app, 0)).Post("/{id}/changeset", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
 
-				body, err := io.ReadAll(r.Body)
+				changeset := Changeset{}
+				if err := readJSON(r, &changeset); err != nil {
+					return err
+				}
+				if !graph.HasPermission(project.Permissions, changeset.RequiredPermission()) {
+					return apperr.Forbidden("You do not have permission to apply this changeset")
+				}
+
+				result, err := Commit(app, live, r, project.Id, changeset)
 				if err != nil {
-					log.Fatal(err)
+					return err
 				}
 
+				return writeJSON(w, result)
+			}))
+			r.With(dashboard.WithProjectPermission(app, graph.P_EDIT_NODES)).Put("/{id}/update-nodes", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
 				nodes := []graph.Node{}
-				if err := json.Unmarshal(body, &nodes); err != nil {
-					log.Fatal(err)
-				}
-
-				query := `
-				INSERT INTO nodes (x, y, name, project, type, metadata)
-				VALUES ({:x}, {:y}, {:name}, {:project}, {:type}, {:metadata})
-				RETURNING x, y, name, type, id, metadata
-				`
-
-				res_nodes := []graph.Node{}
-				var x, y int
-				var name, type_id, id string
-				var metadata []byte
-
-				for _, node := range nodes {
-					if err := app.DB().
-						NewQuery(query).Bind(dbx.Params{
-						"x":        node.X,
-						"y":        node.Y,
-						"name":     node.Name,
-						"metadata": node.Metadata,
-						"project":  project.Id,
-						"type":     node.Type,
-					}).Row(&x, &y, &name, &type_id, &id, &metadata); err != nil {
-						log.Fatal(err)
-					}
-					res_nodes = append(res_nodes, graph.Node{
-						X:        x,
-						Y:        y,
-						Id:       id,
-						Name:     name,
-						Type:     type_id,
-						Metadata: metadata,
-						TempId:   node.Id,
-					})
+				if err := readJSON(r, &nodes); err != nil {
+					return err
 				}
 
-				bytes, err := json.Marshal(&res_nodes)
+				result, err := Commit(app, live, r, project.Id, Changeset{
+					UpdateNodes:  positionPatches(nodes),
+					SkipRejected: true,
+				})
 				if err != nil {
-					log.Fatal(err)
+					return err
 				}
-				w.Write(bytes)
-			})
-			r.Post("/{id}/create-edges", func(w http.ResponseWriter, r *http.Request) {
-				is_owner := dashboard.ValidateProjectOwner(app, w, r)
-				if !is_owner {
-					middleware.WriteJSONUnauthorized(w)
-					return
+
+				return writeJSON(w, MutationReport{
+					Message:   fmt.Sprintf("Updated %d nodes", len(nodes)-len(result.Rejected)),
+					Rejected:  result.Rejected,
+					Revisions: result.Revisions,
+				})
+			}))
+			r.With(dashboard.WithProjectPermission(app, graph.P_EDIT_CONNECTION)).Put("/{id}/update-edges", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				edges := []graph.Edge{}
+				if err := readJSON(r, &edges); err != nil {
+					return err
 				}
 
-				project := dashboard.GetProject(app, r)
+				result, err := Commit(app, live, r, project.Id, Changeset{
+					UpdateEdges: edgePatches(edges),
+				})
+				if err != nil {
+					return err
+				}
+
+				return writeJSON(w, &result.UpdatedEdges)
+			}))
+			r.With(dashboard.WithProjectPermission(app, graph.P_EDIT_NODES)).Delete("/{id}/delete-nodes", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				node_ids := []string{}
+				if err := readJSON(r, &node_ids); err != nil {
+					return err
+				}
+
+				result, err := Commit(app, live, r, project.Id, Changeset{
+					DeleteNodes:  node_ids,
+					SkipRejected: true,
+				})
+				if err != nil {
+					return err
+				}
+
+				return writeJSON(w, MutationReport{
+					Message:  fmt.Sprintf("Deleted %d nodes", result.Deleted),
+					Rejected: result.Rejected,
+				})
+			}))
+			r.With(dashboard.WithProjectPermission(app, graph.P_EDIT_CONNECTION)).Delete("/{id}/delete-edges", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				edge_ids := []string{}
+				if err := readJSON(r, &edge_ids); err != nil {
+					return err
+				}
 
-				body, err := io.ReadAll(r.Body)
+				result, err := Commit(app, live, r, project.Id, Changeset{
+					DeleteEdges:  edge_ids,
+					SkipRejected: true,
+				})
 				if err != nil {
-					log.Fatal(err)
+					return err
 				}
 
+				return writeJSON(w, MutationReport{
+					Message:  fmt.Sprintf("Deleted %d edges", result.Deleted),
+					Rejected: result.Rejected,
+				})
+			}))
+			r.With(dashboard.WithProjectPermission(app, graph.P_EDIT_NODES)).Post("/{id}/restore-nodes", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				node_ids := []string{}
+				if err := readJSON(r, &node_ids); err != nil {
+					return err
+				}
+
+				result, err := Commit(app, live, r, project.Id, Changeset{
+					RestoreNodes: node_ids,
+					SkipRejected: true,
+				})
+				if err != nil {
+					return err
+				}
+
+				return writeJSON(w, MutationReport{
+					Message:   fmt.Sprintf("Restored %d nodes and %d edges", result.Restored-len(result.RestoredEdges), len(result.RestoredEdges)),
+					Rejected:  result.Rejected,
+					Revisions: result.Revisions,
+				})
+			}))
+			r.With(dashboard.WithProjectPermission(app, graph.P_EDIT_CONNECTION)).Post("/{id}/restore-edges", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				edge_ids := []string{}
+				if err := readJSON(r, &edge_ids); err != nil {
+					return err
+				}
+
+				result, err := Commit(app, live, r, project.Id, Changeset{
+					RestoreEdges: edge_ids,
+					SkipRejected: true,
+				})
+				if err != nil {
+					return err
+				}
+
+				return writeJSON(w, MutationReport{
+					Message:   fmt.Sprintf("Restored %d edges", result.Restored),
+					Rejected:  result.Rejected,
+					Revisions: result.Revisions,
+				})
+			}))
+			r.With(dashboard.WithProjectPermission(app, graph.P_EDIT_NODES)).Post("/{id}/create-nodes", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				nodes := []graph.Node{}
+				if err := readJSON(r, &nodes); err != nil {
+					return err
+				}
+
+				result, err := Commit(app, live, r, project.Id, Changeset{
+					CreateNodes: nodes,
+				})
+				if err != nil {
+					return err
+				}
+
+				return writeJSON(w, &result.Nodes)
+			}))
+			r.With(dashboard.WithProjectPermission(app, graph.P_EDIT_CONNECTION)).Post("/{id}/create-edges", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
 				edges := []graph.Edge{}
-				if err := json.Unmarshal(body, &edges); err != nil {
-					log.Fatal(err)
-				}
-
-				query := `
-				INSERT INTO edges (start_id, end_id, type, project)
-				VALUES ({:start_id}, {:end_id}, {:type}, {:project})
-				RETURNING id, start_id, end_id, type 
-				`
-
-				res_edges := []graph.Edge{}
-				for _, edge := range edges {
-					var id, start_id, end_id, type_name string
-					if err := app.DB().
-						NewQuery(query).Bind(dbx.Params{
-						"start_id": edge.StartId,
-						"end_id":   edge.EndId,
-						"type":     edge.Type,
-						"project":  project.Id,
-					}).Row(&id, &start_id, &end_id, &type_name); err != nil {
-						log.Fatal(err)
-					}
-					res_edges = append(res_edges, graph.Edge{
-						StartId: start_id,
-						EndId:   end_id,
-						Type:    type_name,
-						Id:      id,
-						TempId:  edge.Id,
-					})
+				if err := readJSON(r, &edges); err != nil {
+					return err
 				}
 
-				bytes, err := json.Marshal(&res_edges)
+				result, err := Commit(app, live, r, project.Id, Changeset{
+					CreateEdges: edges,
+				})
 				if err != nil {
-					log.Fatal(err)
+					return err
 				}
-				w.Write(bytes)
-			})
-			r.Post("/{id}/upload-snapshot", func(w http.ResponseWriter, r *http.Request) {
-				is_owner := dashboard.ValidateProjectOwner(app, w, r)
-				if !is_owner {
-					middleware.WriteJSONUnauthorized(w)
-					return
+
+				return writeJSON(w, &result.Edges)
+			}))
+			// Imports a project in the Vaev JSON format, see
+			// interchange.Document, posted as the body or uploaded in file.
+			// ?mode=replace deletes what the project has first, the default
+			// merge adds to it and reuses the types it has by name. Every
+			// import takes ?dry_run=true to only report what it would do.
+			r.With(dashboard.WithProjectPermission(app, graph.P_EDIT_NODES|graph.P_EDIT_CONNECTION)).Post("/{id}/import", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				opts, err := importOptions(r.URL.Query())
+				if err != nil {
+					return err
 				}
-				project_id := chi.URLParam(r, "id")
-				collection, err := app.FindCollectionByNameOrId("projects")
+
+				file, err := readImport(w, r)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+
+				doc, err := interchange.ReadDocument(file)
 				if err != nil {
-					log.Fatal(err)
+					return apperr.Validation(err.Error())
+				}
+
+				types, schemas, err := loadTypes(app.DB(), project.Id)
+				if err != nil {
+					return apperr.Internal(err)
+				}
+				if opts.Replace {
+					types, schemas = nil, map[string][]graph.Attribute{}
 				}
 
-				record, err := app.FindRecordById(collection, project_id)
+				g, err := doc.Graph(types)
 				if err != nil {
-					log.Fatal(err)
+					return apperr.Validation(err.Error())
 				}
 
-				fmt.Printf("%v", record)
-			})
+				report, err := ImportGraph(app, live, r, project, g, schemas, []interchange.RowError{}, opts)
+				if err != nil {
+					return err
+				}
+				return writeJSON(w, report)
+			}))
+			// A multipart form with the CSV file in file and the
+			// interchange.CSVMapping, as JSON, in mapping.
+			r.With(dashboard.WithProjectPermission(app, graph.P_EDIT_NODES|graph.P_EDIT_CONNECTION)).Post("/{id}/import/csv", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				opts, err := importOptions(r.URL.Query())
+				if err != nil {
+					return err
+				}
+				if opts.Replace {
+					// The mapping names types of the project, which replacing
+					// would delete.
+					return apperr.Validation("CSV files can only be merged into a project")
+				}
+
+				file, err := readUpload(w, r)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+
+				mapping := interchange.CSVMapping{}
+				if err := json.Unmarshal([]byte(r.FormValue("mapping")), &mapping); err != nil {
+					return apperr.Validation("The mapping must be a JSON document")
+				}
+
+				types, schemas, err := loadTypes(app.DB(), project.Id)
+				if err != nil {
+					return apperr.Internal(err)
+				}
+
+				// Rows are merged into the nodes the project already has.
+				existing := []graph.Node{}
+				if err := app.DB().
+					Select("id", "name", "type").
+					From("nodes").
+					Where(alive("nodes", dbx.HashExp{"project": project.Id})).
+					All(&existing); err != nil {
+					return apperr.Internal(err)
+				}
+
+				g, row_errors, err := interchange.ReadCSV(file, mapping, types, existing)
+				if err != nil {
+					return apperr.Validation(err.Error())
+				}
+
+				report, err := ImportGraph(app, live, r, project, g, schemas, row_errors, opts)
+				if err != nil {
+					return err
+				}
+				return writeJSON(w, report)
+			}))
+			// Imports a graphml or gexf file, posted as the body or uploaded
+			// in file. The attributes named by ?node_class and ?edge_class,
+			// type by default, pick the types of nodes and edges: types of
+			// the project by name, or new ones drawn like the file draws
+			// their first node or edge.
+			r.With(dashboard.WithProjectPermission(app, graph.P_EDIT_NODES|graph.P_EDIT_CONNECTION)).Post("/{id}/import/{format}", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				read, ok := GRAPH_READERS[chi.URLParam(r, "format")]
+				if !ok {
+					return apperr.NotFound("Unknown import format")
+				}
+
+				opts, err := importOptions(r.URL.Query())
+				if err != nil {
+					return err
+				}
+				classes := interchange.DEFAULT_CLASSES
+				if class := r.URL.Query().Get("node_class"); class != "" {
+					classes.Node = class
+				}
+				if class := r.URL.Query().Get("edge_class"); class != "" {
+					classes.Edge = class
+				}
+
+				file, err := readImport(w, r)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+
+				types, schemas, err := loadTypes(app.DB(), project.Id)
+				if err != nil {
+					return apperr.Internal(err)
+				}
+				if opts.Replace {
+					types, schemas = interchange.NewTypes(nil, nil), map[string][]graph.Attribute{}
+				}
+
+				g, row_errors, err := read(file, types, classes)
+				if err != nil {
+					return apperr.Validation(err.Error())
+				}
+
+				report, err := ImportGraph(app, live, r, project, g, schemas, row_errors, opts)
+				if err != nil {
+					return err
+				}
+				return writeJSON(w, report)
+			}))
+			r.With(dashboard.WithProjectPermission(app, graph.P_MANAGE_PROJECT)).Post("/{id}/node-types", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				node_type := graph.NodeType{}
+				if err := readJSON(r, &node_type); err != nil {
+					return err
+				}
+
+				result, err := Commit(app, live, r, project.Id, Changeset{
+					CreateNodeTypes: []graph.NodeType{node_type},
+				})
+				if err != nil {
+					return err
+				}
+
+				return writeJSON(w, result.NodeTypes[0])
+			}))
+			r.With(dashboard.WithProjectPermission(app, graph.P_MANAGE_PROJECT)).Put("/{id}/node-types/{type_id}", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				node_type := graph.NodeType{}
+				if err := readJSON(r, &node_type); err != nil {
+					return err
+				}
+				node_type.Id = chi.URLParam(r, "type_id")
+
+				if _, err := Commit(app, live, r, project.Id, Changeset{
+					UpdateNodeTypes: []graph.NodeType{node_type},
+				}); err != nil {
+					return err
+				}
+
+				stored := []graph.NodeType{}
+				if err := selectByIds(app.DB(), "node_types", project.Id, []string{node_type.Id}, &stored); err != nil || len(stored) == 0 {
+					return apperr.Internal(err)
+				}
+				return writeJSON(w, stored[0])
+			}))
+			// Deleting a type that is in use needs ?reassign= naming the type its
+			// nodes move to.
+			r.With(dashboard.WithProjectPermission(app, graph.P_MANAGE_PROJECT)).Delete("/{id}/node-types/{type_id}", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				type_id := chi.URLParam(r, "type_id")
+				changeset := Changeset{DeleteNodeTypes: []string{type_id}}
+				if reassign := r.URL.Query().Get("reassign"); reassign != "" {
+					changeset.ReassignNodeTypes = map[string]string{type_id: reassign}
+				}
+
+				result, err := Commit(app, live, r, project.Id, changeset)
+				if err != nil {
+					return err
+				}
+
+				return writeJSON(w, MutationReport{
+					Message:   fmt.Sprintf("Deleted node type, moved %d nodes", len(result.Reassigned)),
+					Rejected:  result.Rejected,
+					Revisions: result.Revisions,
+				})
+			}))
+			r.With(dashboard.WithProjectPermission(app, graph.P_MANAGE_PROJECT)).Post("/{id}/edge-types", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				edge_type := graph.EdgeType{}
+				if err := readJSON(r, &edge_type); err != nil {
+					return err
+				}
+
+				result, err := Commit(app, live, r, project.Id, Changeset{
+					CreateEdgeTypes: []graph.EdgeType{edge_type},
+				})
+				if err != nil {
+					return err
+				}
+
+				return writeJSON(w, result.EdgeTypes[0])
+			}))
+			r.With(dashboard.WithProjectPermission(app, graph.P_MANAGE_PROJECT)).Put("/{id}/edge-types/{type_id}", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				edge_type := graph.EdgeType{}
+				if err := readJSON(r, &edge_type); err != nil {
+					return err
+				}
+				edge_type.Id = chi.URLParam(r, "type_id")
+
+				if _, err := Commit(app, live, r, project.Id, Changeset{
+					UpdateEdgeTypes: []graph.EdgeType{edge_type},
+				}); err != nil {
+					return err
+				}
+
+				stored := []graph.EdgeType{}
+				if err := selectByIds(app.DB(), "edge_types", project.Id, []string{edge_type.Id}, &stored); err != nil || len(stored) == 0 {
+					return apperr.Internal(err)
+				}
+				return writeJSON(w, stored[0])
+			}))
+			// Deleting a type that is in use needs ?reassign= naming the type its
+			// edges move to.
+			r.With(dashboard.WithProjectPermission(app, graph.P_MANAGE_PROJECT)).Delete("/{id}/edge-types/{type_id}", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				type_id := chi.URLParam(r, "type_id")
+				changeset := Changeset{DeleteEdgeTypes: []string{type_id}}
+				if reassign := r.URL.Query().Get("reassign"); reassign != "" {
+					changeset.ReassignEdgeTypes = map[string]string{type_id: reassign}
+				}
+
+				result, err := Commit(app, live, r, project.Id, changeset)
+				if err != nil {
+					return err
+				}
+
+				return writeJSON(w, MutationReport{
+					Message:   fmt.Sprintf("Deleted edge type, moved %d edges", len(result.Reassigned)),
+					Rejected:  result.Rejected,
+					Revisions: result.Revisions,
+				})
+			}))
+			r.With(dashboard.WithProjectPermission(app, 0)).Post("/{id}/cursor", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				user, err := auth.GetSignedInUser(app, r)
+				if err != nil {
+					return apperr.Unauthorized("You need to sign in to share your cursor")
+				}
+
+				viewer, ok := live.Viewer(project.Id, r.Header.Get(H_CLIENT_ID))
+				if !ok {
+					return apperr.NotFound("Live session not found")
+				}
+				// The client id is sent by the client, it must belong to a live
+				// session of the same user.
+				if viewer.UserId != user.Id {
+					return apperr.Forbidden("The live session belongs to someone else")
+				}
+
+				cursor := hub.Cursor{}
+				if err := readJSON(r, &cursor); err != nil {
+					return err
+				}
+				cursor.Viewer = viewer
+
+				live.Publish(project.Id, hub.Event{
+					Type:   hub.E_CURSOR,
+					Origin: viewer.ClientId,
+					Data:   cursor,
+				})
+				w.WriteHeader(http.StatusNoContent)
+				return nil
+			}))
+			// Undo and redo check the permissions of the changeset they step
+			// through themselves.
+			r.With(dashboard.WithProjectPermission(app, 0)).Post("/{id}/undo", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				user, err := auth.GetSignedInUser(app, r)
+				if err != nil {
+					return apperr.Unauthorized("You need to sign in to undo changes")
+				}
+
+				changes, err := Undo(app, project, user.Id)
+				if err != nil {
+					return err
+				}
+
+				publishChanges(live, r, project.Id, changes)
+				return writeJSON(w, changes)
+			}))
+			r.With(dashboard.WithProjectPermission(app, 0)).Post("/{id}/redo", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				user, err := auth.GetSignedInUser(app, r)
+				if err != nil {
+					return apperr.Unauthorized("You need to sign in to redo changes")
+				}
+
+				changes, err := Redo(app, project, user.Id)
+				if err != nil {
+					return err
+				}
+
+				publishChanges(live, r, project.Id, changes)
+				return writeJSON(w, changes)
+			}))
+			r.With(dashboard.WithProjectPermission(app, graph.P_MANAGE_PROJECT)).Post("/{id}/upload-snapshot", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				user, err := auth.GetSignedInUser(app, r)
+				if err != nil {
+					return apperr.Unauthorized("You need to sign in to save a snapshot")
+				}
+
+				// The name is optional, an empty body saves an unnamed snapshot.
+				body := struct {
+					Name string `json:"name"`
+				}{}
+				if r.ContentLength != 0 {
+					if err := readJSON(r, &body); err != nil {
+						return err
+					}
+				}
+
+				snapshot, err := CaptureSnapshot(app, project, user.Id, body.Name)
+				if err != nil {
+					return err
+				}
+
+				return writeJSON(w, snapshot)
+			}))
+			r.With(dashboard.WithProjectPermission(app, graph.P_MANAGE_PROJECT)).Post("/{id}/snapshots/{snapshot_id}/restore", apperr.JSON(func(w http.ResponseWriter, r *http.Request) error {
+				project := dashboard.ProjectFromContext(r)
+
+				user, err := auth.GetSignedInUser(app, r)
+				if err != nil {
+					return apperr.Unauthorized("You need to sign in to restore a snapshot")
+				}
+
+				backup, err := RestoreSnapshot(app, project, chi.URLParam(r, "snapshot_id"), user.Id)
+				if err != nil {
+					return err
+				}
+
+				// Every record may have changed, editors load the project again.
+				live.Publish(project.Id, hub.Event{Type: hub.E_RESYNC})
+				return writeJSON(w, backup)
+			}))
 		})
 	})
 }
+
+// positionPatches turns nodes into patches that only move them.
+func positionPatches(nodes []graph.Node) []NodePatch {
+	patches := make([]NodePatch, 0, len(nodes))
+	for _, node := range nodes {
+		patches = append(patches, NodePatch{
+			Id:       node.Id,
+			Revision: node.Revision,
+			X:        &node.X,
+			Y:        &node.Y,
+		})
+	}
+	return patches
+}
+
+// edgePatches turns edges into patches that rewire them, skipping fields the
+// client left empty.
+func edgePatches(edges []graph.Edge) []EdgePatch {
+	patches := make([]EdgePatch, 0, len(edges))
+	for _, edge := range edges {
+		patch := EdgePatch{Id: edge.Id, Revision: edge.Revision}
+		if edge.StartId != "" {
+			patch.StartId = &edge.StartId
+		}
+		if edge.EndId != "" {
+			patch.EndId = &edge.EndId
+		}
+		if edge.Type != "" {
+			patch.Type = &edge.Type
+		}
+		if edge.Name != "" {
+			patch.Name = &edge.Name
+		}
+		if len(edge.Metadata) > 0 {
+			patch.Metadata = edge.Metadata
+		}
+		patches = append(patches, patch)
+	}
+	return patches
+}
+
+func readJSON(r *http.Request, v any) error {
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		return apperr.Validation("Unable to read request body")
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return &apperr.Error{
+			Kind:    apperr.K_VALIDATION,
+			Message: "Malformed request body",
+			Err:     err,
+		}
+	}
+	return nil
+}
+
+// GRAPH_READERS maps the graph formats a project can be imported from to
+// their readers.
+var GRAPH_READERS = map[string]func(io.Reader, *interchange.Types, interchange.Classes) (*interchange.Graph, []interchange.RowError, error){
+	"graphml": interchange.ReadGraphML,
+	"gexf":    interchange.ReadGEXF,
+}
+
+// EXPORT_TYPES maps the formats a project can be exported as to their
+// content types.
+var EXPORT_TYPES = map[string]string{
+	"json":    "application/json",
+	"graphml": "application/graphml+xml",
+	"gexf":    "application/gexf+xml",
+	"dot":     "text/vnd.graphviz",
+	"svg":     "image/svg+xml",
+	"png":     "image/png",
+}
+
+// attachment makes the browser download a response as a file named after
+// the project.
+func attachment(w http.ResponseWriter, project *graph.Project, extension string) {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, project.Name)
+	if name == "" {
+		name = project.Id
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+extension))
+}
+
+func writeJSON(w http.ResponseWriter, v any) error {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return apperr.Internal(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(bytes); err != nil {
+		log.Printf("Unable to write response: %v", err)
+	}
+	return nil
+}
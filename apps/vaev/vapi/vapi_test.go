@@ -0,0 +1,326 @@
+package vapi
+
+import (
+	"encoding/json"
+	"koppla/apps/vaev/apperr"
+	"koppla/apps/vaev/hub"
+	"koppla/apps/vaev/views/auth"
+	"koppla/apps/vaev/views/graph"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// testTables are the tables of the graph, created by hand as the migrations
+// cannot run in tests.
+var testTables = []string{
+	`CREATE TABLE project_members (
+		id TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))),
+		project TEXT DEFAULT '', user TEXT DEFAULT '', permissions INTEGER DEFAULT 0,
+		created TEXT DEFAULT '', updated TEXT DEFAULT ''
+	)`,
+	`CREATE TABLE node_types (
+		id TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))),
+		name TEXT DEFAULT '', project TEXT DEFAULT '', fill_color TEXT DEFAULT '',
+		stroke_color TEXT DEFAULT '', stroke_width INTEGER DEFAULT 1, shape INTEGER DEFAULT 0,
+		metadata JSON DEFAULT '{}', schema JSON DEFAULT '[]'
+	)`,
+	`CREATE TABLE edge_types (
+		id TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))),
+		name TEXT DEFAULT '', project TEXT DEFAULT '', stroke_color TEXT DEFAULT '',
+		stroke_width INTEGER DEFAULT 1, line_dash JSON DEFAULT '[]',
+		metadata JSON DEFAULT '{}', schema JSON DEFAULT '[]'
+	)`,
+	`CREATE TABLE nodes (
+		id TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))),
+		name TEXT DEFAULT '', project TEXT DEFAULT '', type TEXT DEFAULT '',
+		x INTEGER DEFAULT 0, y INTEGER DEFAULT 0, revision INTEGER DEFAULT 1,
+		metadata JSON DEFAULT '{}', deleted_at TEXT DEFAULT '',
+		created TEXT DEFAULT '', updated TEXT DEFAULT ''
+	)`,
+	`CREATE TABLE edges (
+		id TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))),
+		name TEXT DEFAULT '', project TEXT DEFAULT '', type TEXT DEFAULT '',
+		start_id TEXT DEFAULT '', end_id TEXT DEFAULT '', revision INTEGER DEFAULT 1,
+		metadata JSON DEFAULT '{}', deleted_at TEXT DEFAULT '',
+		created TEXT DEFAULT '', updated TEXT DEFAULT ''
+	)`,
+	`CREATE TABLE audit_events (
+		id TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))),
+		project TEXT DEFAULT '', user TEXT DEFAULT '', operation TEXT DEFAULT '',
+		entity TEXT DEFAULT '', entity_id TEXT DEFAULT '', before JSON, after JSON,
+		created TEXT DEFAULT '', updated TEXT DEFAULT ''
+	)`,
+	`CREATE TABLE journal_entries (
+		id TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))),
+		project TEXT DEFAULT '', user TEXT DEFAULT '', changes JSON, undone BOOLEAN DEFAULT FALSE,
+		created TEXT DEFAULT '', updated TEXT DEFAULT ''
+	)`,
+}
+
+// tamperFixture is two projects of different owners. The owner of A holds a
+// token for A and tries to reach into B with it.
+type tamperFixture struct {
+	app     *pocketbase.PocketBase
+	mux     *chi.Mux
+	token   string
+	a       string
+	b       string
+	a_graph *ChangesetResult
+	b_graph *ChangesetResult
+	// b_trash holds a node and an edge of B that are in the trash.
+	b_trash *ChangesetResult
+}
+
+func newTamperFixture(t *testing.T) *tamperFixture {
+	t.Helper()
+
+	app := pocketbase.NewWithConfig(pocketbase.Config{DefaultDataDir: t.TempDir()})
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { app.ResetBootstrapState() })
+
+	projects := core.NewBaseCollection("projects", "pbc_484305853")
+	projects.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.TextField{Name: "owner"},
+		&core.TextField{Name: "visibility"},
+		&core.TextField{Name: "deleted_at"},
+		&core.FileField{Name: "thumbnail", MaxSelect: 1},
+		&core.AutodateField{Name: "created", OnCreate: true},
+		&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+	)
+	if err := app.Save(projects); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := core.NewBaseCollection("access_tokens")
+	tokens.Fields.Add(
+		&core.TextField{Name: "user"},
+		&core.TextField{Name: "name"},
+		&core.TextField{Name: "token_hash"},
+		&core.JSONField{Name: "projects"},
+		&core.BoolField{Name: "write"},
+		&core.TextField{Name: "last_used"},
+		&core.AutodateField{Name: "created", OnCreate: true},
+		&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+	)
+	if err := app.Save(tokens); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, query := range testTables {
+		if _, err := app.DB().NewQuery(query).Execute(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newUser := func(email string) *core.Record {
+		user := core.NewRecord(users)
+		user.SetEmail(email)
+		user.SetPassword("password123")
+		if err := app.Save(user); err != nil {
+			t.Fatal(err)
+		}
+		return user
+	}
+	newProject := func(owner *core.Record) string {
+		project := core.NewRecord(projects)
+		project.Set("name", "Project of "+owner.Email())
+		project.Set("owner", owner.Id)
+		project.Set("visibility", "private")
+		if err := app.Save(project); err != nil {
+			t.Fatal(err)
+		}
+		return project.Id
+	}
+
+	attacker, victim := newUser("attacker@example.com"), newUser("victim@example.com")
+	f := &tamperFixture{app: app, mux: chi.NewMux()}
+	f.a, f.b = newProject(attacker), newProject(victim)
+
+	f.token, err = auth.CreateAccessToken(app, attacker.Id, "tamper", []string{f.a}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	graph_of := func(project_id string) *ChangesetResult {
+		result, err := ApplyChangeset(app, project_id, Changeset{
+			CreateNodeTypes: []graph.NodeType{{Id: "nt", Name: "Node", FillColor: "#4a90d9", StrokeColor: "#000000"}},
+			CreateEdgeTypes: []graph.EdgeType{{Id: "et", Name: "Edge", StrokeColor: "#000000"}},
+			CreateNodes: []graph.Node{
+				{Id: "n1", Name: "one", Type: "nt"},
+				{Id: "n2", Name: "two", Type: "nt"},
+			},
+			CreateEdges: []graph.Edge{{Id: "e1", StartId: "n1", EndId: "n2", Type: "et"}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	f.a_graph = graph_of(f.a)
+	f.b_graph = graph_of(f.b)
+	f.b_trash = graph_of(f.b)
+	if _, err := ApplyChangeset(app, f.b, Changeset{
+		DeleteNodes: []string{f.b_trash.Ids["n1"]},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	RegisterVAPI(app, f.mux, hub.New())
+	return f
+}
+
+// do sends a request to project A with the token of its owner.
+func (f *tamperFixture) do(t *testing.T, method string, route string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(method, "/v-api/project/"+f.a+route, strings.NewReader(string(data)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+f.token)
+	w := httptest.NewRecorder()
+	f.mux.ServeHTTP(w, req)
+	return w
+}
+
+// dump reads every record of project B, to tell whether anything changed.
+func (f *tamperFixture) dump(t *testing.T) string {
+	t.Helper()
+
+	out := strings.Builder{}
+	for _, table := range []string{"node_types", "edge_types", "nodes", "edges"} {
+		rows := []dbx.NullStringMap{}
+		if err := f.app.DB().
+			Select("*").
+			From(table).
+			Where(dbx.HashExp{"project": f.b}).
+			OrderBy("id").
+			All(&rows); err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out.WriteString(table + ": " + string(data) + "\n")
+	}
+	return out.String()
+}
+
+// expectRejected checks that a request either listed every id as rejected
+// or failed validation as a whole.
+func expectRejected(t *testing.T, w *httptest.ResponseRecorder, ids ...string) {
+	t.Helper()
+
+	if w.Code == apperr.K_VALIDATION.Status() {
+		return
+	}
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", w.Code, w.Body.String())
+	}
+	report := MutationReport{}
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("unable to read %s: %v", w.Body.String(), err)
+	}
+	for _, id := range ids {
+		if !slices.Contains(report.Rejected, id) {
+			t.Errorf("%s was not rejected: %s", id, w.Body.String())
+		}
+	}
+}
+
+func TestCrossProjectTampering(t *testing.T) {
+	f := newTamperFixture(t)
+	before := f.dump(t)
+
+	a := f.a_graph.Ids
+	b := f.b_graph.Ids
+	b_trash := f.b_trash.Ids
+	x, y := 500, 500
+	name := "taken"
+	b_nt, b_n1 := b["nt"], b["n1"]
+
+	cases := []struct {
+		name   string
+		method string
+		route  string
+		body   any
+		ids    []string
+	}{
+		{"update-nodes", http.MethodPut, "/update-nodes",
+			[]graph.Node{{Id: b["n1"], X: x, Y: y, Revision: 1}}, []string{b["n1"]}},
+		{"delete-nodes", http.MethodDelete, "/delete-nodes",
+			[]string{b["n1"], b["n2"]}, []string{b["n1"], b["n2"]}},
+		{"update-edges", http.MethodPut, "/update-edges",
+			[]graph.Edge{{Id: b["e1"], StartId: b["n2"], EndId: b["n1"], Revision: 1}}, []string{b["e1"]}},
+		{"delete-edges", http.MethodDelete, "/delete-edges",
+			[]string{b["e1"]}, []string{b["e1"]}},
+		{"restore-nodes", http.MethodPost, "/restore-nodes",
+			[]string{b_trash["n1"]}, []string{b_trash["n1"]}},
+		{"restore-edges", http.MethodPost, "/restore-edges",
+			[]string{b_trash["e1"]}, []string{b_trash["e1"]}},
+		{"changeset update_nodes", http.MethodPost, "/changeset",
+			Changeset{UpdateNodes: []NodePatch{{Id: b["n1"], Revision: 1, X: &x, Y: &y, Name: &name}}}, nil},
+		{"changeset update_nodes skip_rejected", http.MethodPost, "/changeset",
+			Changeset{UpdateNodes: []NodePatch{{Id: b["n1"], Revision: 1, X: &x}}, SkipRejected: true}, []string{b["n1"]}},
+		{"changeset delete_nodes", http.MethodPost, "/changeset",
+			Changeset{DeleteNodes: []string{b["n1"]}}, nil},
+		{"changeset update_edges", http.MethodPost, "/changeset",
+			Changeset{UpdateEdges: []EdgePatch{{Id: b["e1"], Revision: 1, Name: &name}}}, nil},
+		{"changeset delete_edges", http.MethodPost, "/changeset",
+			Changeset{DeleteEdges: []string{b["e1"]}}, nil},
+		{"changeset restore_nodes", http.MethodPost, "/changeset",
+			Changeset{RestoreNodes: []string{b_trash["n1"]}}, nil},
+		{"changeset restore_edges", http.MethodPost, "/changeset",
+			Changeset{RestoreEdges: []string{b_trash["e1"]}}, nil},
+		{"changeset node type of B", http.MethodPost, "/changeset",
+			Changeset{CreateNodes: []graph.Node{{Id: "n", Type: b["nt"]}}}, nil},
+		{"changeset edge to nodes of B", http.MethodPost, "/changeset",
+			Changeset{CreateEdges: []graph.Edge{{Id: "e", StartId: b["n1"], EndId: b["n2"]}}}, nil},
+		{"changeset node moved to type of B", http.MethodPost, "/changeset",
+			Changeset{UpdateNodes: []NodePatch{{Id: a["n1"], Revision: 1, Type: &b_nt}}}, nil},
+		{"changeset edge moved to nodes of B", http.MethodPost, "/changeset",
+			Changeset{UpdateEdges: []EdgePatch{{Id: a["e1"], Revision: 1, StartId: &b_n1}}}, nil},
+		{"changeset reassign to type of B", http.MethodPost, "/changeset",
+			Changeset{DeleteNodeTypes: []string{a["nt"]}, ReassignNodeTypes: map[string]string{a["nt"]: b["nt"]}}, nil},
+		{"changeset update_node_types", http.MethodPost, "/changeset",
+			Changeset{UpdateNodeTypes: []graph.NodeType{{Id: b["nt"], Name: name}}}, nil},
+		{"changeset delete_node_types", http.MethodPost, "/changeset",
+			Changeset{DeleteNodeTypes: []string{b["nt"]}}, nil},
+		{"changeset update_edge_types", http.MethodPost, "/changeset",
+			Changeset{UpdateEdgeTypes: []graph.EdgeType{{Id: b["et"], Name: name}}}, nil},
+		{"changeset delete_edge_types", http.MethodPost, "/changeset",
+			Changeset{DeleteEdgeTypes: []string{b["et"]}}, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := f.do(t, c.method, c.route, c.body)
+			if c.ids == nil && w.Code != apperr.K_VALIDATION.Status() {
+				t.Fatalf("got status %d, want %d: %s", w.Code, apperr.K_VALIDATION.Status(), w.Body.String())
+			}
+			expectRejected(t, w, c.ids...)
+
+			if after := f.dump(t); after != before {
+				t.Fatalf("project B changed:\nbefore %s\nafter  %s", before, after)
+			}
+		})
+	}
+}
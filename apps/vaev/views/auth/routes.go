@@ -2,6 +2,7 @@ package auth
 
 import (
 	"fmt"
+	"koppla/apps/vaev/apperr"
 	"koppla/apps/vaev/constants"
 	"koppla/apps/vaev/middleware"
 	"koppla/apps/vaev/routing"
@@ -9,7 +10,6 @@ import (
 	"koppla/apps/vaev/views/toaster"
 	"log"
 	"net/http"
-	"net/url"
 	"time"
 
 	"github.com/a-h/templ"
@@ -37,6 +37,7 @@ func AuthRoutes(app *pocketbase.PocketBase, r *chi.Mux) {
 				GuestCard(),
 				datastar.WithSelectorID("user-card"),
 			)
+			return
 		}
 
 		sse.MergeFragmentTempl(
@@ -45,31 +46,39 @@ func AuthRoutes(app *pocketbase.PocketBase, r *chi.Mux) {
 		)
 	})
 
-	r.Get(R_LOGIN, func(w http.ResponseWriter, r *http.Request) {
-		next := r.URL.Query().Get("next")
-		next_parsed, err := url.QueryUnescape(next)
+	r.Get(R_LOGIN, apperr.Page(func(w http.ResponseWriter, r *http.Request) error {
+		next, err := routing.NextPath(r)
 		if err != nil {
-			log.Fatalf("Unable to parse next: %s", next)
+			return apperr.Validation(err.Error())
 		}
 		if cookie, err := r.Cookie(constants.COOKIE_AUTH); err == nil {
 			_, err := app.FindAuthRecordByToken(cookie.Value)
 			if err == nil {
-				routing.BounceBack(w, r)
-				return
+				if err := routing.BounceBack(w, r); err != nil {
+					return apperr.Validation(err.Error())
+				}
+				return nil
 			}
 
 			routing.DestroyCookie(w, constants.COOKIE_AUTH)
 		}
 
 		templ.Handler(layout.Doc(func() templ.Component {
-			return Login(next_parsed)
+			return Login(next)
 		})).ServeHTTP(w, r)
-	})
+		return nil
+	}))
+
+	r.Post(R_VALIDATE, apperr.SSE(func(w http.ResponseWriter, r *http.Request) error {
+		// The destination is checked before signing in, so that a bad one does
+		// not leave the user signed in on an error.
+		if _, err := routing.NextPath(r); err != nil {
+			return apperr.Validation(err.Error())
+		}
 
-	r.Post(R_VALIDATE, func(w http.ResponseWriter, r *http.Request) {
 		users, err := app.FindCollectionByNameOrId("users")
 		if err != nil {
-			log.Fatal(err)
+			return apperr.Internal(err)
 		}
 
 		data := &struct {
@@ -84,41 +93,32 @@ func AuthRoutes(app *pocketbase.PocketBase, r *chi.Mux) {
 		data.Password = r.FormValue("password")
 
 		auth, err := app.FindAuthRecordByEmail(users, data.Username)
-		if err != nil {
-			sse := datastar.NewSSE(w, r)
-			toaster.SendErrorMessage(sse, "Invalid credentials")
-			sse.ExecuteScript(`document.getElementById("login-form").reset();`)
+		if err != nil || !auth.ValidatePassword(data.Password) {
+			rejectCredentials(w, r)
+			return nil
 		}
 
-		valid_pass := auth.ValidatePassword(data.Password)
-
-		if valid_pass {
-			token, err := auth.NewAuthToken()
-			if err != nil {
-				sse := datastar.NewSSE(w, r)
-				toaster.SendErrorMessage(sse, "Invalid credentials")
-				sse.ExecuteScript(`document.getElementById("login-form").reset();`)
-			}
-
-			http.SetCookie(w, &http.Cookie{
-				Name:     constants.COOKIE_AUTH,
-				Value:    token,
-				Expires:  time.Now().Add(time.Hour * (24 * 365)),
-				Secure:   true,
-				HttpOnly: true,
-				SameSite: http.SameSiteStrictMode,
-				Path:     "/",
-			})
-
-			log.Println("Bouncing back")
-			routing.BounceBackSSE(w, r)
-			return
+		token, err := auth.NewAuthToken()
+		if err != nil {
+			return apperr.Internal(err)
 		}
 
-		sse := datastar.NewSSE(w, r)
-		toaster.SendErrorMessage(sse, "Invalid credentials")
-		sse.ExecuteScript(`document.getElementById("login-form").reset();`)
-	})
+		http.SetCookie(w, &http.Cookie{
+			Name:     constants.COOKIE_AUTH,
+			Value:    token,
+			Expires:  time.Now().Add(time.Hour * (24 * 365)),
+			Secure:   true,
+			HttpOnly: true,
+			SameSite: http.SameSiteStrictMode,
+			Path:     "/",
+		})
+
+		log.Println("Bouncing back")
+		if err := routing.BounceBackSSE(w, r); err != nil {
+			return apperr.Validation(err.Error())
+		}
+		return nil
+	}))
 
 	r.Post(R_INVALIDATE, func(w http.ResponseWriter, r *http.Request) {
 		routing.DestroyCookie(w, constants.COOKIE_AUTH)
@@ -126,6 +126,14 @@ func AuthRoutes(app *pocketbase.PocketBase, r *chi.Mux) {
 	})
 }
 
+func rejectCredentials(w http.ResponseWriter, r *http.Request) {
+	sse := datastar.NewSSE(w, r)
+	toaster.SendErrorMessage(sse, "Invalid credentials")
+	sse.ExecuteScript(`document.getElementById("login-form").reset();`)
+}
+
+// WithAuthJSONGuard lets requests through that are signed in, with the auth
+// cookie or with a personal access token as "Authorization: Bearer".
 func WithAuthJSONGuard(app *pocketbase.PocketBase) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
@@ -137,7 +145,7 @@ func WithAuthJSONGuard(app *pocketbase.PocketBase) func(next http.Handler) http.
 			}
 			next.ServeHTTP(w, r)
 		}
-		return http.HandlerFunc(fn)
+		return WithAccessToken(app)(http.HandlerFunc(fn))
 	}
 }
 
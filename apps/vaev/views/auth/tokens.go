@@ -0,0 +1,276 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"koppla/apps/vaev/apperr"
+	"koppla/apps/vaev/constants"
+	"koppla/apps/vaev/views/graph"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+)
+
+const (
+	// TOKEN_PREFIX starts every personal access token, so that leaked tokens
+	// are easy to search for.
+	TOKEN_PREFIX = "vaev_"
+	// MAX_ACCESS_TOKENS is how many tokens a user may have at once.
+	MAX_ACCESS_TOKENS = 50
+)
+
+// AccessToken lets scripts use /v-api as the user who created it, limited to
+// some of their projects. Only a hash of the token itself is stored.
+type AccessToken struct {
+	Id       string   `db:"id" json:"id"`
+	User     string   `db:"user" json:"user"`
+	Name     string   `db:"name" json:"name"`
+	Write    bool     `db:"write" json:"write"`
+	Projects []string `db:"-" json:"projects"`
+	LastUsed string   `db:"last_used" json:"last_used"`
+	Created  string   `db:"created" json:"created"`
+}
+
+// Allows reports whether the token may be used on the project.
+func (t *AccessToken) Allows(project_id string) bool {
+	return slices.Contains(t.Projects, project_id)
+}
+
+// Permissions limits what the user may do with a project to what the token
+// allows: nothing but reading for read only tokens.
+func (t *AccessToken) Permissions(permissions graph.Permission) graph.Permission {
+	if t == nil || t.Write {
+		return permissions
+	}
+	return 0
+}
+
+// accessTokenRow is an access token as stored, its projects a JSON list.
+type accessTokenRow struct {
+	AccessToken
+	Projects string `db:"projects"`
+}
+
+func (row accessTokenRow) token() AccessToken {
+	t := row.AccessToken
+	t.Projects = []string{}
+	if row.Projects != "" {
+		json.Unmarshal([]byte(row.Projects), &t.Projects)
+	}
+	return t
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// AccessTokenFromContext returns the token the request was signed in with, or
+// nil when it was signed in with the auth cookie.
+func AccessTokenFromContext(r *http.Request) *AccessToken {
+	token, _ := r.Context().Value(constants.CTX_TOKEN).(*AccessToken)
+	return token
+}
+
+// WithAccessToken signs in requests that carry a personal access token as
+// "Authorization: Bearer". Requests without one pass on unchanged, tokens
+// that are unknown are turned away, and so are writes with a read only
+// token.
+func WithAccessToken(app *pocketbase.PocketBase) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			row := accessTokenRow{}
+			err := app.DB().
+				Select("id", "user", "name", "write", "projects", "last_used", "created").
+				From("access_tokens").
+				Where(dbx.HashExp{"token_hash": hashToken(strings.TrimSpace(raw))}).
+				One(&row)
+			if errors.Is(err, sql.ErrNoRows) {
+				apperr.WriteJSON(w, r, apperr.Unauthorized("The access token is not valid"))
+				return
+			}
+			if err != nil {
+				apperr.WriteJSON(w, r, apperr.Internal(err))
+				return
+			}
+			token := row.token()
+
+			if !token.Write &&
+				r.Method != http.MethodGet &&
+				r.Method != http.MethodHead &&
+				r.Method != http.MethodOptions {
+				apperr.WriteJSON(w, r, apperr.Forbidden("The access token can only read"))
+				return
+			}
+
+			user, err := app.FindRecordById("users", token.User)
+			if err != nil {
+				apperr.WriteJSON(w, r, apperr.Unauthorized("The access token is not valid"))
+				return
+			}
+
+			now := time.Now().UTC().Format("2006-01-02 15:04:05.000Z")
+			if _, err := app.DB().
+				Update("access_tokens", dbx.Params{"last_used": now}, dbx.HashExp{"id": token.Id}).
+				Execute(); err != nil {
+				log.Printf("Unable to record the use of access token %s: %v", token.Id, err)
+			}
+
+			new_ctx := context.WithValue(r.Context(), constants.CTX_AUTH, user)
+			new_ctx = context.WithValue(new_ctx, constants.CTX_TOKEN, &token)
+			next.ServeHTTP(w, r.WithContext(new_ctx))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// ListAccessTokens returns the tokens of the user, newest first.
+func ListAccessTokens(app *pocketbase.PocketBase, user_id string) ([]AccessToken, error) {
+	rows := []accessTokenRow{}
+	if err := app.DB().
+		Select("id", "user", "name", "write", "projects", "last_used", "created").
+		From("access_tokens").
+		Where(dbx.HashExp{"user": user_id}).
+		OrderBy("created DESC").
+		All(&rows); err != nil {
+		return nil, err
+	}
+
+	tokens := make([]AccessToken, 0, len(rows))
+	for _, row := range rows {
+		tokens = append(tokens, row.token())
+	}
+	return tokens, nil
+}
+
+// TokenProjects returns the projects a token of the user can be scoped to,
+// the ones they own or are a member of.
+func TokenProjects(app *pocketbase.PocketBase, user_id string) ([]graph.Project, error) {
+	projects := []graph.Project{}
+
+	query := `
+	SELECT p.id, p.name, p.owner
+	FROM projects p
+	LEFT JOIN project_members m ON m.project = p.id AND m.user = {:user}
+	WHERE (p.owner = {:user} OR m.id IS NOT NULL) AND p.deleted_at = ''
+	ORDER BY p.name
+	`
+	if err := app.DB().
+		NewQuery(query).
+		Bind(dbx.Params{"user": user_id}).
+		All(&projects); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// CreateAccessToken creates a token for the user that may be used on the
+// given projects, and for writes when write is set. The token is returned
+// once and cannot be read back later.
+func CreateAccessToken(app *pocketbase.PocketBase, user_id string, name string, project_ids []string, write bool) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", apperr.Validation("Give the token a name")
+	}
+	if len([]rune(name)) > 100 {
+		return "", apperr.Validation("The name can be at most 100 characters")
+	}
+	if len(project_ids) == 0 {
+		return "", apperr.Validation("Choose at least one project for the token")
+	}
+
+	projects, err := TokenProjects(app, user_id)
+	if err != nil {
+		return "", apperr.Internal(err)
+	}
+	allowed := map[string]bool{}
+	for _, p := range projects {
+		allowed[p.Id] = true
+	}
+	scope := []string{}
+	for _, id := range project_ids {
+		if !allowed[id] {
+			return "", apperr.Forbidden("You do not have access to one of the projects")
+		}
+		if !slices.Contains(scope, id) {
+			scope = append(scope, id)
+		}
+	}
+
+	var count int
+	if err := app.DB().
+		Select("COUNT(*)").
+		From("access_tokens").
+		Where(dbx.HashExp{"user": user_id}).
+		Row(&count); err != nil {
+		return "", apperr.Internal(err)
+	}
+	if count >= MAX_ACCESS_TOKENS {
+		return "", apperr.Validation(fmt.Sprintf("You can have at most %d access tokens, revoke one first", MAX_ACCESS_TOKENS))
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", apperr.Internal(err)
+	}
+	token := TOKEN_PREFIX + hex.EncodeToString(secret)
+
+	scope_json, err := json.Marshal(scope)
+	if err != nil {
+		return "", apperr.Internal(err)
+	}
+
+	query := `
+	INSERT INTO access_tokens (user, name, token_hash, projects, write, created, updated)
+	VALUES ({:user}, {:name}, {:hash}, {:projects}, {:write}, {:now}, {:now})
+	`
+	now := time.Now().UTC().Format("2006-01-02 15:04:05.000Z")
+	if _, err := app.DB().
+		NewQuery(query).
+		Bind(dbx.Params{
+			"user":     user_id,
+			"name":     name,
+			"hash":     hashToken(token),
+			"projects": string(scope_json),
+			"write":    write,
+			"now":      now,
+		}).
+		Execute(); err != nil {
+		return "", apperr.Internal(err)
+	}
+
+	return token, nil
+}
+
+// RevokeAccessToken deletes a token of the user, requests that use it are
+// turned away from then on.
+func RevokeAccessToken(app *pocketbase.PocketBase, user_id string, token_id string) error {
+	res, err := app.DB().
+		Delete("access_tokens", dbx.HashExp{"id": token_id, "user": user_id}).
+		Execute()
+	if err != nil {
+		return apperr.Internal(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return apperr.NotFound("Access token not found")
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package dashboard
+
+import (
+	"database/sql"
+	"errors"
+	"koppla/apps/vaev/apperr"
+	"koppla/apps/vaev/audit"
+	"koppla/apps/vaev/views/graph"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func ListMembers(app *pocketbase.PocketBase, project_id string) ([]graph.ProjectMember, error) {
+	members := []graph.ProjectMember{}
+
+	query := `
+	SELECT m.id, m.project, m.user, m.permissions, m.created, u.email
+	FROM project_members m
+	JOIN users u ON u.id = m.user
+	WHERE m.project = {:project}
+	ORDER BY u.email
+	`
+	if err := app.DB().
+		NewQuery(query).
+		Bind(dbx.Params{"project": project_id}).
+		All(&members); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// InviteMember gives the user with the given email access to the project.
+// Inviting someone who already is a member replaces their permissions. The
+// change is audited as made by actor.
+func InviteMember(app *pocketbase.PocketBase, project *graph.Project, actor string, email string, permissions graph.Permission) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return apperr.Validation("Enter the email of the person to invite")
+	}
+
+	var user_id string
+	err := app.DB().
+		NewQuery("SELECT id FROM users WHERE email = {:email} COLLATE NOCASE").
+		Bind(dbx.Params{"email": email}).
+		Row(&user_id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperr.Validation("There is no user with that email")
+	}
+	if err != nil {
+		return apperr.Internal(err)
+	}
+
+	if user_id == project.Owner {
+		return apperr.Validation("The owner already has access to the project")
+	}
+
+	query := `
+	INSERT INTO project_members (project, user, permissions, created, updated)
+	VALUES ({:project}, {:user}, {:permissions}, {:now}, {:now})
+	ON CONFLICT (project, user) DO UPDATE
+	SET permissions = excluded.permissions, updated = excluded.updated
+	RETURNING id
+	`
+	err = app.RunInTransaction(func(tx core.App) error {
+		before, err := findMember(tx.DB(), project.Id, dbx.HashExp{"m.user": user_id})
+		if err != nil {
+			return err
+		}
+
+		var member_id string
+		if err := tx.DB().
+			NewQuery(query).
+			Bind(dbx.Params{
+				"project":     project.Id,
+				"user":        user_id,
+				"permissions": int(permissions),
+				"now":         time.Now().UTC().Format("2006-01-02 15:04:05.000Z"),
+			}).
+			Row(&member_id); err != nil {
+			return err
+		}
+
+		after, err := findMember(tx.DB(), project.Id, dbx.HashExp{"m.id": member_id})
+		if err != nil {
+			return err
+		}
+
+		change := audit.Change{
+			Operation: audit.O_CREATE,
+			Entity:    audit.ENT_MEMBER,
+			EntityId:  member_id,
+			After:     after,
+		}
+		if before != nil {
+			change.Operation = audit.O_UPDATE
+			change.Before = before
+		}
+		return audit.Record(tx.DB(), project.Id, actor, change)
+	})
+	if err != nil {
+		return apperr.Internal(err)
+	}
+
+	return nil
+}
+
+// RemoveMember removes a member from the project and returns who it was.
+func RemoveMember(app *pocketbase.PocketBase, project_id string, actor string, member_id string) (*graph.ProjectMember, error) {
+	var removed *graph.ProjectMember
+	err := app.RunInTransaction(func(tx core.App) error {
+		before, err := findMember(tx.DB(), project_id, dbx.HashExp{"m.id": member_id})
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return apperr.NotFound("Member not found")
+		}
+
+		if _, err := tx.DB().
+			Delete("project_members", dbx.HashExp{"id": member_id, "project": project_id}).
+			Execute(); err != nil {
+			return err
+		}
+
+		removed = before
+		return audit.Record(tx.DB(), project_id, actor, audit.Change{
+			Operation: audit.O_DELETE,
+			Entity:    audit.ENT_MEMBER,
+			EntityId:  member_id,
+			Before:    before,
+		})
+	})
+
+	var app_err *apperr.Error
+	if errors.As(err, &app_err) {
+		return nil, app_err
+	}
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+	return removed, nil
+}
+
+// findMember returns the member of the project that matches where, or nil
+// if there is none.
+func findMember(db dbx.Builder, project_id string, where dbx.HashExp) (*graph.ProjectMember, error) {
+	member := &graph.ProjectMember{}
+	err := db.
+		Select("m.id", "m.project", "m.user", "m.permissions", "m.created", "u.email").
+		From("project_members m").
+		InnerJoin("users u", dbx.NewExp("u.id = m.user")).
+		Where(dbx.And(dbx.HashExp{"m.project": project_id}, where)).
+		One(member)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
@@ -0,0 +1,346 @@
+package dashboard
+
+import (
+	"database/sql"
+	"errors"
+	"koppla/apps/vaev/apperr"
+	"koppla/apps/vaev/audit"
+	"koppla/apps/vaev/views/graph"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// ListTrashedProjects returns the projects of the owner that are in the
+// trash, most recently deleted first.
+func ListTrashedProjects(app *pocketbase.PocketBase, owner_id string) ([]graph.Project, error) {
+	projects := []graph.Project{}
+	if err := app.DB().
+		Select("*").
+		From("projects").
+		Where(dbx.And(dbx.HashExp{"owner": owner_id}, dbx.NewExp("deleted_at != ''"))).
+		OrderBy("deleted_at DESC").
+		All(&projects); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// TrashProject moves the project to the trash, where it is kept until it is
+// restored or purged. Only the owner may do so.
+func TrashProject(app *pocketbase.PocketBase, project *graph.Project, actor string) error {
+	if project.Owner != actor {
+		return apperr.Forbidden("Only the owner can delete a project")
+	}
+
+	err := app.RunInTransaction(func(tx core.App) error {
+		if _, err := tx.DB().
+			Update(
+				"projects",
+				dbx.Params{"deleted_at": time.Now().UTC().Format("2006-01-02 15:04:05.000Z")},
+				dbx.HashExp{"id": project.Id},
+			).
+			Execute(); err != nil {
+			return err
+		}
+
+		return audit.Record(tx.DB(), project.Id, actor, audit.Change{
+			Operation: audit.O_DELETE,
+			Entity:    audit.ENT_PROJECT,
+			EntityId:  project.Id,
+			Before:    map[string]string{"name": project.Name},
+		})
+	})
+	if err != nil {
+		return apperr.Internal(err)
+	}
+
+	return nil
+}
+
+// RestoreProject takes a project of the owner out of the trash.
+func RestoreProject(app *pocketbase.PocketBase, project_id string, actor string) (*graph.Project, error) {
+	var project *graph.Project
+	err := app.RunInTransaction(func(tx core.App) error {
+		var err error
+		project, err = findTrashedProject(tx.DB(), project_id, actor)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.DB().
+			Update("projects", dbx.Params{"deleted_at": ""}, dbx.HashExp{"id": project_id}).
+			Execute(); err != nil {
+			return err
+		}
+		project.DeletedAt = ""
+
+		return audit.Record(tx.DB(), project_id, actor, audit.Change{
+			Operation: audit.O_RESTORE,
+			Entity:    audit.ENT_PROJECT,
+			EntityId:  project_id,
+			After:     map[string]string{"name": project.Name},
+		})
+	})
+
+	var app_err *apperr.Error
+	if errors.As(err, &app_err) {
+		return nil, app_err
+	}
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+
+	project.Permissions = graph.P_ALL
+	return project, nil
+}
+
+// DeleteProject deletes a project of the owner that is in the trash for good,
+// without waiting for the trash to be purged.
+func DeleteProject(app *pocketbase.PocketBase, project_id string, actor string) error {
+	if _, err := findTrashedProject(app.DB(), project_id, actor); err != nil {
+		var app_err *apperr.Error
+		if errors.As(err, &app_err) {
+			return app_err
+		}
+		return apperr.Internal(err)
+	}
+
+	if err := PurgeProject(app, project_id); err != nil {
+		return apperr.Internal(err)
+	}
+	return nil
+}
+
+// PurgeProject deletes a project together with everything that belongs to it.
+func PurgeProject(app core.App, project_id string) error {
+	return app.RunInTransaction(func(tx core.App) error {
+		for _, table := range []string{"edges", "nodes", "edge_types", "node_types"} {
+			if _, err := tx.DB().
+				Delete(table, dbx.HashExp{"project": project_id}).
+				Execute(); err != nil {
+				return err
+			}
+		}
+
+		record, err := tx.FindRecordById("projects", project_id)
+		if err != nil {
+			return err
+		}
+		// Members, snapshots, the audit log and the journal cascade, and the
+		// thumbnail is removed from storage.
+		return tx.Delete(record)
+	})
+}
+
+func findTrashedProject(db dbx.Builder, project_id string, owner_id string) (*graph.Project, error) {
+	project := &graph.Project{}
+	err := db.
+		Select("*").
+		From("projects").
+		Where(dbx.And(
+			dbx.HashExp{"id": project_id, "owner": owner_id},
+			dbx.NewExp("deleted_at != ''"),
+		)).
+		One(project)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.NotFound("Project not found in the trash")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// RenameProject gives the project a new name.
+func RenameProject(app *pocketbase.PocketBase, project *graph.Project, actor string, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return apperr.Validation("A project needs a name")
+	}
+	if name == project.Name {
+		return nil
+	}
+
+	now := time.Now().UTC().Format("2006-01-02 15:04:05.000Z")
+	err := app.RunInTransaction(func(tx core.App) error {
+		if _, err := tx.DB().
+			Update("projects", dbx.Params{"name": name, "updated": now}, dbx.HashExp{"id": project.Id}).
+			Execute(); err != nil {
+			return err
+		}
+
+		return audit.Record(tx.DB(), project.Id, actor, audit.Change{
+			Operation: audit.O_UPDATE,
+			Entity:    audit.ENT_PROJECT,
+			EntityId:  project.Id,
+			Before:    map[string]string{"name": project.Name},
+			After:     map[string]string{"name": name},
+		})
+	})
+	if err != nil {
+		return apperr.Internal(err)
+	}
+
+	project.Name = name
+	project.Updated = now
+	return nil
+}
+
+// DuplicateProject copies the types, nodes and edges of a project into a new
+// private project owned by actor. Every record gets a new id, nodes and edges
+// start over at revision 1 and the trash is left behind.
+func DuplicateProject(app *pocketbase.PocketBase, project *graph.Project, actor string) (*graph.Project, error) {
+	duplicate := &graph.Project{}
+	now := time.Now().UTC().Format("2006-01-02 15:04:05.000Z")
+
+	err := app.RunInTransaction(func(tx core.App) error {
+		query := `
+		INSERT INTO projects (name, owner, visibility, created, updated)
+		VALUES ({:name}, {:owner}, {:visibility}, {:now}, {:now})
+		RETURNING id, name, owner, visibility, created, updated
+		`
+		if err := tx.DB().
+			NewQuery(query).
+			Bind(dbx.Params{
+				"name":       "Copy of " + project.Name,
+				"owner":      actor,
+				"visibility": graph.V_PRIVATE,
+				"now":        now,
+			}).
+			One(duplicate); err != nil {
+			return err
+		}
+
+		// ids maps the id of every copied record to the id of its copy.
+		ids := map[string]string{}
+		insert_copies := func(query string, records []dbx.Params) error {
+			for _, params := range records {
+				var id string
+				if err := tx.DB().NewQuery(query).Bind(params).Row(&id); err != nil {
+					return err
+				}
+				ids[params["id"].(string)] = id
+			}
+			return nil
+		}
+
+		node_types := []graph.NodeType{}
+		if err := tx.DB().Select("*").From("node_types").Where(dbx.HashExp{"project": project.Id}).All(&node_types); err != nil {
+			return err
+		}
+		records := []dbx.Params{}
+		for _, t := range node_types {
+			records = append(records, dbx.Params{
+				"id":           t.Id,
+				"name":         t.Name,
+				"fill_color":   t.FillColor,
+				"stroke_color": t.StrokeColor,
+				"stroke_width": t.StrokeWidth,
+				"shape":        t.Shape,
+				"metadata":     t.Metadata,
+				"schema":       t.Schema,
+				"project":      duplicate.Id,
+			})
+		}
+		if err := insert_copies(`
+		INSERT INTO node_types (name, fill_color, stroke_color, stroke_width, shape, metadata, schema, project)
+		VALUES ({:name}, {:fill_color}, {:stroke_color}, {:stroke_width}, {:shape}, {:metadata}, {:schema}, {:project})
+		RETURNING id
+		`, records); err != nil {
+			return err
+		}
+
+		edge_types := []graph.EdgeType{}
+		if err := tx.DB().Select("*").From("edge_types").Where(dbx.HashExp{"project": project.Id}).All(&edge_types); err != nil {
+			return err
+		}
+		records = []dbx.Params{}
+		for _, t := range edge_types {
+			records = append(records, dbx.Params{
+				"id":           t.Id,
+				"name":         t.Name,
+				"stroke_width": t.StrokeWidth,
+				"stroke_color": t.StrokeColor,
+				"line_dash":    t.LineDash,
+				"metadata":     t.Metadata,
+				"schema":       t.Schema,
+				"project":      duplicate.Id,
+			})
+		}
+		if err := insert_copies(`
+		INSERT INTO edge_types (name, stroke_width, stroke_color, line_dash, metadata, schema, project)
+		VALUES ({:name}, {:stroke_width}, {:stroke_color}, {:line_dash}, {:metadata}, {:schema}, {:project})
+		RETURNING id
+		`, records); err != nil {
+			return err
+		}
+
+		nodes := []graph.Node{}
+		if err := tx.DB().Select("*").From("nodes").Where(dbx.HashExp{"project": project.Id, "deleted_at": ""}).All(&nodes); err != nil {
+			return err
+		}
+		records = []dbx.Params{}
+		for _, n := range nodes {
+			records = append(records, dbx.Params{
+				"id":       n.Id,
+				"name":     n.Name,
+				"type":     ids[n.Type],
+				"x":        n.X,
+				"y":        n.Y,
+				"metadata": n.Metadata,
+				"project":  duplicate.Id,
+				"now":      now,
+			})
+		}
+		if err := insert_copies(`
+		INSERT INTO nodes (name, type, x, y, metadata, project, revision, created, updated)
+		VALUES ({:name}, {:type}, {:x}, {:y}, {:metadata}, {:project}, 1, {:now}, {:now})
+		RETURNING id
+		`, records); err != nil {
+			return err
+		}
+
+		edges := []graph.Edge{}
+		if err := tx.DB().Select("*").From("edges").Where(dbx.HashExp{"project": project.Id, "deleted_at": ""}).All(&edges); err != nil {
+			return err
+		}
+		records = []dbx.Params{}
+		for _, e := range edges {
+			records = append(records, dbx.Params{
+				"id":       e.Id,
+				"start_id": ids[e.StartId],
+				"end_id":   ids[e.EndId],
+				"type":     ids[e.Type],
+				"name":     e.Name,
+				"metadata": e.Metadata,
+				"project":  duplicate.Id,
+				"now":      now,
+			})
+		}
+		if err := insert_copies(`
+		INSERT INTO edges (start_id, end_id, type, name, metadata, project, revision, created, updated)
+		VALUES ({:start_id}, {:end_id}, {:type}, {:name}, {:metadata}, {:project}, 1, {:now}, {:now})
+		RETURNING id
+		`, records); err != nil {
+			return err
+		}
+
+		return audit.Record(tx.DB(), duplicate.Id, actor, audit.Change{
+			Operation: audit.O_CREATE,
+			Entity:    audit.ENT_PROJECT,
+			EntityId:  duplicate.Id,
+			After:     map[string]string{"name": duplicate.Name, "duplicate_of": project.Id},
+		})
+	})
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+
+	duplicate.Permissions = graph.P_ALL
+	return duplicate, nil
+}
@@ -1,7 +1,11 @@
 package dashboard
 
 import (
-	"koppla/apps/vaev/middleware"
+	"context"
+	"database/sql"
+	"errors"
+	"koppla/apps/vaev/apperr"
+	"koppla/apps/vaev/constants"
 	"koppla/apps/vaev/views/auth"
 	"koppla/apps/vaev/views/graph"
 	"net/http"
@@ -11,39 +15,140 @@ import (
 	"github.com/pocketbase/pocketbase"
 )
 
-func GetProject(app *pocketbase.PocketBase, r *http.Request) *graph.Project {
+// GetProject reads the project in the URL. Projects in the trash are not
+// found.
+func GetProject(app *pocketbase.PocketBase, r *http.Request) (*graph.Project, error) {
 	project_id := chi.URLParam(r, "id")
 	if project_id == "" {
-		return nil
+		return nil, apperr.NotFound("Project not found")
 	}
 
 	project := &graph.Project{}
 
-	app.DB().
+	err := app.DB().
 		Select("*").
 		From("projects").
-		Where(dbx.NewExp("id = {:id}", dbx.Params{"id": project_id})).
+		Where(dbx.HashExp{"id": project_id, "deleted_at": ""}).
 		One(project)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperr.NotFound("Project not found")
+	}
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
 
-	return project
+	return project, nil
 }
 
-func ValidateProjectOwner(app *pocketbase.PocketBase, w http.ResponseWriter, r *http.Request) bool {
-	project := GetProject(app, r)
-	if project == nil {
-		middleware.WriteJSONNotFound(w)
-		return false
+// projectPermissions returns what the user may do with the project, and
+// whether the user is its owner or one of its members at all.
+func projectPermissions(app *pocketbase.PocketBase, project *graph.Project, user_id string) (graph.Permission, bool, error) {
+	if project.Owner == user_id {
+		return graph.P_ALL, true, nil
+	}
+
+	var permissions graph.Permission
+	err := app.DB().
+		NewQuery("SELECT permissions FROM project_members WHERE project = {:project} AND user = {:user}").
+		Bind(dbx.Params{"project": project.Id, "user": user_id}).
+		Row(&permissions)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return permissions, true, nil
+}
+
+// ValidateProjectPermission lets the owner through, as well as members that
+// have been granted every permission in required. The returned project holds
+// the permissions of the signed in user.
+func ValidateProjectPermission(app *pocketbase.PocketBase, r *http.Request, required graph.Permission) (*graph.Project, error) {
+	project, err := GetProject(app, r)
+	if err != nil {
+		return nil, err
 	}
 
 	user, err := auth.GetSignedInUser(app, r)
 	if err != nil {
-		return false
+		return nil, apperr.Unauthorized("You need to sign in to access this resource")
+	}
+	if token := auth.AccessTokenFromContext(r); token != nil && !token.Allows(project.Id) {
+		return nil, apperr.Forbidden("The access token does not grant access to this project")
+	}
+
+	permissions, is_member, err := projectPermissions(app, project, user.Id)
+	if err != nil {
+		return nil, apperr.Internal(err)
+	}
+	if !is_member {
+		return nil, apperr.Forbidden("You are not authorized to access this resource")
+	}
+	permissions = auth.AccessTokenFromContext(r).Permissions(permissions)
+	if !graph.HasPermission(permissions, required) {
+		return nil, apperr.Forbidden("You do not have permission to do this")
+	}
+
+	project.Permissions = permissions
+	return project, nil
+}
+
+// ValidateProjectViewer lets the owner and members through, as well as
+// anyone, signed in or not, when the project is shared. The returned project
+// holds the permissions of the viewer, which are none for guests.
+func ValidateProjectViewer(app *pocketbase.PocketBase, r *http.Request) (*graph.Project, error) {
+	project, err := GetProject(app, r)
+	if err != nil {
+		return nil, err
+	}
+
+	user, user_err := auth.GetSignedInUser(app, r)
+	// An access token is only good for the projects it was scoped to, on
+	// others its user is a guest.
+	token := auth.AccessTokenFromContext(r)
+	in_scope := token == nil || token.Allows(project.Id)
+	if user_err == nil && in_scope {
+		permissions, is_member, err := projectPermissions(app, project, user.Id)
+		if err != nil {
+			return nil, apperr.Internal(err)
+		}
+		if is_member {
+			project.Permissions = token.Permissions(permissions)
+			return project, nil
+		}
 	}
 
-	if project.Owner != user.Id {
-		middleware.WriteJSONUnauthorized(w)
-		return false
+	if project.IsShared() {
+		return project, nil
 	}
 
-	return true
+	if user_err != nil {
+		return nil, apperr.Unauthorized("You need to sign in to access this project")
+	}
+	return nil, apperr.Forbidden("Access denied")
+}
+
+// WithProjectPermission guards a /v-api route with ValidateProjectPermission.
+// Handlers get the project through ProjectFromContext.
+func WithProjectPermission(app *pocketbase.PocketBase, required graph.Permission) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			project, err := ValidateProjectPermission(app, r, required)
+			if err != nil {
+				apperr.WriteJSON(w, r, err)
+				return
+			}
+
+			new_ctx := context.WithValue(r.Context(), constants.CTX_PROJECT, project)
+			next.ServeHTTP(w, r.WithContext(new_ctx))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+func ProjectFromContext(r *http.Request) *graph.Project {
+	project, _ := r.Context().Value(constants.CTX_PROJECT).(*graph.Project)
+	return project
 }
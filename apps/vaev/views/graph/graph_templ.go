@@ -10,8 +10,16 @@ import templruntime "github.com/a-h/templ/runtime"
 
 import "github.com/pocketbase/pocketbase"
 import "fmt"
+import "bytes"
+import "encoding/json"
+import "strconv"
+import "strings"
+import "koppla/apps/vaev/hub"
+import "koppla/apps/vaev/middleware"
 
-func Main(app *pocketbase.PocketBase, project_id string) templ.Component {
+// Main renders the editor. With a snapshot it shows that version of the
+// project instead, read-only and without the live stream.
+func Main(app *pocketbase.PocketBase, project_id string, read_only bool, snapshot *Snapshot) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -40,137 +48,451 @@ func Main(app *pocketbase.PocketBase, project_id string) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Var2 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
-			templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
-			templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
-			if !templ_7745c5c3_IsBuffer {
-				defer func() {
-					templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
-					if templ_7745c5c3_Err == nil {
-						templ_7745c5c3_Err = templ_7745c5c3_BufErr
-					}
-				}()
+		if snapshot != nil {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<div class=\"control-panel__section control-panel__notice\"><span class=\"material-symbols\">history</span> <span>Snapshot: ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var2 string
+			templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(snapshot.Name)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 26, Col: 36}
 			}
-			ctx = templ.InitializeContext(ctx)
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<div class=\"control-panel__button-group\"><graph-btn action=\"sort_force\" icon=\"graph_3\" title=\"Fruchterman-Reingold esque sorting\"></graph-btn> <graph-btn disabled action=\"sort_force\" icon=\"graph_1\" title=\"Hierarchy based sorting\"></graph-btn></div>")
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			return nil
-		})
-		templ_7745c5c3_Err = ControlPanelSection("Sorting", "graph_7", -1).Render(templ.WithChildren(ctx, templ_7745c5c3_Var2), templ_7745c5c3_Buffer)
-		if templ_7745c5c3_Err != nil {
-			return templ_7745c5c3_Err
-		}
-		templ_7745c5c3_Var3 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
-			templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
-			templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
-			if !templ_7745c5c3_IsBuffer {
-				defer func() {
-					templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
-					if templ_7745c5c3_Err == nil {
-						templ_7745c5c3_Err = templ_7745c5c3_BufErr
-					}
-				}()
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "</span> <a href=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var3 templ.SafeURL
+			templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinURLErrs(templ.SafeURL(fmt.Sprintf("/project/%s", project_id)))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 27, Col: 68}
 			}
-			ctx = templ.InitializeContext(ctx)
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<div class=\"control-panel__button-group\"><graph-btn action=\"distribute_vertical\" icon=\"align_space_even\"></graph-btn> <graph-btn action=\"distribute_horizontal\" icon=\"align_justify_space_even\"></graph-btn> <graph-btn action=\"align_vertical\" icon=\"align_horizontal_center\"></graph-btn> <graph-btn action=\"align_horizontal\" icon=\"align_vertical_center\"></graph-btn></div>")
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			return nil
-		})
-		templ_7745c5c3_Err = ControlPanelSection("Position", "align_justify_stretch", 0).Render(templ.WithChildren(ctx, templ_7745c5c3_Var3), templ_7745c5c3_Buffer)
-		if templ_7745c5c3_Err != nil {
-			return templ_7745c5c3_Err
-		}
-		templ_7745c5c3_Var4 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
-			templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
-			templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
-			if !templ_7745c5c3_IsBuffer {
-				defer func() {
-					templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
-					if templ_7745c5c3_Err == nil {
-						templ_7745c5c3_Err = templ_7745c5c3_BufErr
-					}
-				}()
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "\">Back to project</a></div>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
 			}
-			ctx = templ.InitializeContext(ctx)
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "<div id=\"edge-type-select\" class=\"edge-type-select\" data-on-load=\"")
+		} else {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "<div id=\"live\" data-on-load=\"")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var5 string
-			templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("@get('/sse/project/%s/edge-select')", project_id))
+			var templ_7745c5c3_Var4 string
+			templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("@get('/sse/project/%s/live')", project_id))
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 34, Col: 68}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 32, Col: 75}
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "\"></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "\"></div><div id=\"presence\"></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			return nil
-		})
-		templ_7745c5c3_Err = ControlPanelSection("Connection settings", "mediation", 1).Render(templ.WithChildren(ctx, templ_7745c5c3_Var4), templ_7745c5c3_Buffer)
-		if templ_7745c5c3_Err != nil {
-			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Var6 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
-			templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
-			templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
-			if !templ_7745c5c3_IsBuffer {
-				defer func() {
-					templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
-					if templ_7745c5c3_Err == nil {
-						templ_7745c5c3_Err = templ_7745c5c3_BufErr
-					}
-				}()
+		if read_only || snapshot != nil {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "<div class=\"control-panel__section control-panel__notice\"><span class=\"material-symbols\">visibility</span> Read-only view</div>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		} else {
+			templ_7745c5c3_Var5 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+				templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+				if !templ_7745c5c3_IsBuffer {
+					defer func() {
+						templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+						if templ_7745c5c3_Err == nil {
+							templ_7745c5c3_Err = templ_7745c5c3_BufErr
+						}
+					}()
+				}
+				ctx = templ.InitializeContext(ctx)
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "<div class=\"control-panel__button-group\"><graph-btn action=\"sort_force\" icon=\"graph_3\" title=\"Fruchterman-Reingold esque sorting\"></graph-btn> <graph-btn action=\"sort_hierarchy\" icon=\"graph_1\" title=\"Hierarchy based sorting\"></graph-btn> <graph-btn action=\"sort_radial\" icon=\"graph_2\" title=\"Radial sorting\"></graph-btn></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				return nil
+			})
+			templ_7745c5c3_Err = ControlPanelSection("Sorting", "graph_7", -1).Render(templ.WithChildren(ctx, templ_7745c5c3_Var5), templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, " ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Var6 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+				templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+				if !templ_7745c5c3_IsBuffer {
+					defer func() {
+						templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+						if templ_7745c5c3_Err == nil {
+							templ_7745c5c3_Err = templ_7745c5c3_BufErr
+						}
+					}()
+				}
+				ctx = templ.InitializeContext(ctx)
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "<div class=\"control-panel__button-group\"><graph-btn action=\"distribute_vertical\" icon=\"align_space_even\"></graph-btn> <graph-btn action=\"distribute_horizontal\" icon=\"align_justify_space_even\"></graph-btn> <graph-btn action=\"align_vertical\" icon=\"align_horizontal_center\"></graph-btn> <graph-btn action=\"align_horizontal\" icon=\"align_vertical_center\"></graph-btn></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				return nil
+			})
+			templ_7745c5c3_Err = ControlPanelSection("Position", "align_justify_stretch", 0).Render(templ.WithChildren(ctx, templ_7745c5c3_Var6), templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, " ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Var7 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+				templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+				if !templ_7745c5c3_IsBuffer {
+					defer func() {
+						templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+						if templ_7745c5c3_Err == nil {
+							templ_7745c5c3_Err = templ_7745c5c3_BufErr
+						}
+					}()
+				}
+				ctx = templ.InitializeContext(ctx)
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "<div id=\"edge-type-select\" class=\"edge-type-select\" data-on-load=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var8 string
+				templ_7745c5c3_Var8, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("@get('/sse/project/%s/edge-select')", project_id))
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 62, Col: 69}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var8))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "\"></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				return nil
+			})
+			templ_7745c5c3_Err = ControlPanelSection("Connection settings", "mediation", 1).Render(templ.WithChildren(ctx, templ_7745c5c3_Var7), templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, " ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Var9 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+				templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+				if !templ_7745c5c3_IsBuffer {
+					defer func() {
+						templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+						if templ_7745c5c3_Err == nil {
+							templ_7745c5c3_Err = templ_7745c5c3_BufErr
+						}
+					}()
+				}
+				ctx = templ.InitializeContext(ctx)
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "<div id=\"node-type-select\" class=\"node-type-select\" data-on-load=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var10 string
+				templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("@get('/sse/project/%s/node-select')", project_id))
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 71, Col: 69}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "\"></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				return nil
+			})
+			templ_7745c5c3_Err = ControlPanelSection("Node settings", "control_point_duplicate", 2).Render(templ.WithChildren(ctx, templ_7745c5c3_Var9), templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, " ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Var11 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+				templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+				if !templ_7745c5c3_IsBuffer {
+					defer func() {
+						templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+						if templ_7745c5c3_Err == nil {
+							templ_7745c5c3_Err = templ_7745c5c3_BufErr
+						}
+					}()
+				}
+				ctx = templ.InitializeContext(ctx)
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, "<div id=\"types\" data-on-load=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var12 string
+				templ_7745c5c3_Var12, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("@get('/sse/project/%s/types')", project_id))
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 79, Col: 63}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var12))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "\"></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				return nil
+			})
+			templ_7745c5c3_Err = ControlPanelSection("Types", "category", -1).Render(templ.WithChildren(ctx, templ_7745c5c3_Var11), templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 20, " ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Var13 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+				templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+				if !templ_7745c5c3_IsBuffer {
+					defer func() {
+						templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+						if templ_7745c5c3_Err == nil {
+							templ_7745c5c3_Err = templ_7745c5c3_BufErr
+						}
+					}()
+				}
+				ctx = templ.InitializeContext(ctx)
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 21, "<div id=\"snapshots\" data-on-load=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var14 string
+				templ_7745c5c3_Var14, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("@get('/sse/project/%s/snapshots')", project_id))
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 87, Col: 67}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var14))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 22, "\"></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				return nil
+			})
+			templ_7745c5c3_Err = ControlPanelSection("History", "history", -1).Render(templ.WithChildren(ctx, templ_7745c5c3_Var13), templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
 			}
-			ctx = templ.InitializeContext(ctx)
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "<div id=\"node-type-select\" class=\"node-type-select\" data-on-load=\"")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 23, " ")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var7 string
-			templ_7745c5c3_Var7, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("@get('/sse/project/%s/node-select')", project_id))
+			templ_7745c5c3_Var15 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+				templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+				if !templ_7745c5c3_IsBuffer {
+					defer func() {
+						templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+						if templ_7745c5c3_Err == nil {
+							templ_7745c5c3_Err = templ_7745c5c3_BufErr
+						}
+					}()
+				}
+				ctx = templ.InitializeContext(ctx)
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "<div class=\"export\"><a class=\"export__link\" href=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var16 templ.SafeURL
+				templ_7745c5c3_Var16, templ_7745c5c3_Err = templ.JoinURLErrs(exportURL(project_id, "json"))
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 93, Col: 66}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var16))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "\" download>Vaev JSON</a> <a class=\"export__link\" href=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var17 templ.SafeURL
+				templ_7745c5c3_Var17, templ_7745c5c3_Err = templ.JoinURLErrs(exportURL(project_id, "graphml"))
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 94, Col: 69}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var17))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 26, "\" download>GraphML (yEd)</a> <a class=\"export__link\" href=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var18 templ.SafeURL
+				templ_7745c5c3_Var18, templ_7745c5c3_Err = templ.JoinURLErrs(exportURL(project_id, "gexf"))
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 95, Col: 66}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var18))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 27, "\" download>GEXF (Gephi)</a> <a class=\"export__link\" href=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var19 templ.SafeURL
+				templ_7745c5c3_Var19, templ_7745c5c3_Err = templ.JoinURLErrs(exportURL(project_id, "dot"))
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 96, Col: 65}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var19))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 28, "\" download>DOT (Graphviz)</a> <a class=\"export__link\" href=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var20 templ.SafeURL
+				templ_7745c5c3_Var20, templ_7745c5c3_Err = templ.JoinURLErrs(exportURL(project_id, "svg"))
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 97, Col: 65}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var20))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 29, "\" download>SVG image</a> <a class=\"export__link\" href=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var21 templ.SafeURL
+				templ_7745c5c3_Var21, templ_7745c5c3_Err = templ.JoinURLErrs(exportURL(project_id, "png"))
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 98, Col: 65}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var21))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 30, "\" download>PNG image</a></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				return nil
+			})
+			templ_7745c5c3_Err = ControlPanelSection("Export", "download", -1).Render(templ.WithChildren(ctx, templ_7745c5c3_Var15), templ_7745c5c3_Buffer)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 43, Col: 68}
+				return templ_7745c5c3_Err
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var7))
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 31, " ")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "\"></div>")
+			templ_7745c5c3_Var22 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+				templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+				templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+				if !templ_7745c5c3_IsBuffer {
+					defer func() {
+						templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+						if templ_7745c5c3_Err == nil {
+							templ_7745c5c3_Err = templ_7745c5c3_BufErr
+						}
+					}()
+				}
+				ctx = templ.InitializeContext(ctx)
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 32, "<div id=\"trash\" data-on-load=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var23 string
+				templ_7745c5c3_Var23, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("@get('/sse/project/%s/trash')", project_id))
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 105, Col: 63}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var23))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 33, "\"></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				return nil
+			})
+			templ_7745c5c3_Err = ControlPanelSection("Trash", "delete", -1).Render(templ.WithChildren(ctx, templ_7745c5c3_Var22), templ_7745c5c3_Buffer)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			return nil
-		})
-		templ_7745c5c3_Err = ControlPanelSection("Node settings", "control_point_duplicate", 2).Render(templ.WithChildren(ctx, templ_7745c5c3_Var6), templ_7745c5c3_Buffer)
+		}
+		templ_7745c5c3_Err = Footer().Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = Footer().Render(ctx, templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 34, "</div><dialog id=\"create-edge-dialog\"><p>Choose connection type</p><form id=\"create-edge-form\" method=\"dialog\" koppla-submit=\"createEdge\"><label>Type:  <select name=\"type\" koppla-value=\"edge_type_signal\" id=\"edge-type-select\"></select></label><div class=\"btn-container\"><button id=\"close\">Create</button></div></form></dialog><script type=\"module\">\n\t\t\timport {PBStore, CSVWriter, LiveSession, driver} from \"/dist/graph.js\";\n\t\t\tconst store = new PBStore(")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Var24, templ_7745c5c3_Err := templruntime.ScriptContentOutsideStringLiteral(project_id)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 129, Col: 41}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var24)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 35, ", ")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Var25, templ_7745c5c3_Err := templruntime.ScriptContentOutsideStringLiteral(read_only)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 129, Col: 56}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var25)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "</div><dialog id=\"create-edge-dialog\"><p>Choose connection type</p><form id=\"create-edge-form\" method=\"dialog\" koppla-submit=\"createEdge\"><label>Type:  <select name=\"type\" koppla-value=\"edge_type_signal\" id=\"edge-type-select\"></select></label><div class=\"btn-container\"><button id=\"close\">Create</button></div></form></dialog><script type=\"module\">\n\t\t\timport {PBStore, CSVWriter, driver} from \"/dist/graph.js\";\n\t\t\tconst store = new PBStore(")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 36, ", ")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Var8, templ_7745c5c3_Err := templruntime.ScriptContentOutsideStringLiteral(project_id)
+		templ_7745c5c3_Var26, templ_7745c5c3_Err := templruntime.ScriptContentOutsideStringLiteral(snapshotId(snapshot))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 66, Col: 41}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 129, Col: 82}
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var8)
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var26)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, ")\n\t\t\tconst csv_data = new CSVWriter(`transaction_id,timestamp,from_account,to_account,amount,currency,transaction_type,location_country,location_city,ip_address,device_id,is_flagged_for_fraud,fraud_pattern_type\nTXN000001,2025-07-16T19:00:00Z,ACC1001,ACC2001,50.25,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,0,Legitimate\nTXN000002,2025-07-16T19:00:15Z,ACC1002,ACC2002,120.00,SEK,credit,Sweden,Gothenburg,192.168.1.11,DEV002,0,Legitimate\nTXN000003,2025-07-16T19:00:30Z,ACC1003,ACC2003,30.50,DKK,debit,Denmark,Copenhagen,192.168.1.12,DEV003,0,Legitimate\nTXN000004,2025-07-16T19:00:45Z,ACC1004,ACC2004,200.75,NOK,credit,Norway,Oslo,192.168.1.13,DEV004,0,Legitimate\nTXN000005,2025-07-16T19:01:00Z,ACC1005,ACC2005,80.10,GBP,debit,UK,London,192.168.1.14,DEV005,0,Legitimate\nTXN000006,2025-07-16T19:01:15Z,ACC1006,ACC2006,15.99,USD,credit,USA,New York,192.168.1.15,DEV006,0,Legitimate\nTXN000007,2025-07-16T19:01:30Z,ACC1007,ACC2007,75.00,CAD,debit,Canada,Toronto,192.168.1.16,DEV007,0,Legitimate\nTXN000008,2025-07-16T19:01:45Z,ACC1008,ACC2008,180.30,AUD,credit,Australia,Sydney,192.168.1.17,DEV008,0,Legitimate\nTXN000009,2025-07-16T19:02:00Z,ACC1009,ACC2009,45.60,NZD,debit,New Zealand,Wellington,192.168.1.18,DEV009,0,Legitimate\nTXN000010,2025-07-16T19:02:15Z,ACC1010,ACC2010,99.99,CHF,credit,Switzerland,Zurich,192.168.1.19,DEV010,0,Legitimate\nTXN000011,2025-07-16T19:02:30Z,ACC1011,ACC2011,10.00,EUR,debit,Sweden,Trollhattan,192.168.1.20,DEV011,0,Legitimate\nTXN000012,2025-07-16T19:02:45Z,ACC1012,ACC2012,250.00,SEK,credit,Sweden,Stockholm,192.168.1.21,DEV012,0,Legitimate\nTXN000013,2025-07-16T19:03:00Z,ACC1013,ACC2013,60.00,DKK,debit,Denmark,Aarhus,192.168.1.22,DEV013,0,Legitimate\nTXN000014,2025-07-16T19:03:15Z,ACC1014,ACC2014,130.50,NOK,credit,Norway,Bergen,192.168.1.23,DEV014,0,Legitimate\nTXN000015,2025-07-16T19:03:30Z,ACC1015,ACC2015,25.75,GBP,debit,UK,Manchester,192.168.1.24,DEV015,0,Legitimate\nTXN000016,2025-07-16T19:03:45Z,ACC1016,ACC2016,190.00,USD,credit,USA,Los Angeles,192.168.1.25,DEV016,0,Legitimate\nTXN000017,2025-07-16T19:04:00Z,ACC1017,ACC2017,70.20,CAD,debit,Canada,Vancouver,192.168.1.26,DEV017,0,Legitimate\nTXN000018,2025-07-16T19:04:15Z,ACC1018,ACC2018,110.40,AUD,credit,Australia,Melbourne,192.168.1.27,DEV018,0,Legitimate\nTXN000019,2025-07-16T19:04:30Z,ACC1019,ACC2019,55.00,NZD,debit,New Zealand,Auckland,192.168.1.28,DEV019,0,Legitimate\nTXN000020,2025-07-16T19:04:45Z,ACC1020,ACC2020,85.80,CHF,credit,Switzerland,Geneva,192.168.1.29,DEV020,0,Legitimate\nTXN000021,2025-07-16T19:05:00Z,ACC1001,ACC2021,15000.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,High-Value Single\nTXN000022,2025-07-16T19:05:30Z,ACC1022,ACC2022,0.85,USD,debit,USA,Miami,203.0.113.1,DEV022,1,Rapid Small Transactions\nTXN000023,2025-07-16T19:05:35Z,ACC1022,ACC2023,1.20,USD,debit,USA,Miami,203.0.113.1,DEV022,1,Rapid Small Transactions\nTXN000024,2025-07-16T19:05:40Z,ACC1022,ACC2024,0.99,USD,debit,USA,Miami,203.0.113.1,DEV022,1,Rapid Small Transactions\nTXN000025,2025-07-16T19:05:45Z,ACC1022,ACC2025,2.50,USD,debit,USA,Miami,203.0.113.1,DEV022,1,Rapid Small Transactions\nTXN000026,2025-07-16T19:06:00Z,ACC1026,ACC2026,500.00,USD,debit,Nigeria,Lagos,10.0.0.1,DEV026,1,Geographic Anomaly\nTXN000027,2025-07-16T19:06:15Z,ACC1027,ACC2027,1000.00,EUR,debit,Russia,Moscow,10.0.0.2,DEV027,1,Geographic Anomaly\nTXN000028,2025-07-17T03:00:00Z,ACC1001,ACC2028,2500.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Time Anomaly\nTXN000029,2025-07-17T03:00:15Z,ACC1004,ACC2029,5000.00,NOK,debit,Norway,Oslo,192.168.1.13,DEV004,1,Time Anomaly\nTXN000030,2025-07-16T19:07:00Z,ACC1030,ACC2030,50000.00,USD,credit,USA,Miami,203.0.113.2,DEV030,1,Money Mule Entry\nTXN000031,2025-07-16T19:07:10Z,ACC1030,ACC2031,9800.00,USD,debit,USA,New York,203.0.113.2,DEV030,1,Money Mule Outflow\nTXN000032,2025-07-16T19:07:20Z,ACC1030,ACC2032,12000.00,EUR,debit,Germany,Berlin,203.0.113.2,DEV030,1,Money Mule Outflow\nTXN000033,2025-07-16T19:07:30Z,ACC1030,ACC2033,7500.00,GBP,debit,UK,London,203.0.113.2,DEV030,1,Money Mule Outflow\nTXN000034,2025-07-16T19:07:40Z,ACC1030,ACC2034,15000.00,CAD,debit,Canada,Montreal,203.0.113.2,DEV030,1,Money Mule Outflow\nTXN000035,2025-07-16T19:08:00Z,ACC1035,ACC2035,10000.00,USD,debit,USA,New York,172.16.0.1,NEWDEV01,1,New Account Anomaly\nTXN000036,2025-07-16T19:08:15Z,ACC1035,ACC2036,5000.00,USD,credit,USA,New York,172.16.0.1,NEWDEV01,1,New Account Anomaly\nTXN000037,2025-07-16T19:08:30Z,ACC1037,ACC2037,20000.00,EUR,debit,Sweden,Stockholm,10.0.0.3,DEV037,1,Round Number Transfer\nTXN000038,2025-07-16T19:08:45Z,ACC1038,ACC2038,15000.00,USD,credit,UAE,Dubai,10.0.0.4,DEV038,1,High-Risk Country\nTXN000039,2025-07-16T19:09:00Z,ACC1039,ACC2039,500.00,USD,debit,USA,Chicago,192.168.1.30,DEV039,0,Legitimate\nTXN000040,2025-07-16T19:09:15Z,ACC1040,ACC2040,10.00,EUR,credit,France,Paris,192.168.1.31,DEV040,0,Legitimate\nTXN000041,2025-07-16T19:09:30Z,ACC1041,ACC2041,75.00,SEK,debit,Sweden,Malmo,192.168.1.32,DEV041,0,Legitimate\nTXN000042,2025-07-16T19:09:45Z,ACC1042,ACC2042,300.00,NOK,credit,Norway,Trondheim,192.168.1.33,DEV042,0,Legitimate\nTXN000043,2025-07-16T19:10:00Z,ACC1043,ACC2043,90.50,GBP,debit,UK,Birmingham,192.168.1.34,DEV043,0,Legitimate\nTXN000044,2025-07-16T19:10:15Z,ACC1044,ACC2044,25.00,USD,credit,USA,Houston,192.168.1.35,DEV044,0,Legitimate\nTXN000045,2025-07-16T19:10:30Z,ACC1045,ACC2045,150.00,CAD,debit,Canada,Calgary,192.168.1.36,DEV045,0,Legitimate\nTXN000046,2025-07-16T19:10:45Z,ACC1046,ACC2046,50.00,AUD,credit,Australia,Perth,192.168.1.37,DEV046,0,Legitimate\nTXN000047,2025-07-16T19:11:00Z,ACC1047,ACC2047,20.00,NZD,debit,New Zealand,Christchurch,192.168.1.38,DEV047,0,Legitimate\nTXN000048,2025-07-16T19:11:15Z,ACC1048,ACC2048,40.00,CHF,credit,Switzerland,Basel,192.168.1.39,DEV048,0,Legitimate\nTXN000049,2025-07-16T19:11:30Z,ACC1049,ACC2049,5.00,EUR,debit,Sweden,Uppsala,192.168.1.40,DEV049,0,Legitimate\nTXN000050,2025-07-16T19:11:45Z,ACC1050,ACC2050,100.00,SEK,credit,Sweden,Lund,192.168.1.41,DEV050,0,Legitimate\nTXN000051,2025-07-16T19:12:00Z,ACC1001,ACC2051,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000052,2025-07-16T19:12:05Z,ACC1001,ACC2052,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000053,2025-07-16T19:12:10Z,ACC1001,ACC2053,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000054,2025-07-16T19:12:15Z,ACC1001,ACC2054,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000055,2025-07-16T19:12:20Z,ACC1001,ACC2055,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000056,2025-07-16T19:13:00Z,ACC1056,ACC2056,7500.00,USD,debit,Brazil,Rio de Janeiro,10.0.0.5,DEV056,1,Geographic Anomaly\nTXN000057,2025-07-16T19:13:15Z,ACC1057,ACC2057,12000.00,JPY,debit,China,Shanghai,10.0.0.6,DEV057,1,Geographic Anomaly\nTXN000058,2025-07-17T04:30:00Z,ACC1002,ACC2058,800.00,SEK,debit,Sweden,Gothenburg,192.168.1.11,DEV002,1,Time Anomaly\nTXN000059,2025-07-17T04:30:15Z,ACC1005,ACC2059,3000.00,GBP,debit,UK,London,192.168.1.14,DEV005,1,Time Anomaly\nTXN000060,2025-07-16T19:14:00Z,ACC1060,ACC2060,80000.00,USD,credit,USA,Dallas,203.0.113.3,DEV060,1,Money Mule Entry\nTXN000061,2025-07-16T19:14:10Z,ACC1060,ACC2061,15000.00,USD,debit,USA,Houston,203.0.113.3,DEV060,1,Money Mule Outflow\nTXN000062,2025-07-16T19:14:20Z,ACC1060,ACC2062,20000.00,AUD,debit,Australia,Sydney,203.0.113.3,DEV060,1,Money Mule Outflow\nTXN000063,2025-07-16T19:14:30Z,ACC1060,ACC2063,18000.00,NZD,debit,New Zealand,Auckland,203.0.113.3,DEV060,1,Money Mule Outflow\nTXN000064,2025-07-16T19:14:40Z,ACC1060,ACC2064,25000.00,SGD,debit,Singapore,Singapore,203.0.113.3,DEV060,1,Money Mule Outflow\nTXN000065,2025-07-16T19:15:00Z,ACC1065,ACC2065,25000.00,USD,debit,USA,Chicago,172.16.0.2,NEWDEV02,1,New Account Anomaly\nTXN000066,2025-07-16T19:15:15Z,ACC1065,ACC2066,10000.00,USD,credit,USA,Chicago,172.16.0.2,NEWDEV02,1,New Account Anomaly\nTXN000067,2025-07-16T19:15:30Z,ACC1067,ACC2067,50000.00,EUR,debit,Germany,Frankfurt,10.0.0.7,DEV067,1,Round Number Transfer\nTXN000068,2025-07-16T19:15:45Z,ACC1068,ACC2068,30000.00,GBP,credit,Turkey,Istanbul,10.0.0.8,DEV068,1,High-Risk Country\nTXN000069,2025-07-16T19:16:00Z,ACC1001,ACC2069,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000070,2025-07-16T19:16:05Z,ACC1001,ACC2070,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000071,2025-07-16T19:16:10Z,ACC1001,ACC2071,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000072,2025-07-16T19:16:15Z,ACC1001,ACC2072,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000073,2025-07-16T19:16:20Z,ACC1001,ACC2073,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000074,2025-07-16T19:17:00Z,ACC1074,ACC2074,100.00,USD,debit,USA,Orlando,192.168.1.42,DEV074,0,Legitimate\nTXN000075,2025-07-16T19:17:15Z,ACC1075,ACC2075,20.00,SEK,credit,Sweden,Vasteras,192.168.1.43,DEV075,0,Legitimate\nTXN000076,2025-07-16T19:17:30Z,ACC1076,ACC2076,50.00,DKK,debit,Denmark,Odense,192.168.1.44,DEV076,0,Legitimate\nTXN000077,2025-07-16T19:17:45Z,ACC1077,ACC2077,100.00,NOK,credit,Norway,Stavanger,192.168.1.45,DEV077,0,Legitimate\nTXN000078,2025-07-16T19:18:00Z,ACC1078,ACC2078,35.00,GBP,debit,UK,Glasgow,192.168.1.46,DEV078,0,Legitimate\nTXN000079,2025-07-16T19:18:15Z,ACC1079,ACC2079,80.00,USD,credit,USA,Phoenix,192.168.1.47,DEV079,0,Legitimate\nTXN000080,2025-07-16T19:18:30Z,ACC1080,ACC2080,200.00,CAD,debit,Canada,Edmonton,192.168.1.48,DEV080,0,Legitimate\nTXN000081,2025-07-16T19:18:45Z,ACC1081,ACC2081,60.00,AUD,credit,Australia,Adelaide,192.168.1.49,DEV081,0,Legitimate\nTXN000082,2025-07-16T19:19:00Z,ACC1082,ACC2082,15.00,NZD,debit,New Zealand,Dunedin,192.168.1.50,DEV082,0,Legitimate\nTXN000083,2025-07-16T19:19:15Z,ACC1083,ACC2083,25.00,CHF,credit,Switzerland,Bern,192.168.1.51,DEV083,0,Legitimate\nTXN000084,2025-07-16T19:19:30Z,ACC1084,ACC2084,5.00,EUR,debit,Sweden,Linkoping,192.168.1.52,DEV084,0,Legitimate\nTXN000085,2025-07-16T19:19:45Z,ACC1085,ACC2085,120.00,SEK,credit,Sweden,Helsingborg,192.168.1.53,DEV085,0,Legitimate\nTXN000086,2025-07-16T19:20:00Z,ACC1086,ACC2086,7500.00,EUR,debit,Latvia,Riga,10.0.0.9,DEV086,1,Geographic Anomaly\nTXN000087,2025-07-16T19:20:15Z,ACC1087,ACC2087,15000.00,RUB,debit,Kazakhstan,Nur-Sultan,10.0.0.10,DEV087,1,High-Risk Country\nTXN000088,2025-07-17T00:30:00Z,ACC1003,ACC2088,600.00,DKK,debit,Denmark,Copenhagen,192.168.1.12,DEV003,1,Time Anomaly\nTXN000089,2025-07-17T00:30:15Z,ACC1006,ACC2089,1200.00,USD,debit,USA,New York,192.168.1.15,DEV006,1,Time Anomaly\nTXN000090,2025-07-16T19:21:00Z,ACC1090,ACC2090,100000.00,USD,credit,USA,Las Vegas,203.0.113.4,DEV090,1,Money Mule Entry\nTXN000091,2025-07-16T19:21:10Z,ACC1090,ACC2091,20000.00,USD,debit,USA,San Francisco,203.0.113.4,DEV090,1,Money Mule Outflow\nTXN000092,2025-07-16T19:21:20Z,ACC1090,ACC2092,30000.00,EUR,debit,France,Marseille,203.0.113.4,DEV090,1,Money Mule Outflow\nTXN000093,2025-07-16T19:21:30Z,ACC1090,ACC2093,25000.00,GBP,debit,Ireland,Dublin,203.0.113.4,DEV090,1,Money Mule Outflow\nTXN000094,2025-07-16T19:21:40Z,ACC1090,ACC2094,18000.00,CHF,debit,Italy,Rome,203.0.113.4,DEV090,1,Money Mule Outflow\nTXN000095,2025-07-16T19:22:00Z,ACC1095,ACC2095,5000.00,USD,debit,USA,Boston,172.16.0.3,NEWDEV03,1,New Account Anomaly\nTXN000096,2025-07-16T19:22:15Z,ACC1095,ACC2096,2000.00,USD,credit,USA,Boston,172.16.0.3,NEWDEV03,1,New Account Anomaly\nTXN000097,2025-07-16T19:22:30Z,ACC1097,ACC2097,75000.00,EUR,debit,Spain,Madrid,10.0.0.11,DEV097,1,Round Number Transfer\nTXN000098,2025-07-16T19:22:45Z,ACC1098,ACC2098,40000.00,USD,credit,North Korea,Pyongyang,10.0.0.12,DEV098,1,High-Risk Country\nTXN000099,2025-07-16T19:23:00Z,ACC1001,ACC2099,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000100,2025-07-16T19:23:05Z,ACC1001,ACC2100,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000101,2025-07-16T19:23:10Z,ACC1001,ACC2101,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000102,2025-07-16T19:23:15Z,ACC1001,ACC2102,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000103,2025-07-16T19:23:20Z,ACC1001,ACC2103,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000104,2025-07-16T19:24:00Z,ACC1104,ACC2104,50.00,USD,debit,USA,Dallas,192.168.1.54,DEV104,0,Legitimate\nTXN000105,2025-07-16T19:24:15Z,ACC1105,ACC2105,10.00,SEK,credit,Sweden,Orebro,192.168.1.55,DEV105,0,Legitimate\nTXN000106,2025-07-16T19:24:30Z,ACC1106,ACC2106,20.00,DKK,debit,Denmark,Esbjerg,192.168.1.56,DEV106,0,Legitimate\nTXN000107,2025-07-16T19:24:45Z,ACC1107,ACC2107,50.00,NOK,credit,Norway,Kristiansand,192.168.1.57,DEV107,0,Legitimate\nTXN000108,2025-07-16T19:25:00Z,ACC1108,ACC2108,15.00,GBP,debit,UK,Edinburgh,192.168.1.58,DEV108,0,Legitimate\nTXN000109,2025-07-16T19:25:15Z,ACC1109,ACC2109,30.00,USD,credit,USA,San Antonio,192.168.1.59,DEV109,0,Legitimate\nTXN000110,2025-07-16T19:25:30Z,ACC1110,ACC2110,75.00,CAD,debit,Canada,Quebec City,192.168.1.60,DEV110,0,Legitimate\nTXN000111,2025-07-16T19:25:45Z,ACC1111,ACC2111,25.00,AUD,credit,Australia,Canberra,192.168.1.61,DEV111,0,Legitimate\nTXN000112,2025-07-16T19:26:00Z,ACC1112,ACC2112,10.00,NZD,debit,New Zealand,Hamilton,192.168.1.62,DEV112,0,Legitimate\nTXN000113,2025-07-16T19:26:15Z,ACC1113,ACC2113,15.00,CHF,credit,Switzerland,Lausanne,192.168.1.63,DEV113,0,Legitimate\nTXN000114,2025-07-16T19:26:30Z,ACC1114,ACC2114,2.00,EUR,debit,Sweden,Jonkoping,192.168.1.64,DEV114,0,Legitimate\nTXN000115,2025-07-16T19:26:45Z,ACC1115,ACC2115,80.00,SEK,credit,Sweden,Norrkoping,192.168.1.65,DEV115,0,Legitimate\nTXN000116,2025-07-16T19:27:00Z,ACC1116,ACC2116,25000.00,USD,debit,USA,New York,192.168.1.66,DEV116,1,High-Value Single\nTXN000117,2025-07-16T19:27:30Z,ACC1117,ACC2117,0.50,GBP,debit,UK,London,203.0.113.5,DEV117,1,Rapid Small Transactions\nTXN000118,2025-07-16T19:27:35Z,ACC1117,ACC2118,0.75,GBP,debit,UK,London,203.0.113.5,DEV117,1,Rapid Small Transactions\nTXN000119,2025-07-16T19:27:40Z,ACC1117,ACC2119,0.25,GBP,debit,UK,London,203.0.113.5,DEV117,1,Rapid Small Transactions\nTXN000120,2025-07-16T19:27:45Z,ACC1117,ACC2120,1.00,GBP,debit,UK,London,203.0.113.5,DEV117,1,Rapid Small Transactions\nTXN000121,2025-07-16T19:28:00Z,ACC1121,ACC2121,1000.00,EUR,debit,Romania,Bucharest,10.0.0.13,DEV121,1,Geographic Anomaly\nTXN000122,2025-07-16T19:28:15Z,ACC1122,ACC2122,2000.00,USD,debit,Pakistan,Karachi,10.0.0.14,DEV122,1,Geographic Anomaly\nTXN000123,2025-07-17T01:00:00Z,ACC1007,ACC2123,1500.00,CAD,debit,Canada,Toronto,192.168.1.16,DEV007,1,Time Anomaly\nTXN000124,2025-07-17T01:00:15Z,ACC1010,ACC2124,2000.00,CHF,debit,Switzerland,Zurich,192.168.1.19,DEV010,1,Time Anomaly\nTXN000125,2025-07-16T19:29:00Z,ACC1125,ACC2125,60000.00,USD,credit,USA,Orlando,203.0.113.6,DEV125,1,Money Mule Entry\nTXN000126,2025-07-16T19:29:10Z,ACC1125,ACC2126,10000.00,USD,debit,USA,Tampa,203.0.113.6,DEV125,1,Money Mule Outflow\nTXN000127,2025-07-16T19:29:20Z,ACC1125,ACC2127,15000.00,EUR,debit,Greece,Athens,203.0.113.6,DEV125,1,Money Mule Outflow\nTXN000128,2025-07-16T19:29:30Z,ACC1125,ACC2128,12000.00,GBP,debit,Egypt,Cairo,203.0.113.6,DEV125,1,Money Mule Outflow\nTXN000129,2025-07-16T19:29:40Z,ACC1125,ACC2129,20000.00,AUD,debit,Vietnam,Hanoi,203.0.113.6,DEV125,1,Money Mule Outflow\nTXN000130,2025-07-16T19:30:00Z,ACC1130,ACC2130,12000.00,USD,debit,USA,Denver,172.16.0.4,NEWDEV04,1,New Account Anomaly\nTXN000131,2025-07-16T19:30:15Z,ACC1130,ACC2131,6000.00,USD,credit,USA,Denver,172.16.0.4,NEWDEV04,1,New Account Anomaly\nTXN000132,2025-07-16T19:30:30Z,ACC1132,ACC2132,30000.00,SEK,debit,Sweden,Gothenburg,10.0.0.15,DEV132,1,Round Number Transfer\nTXN000133,2025-07-16T19:30:45Z,ACC1133,ACC2133,20000.00,USD,credit,Iran,Tehran,10.0.0.16,DEV133,1,High-Risk Country\nTXN000134,2025-07-16T19:31:00Z,ACC1001,ACC2134,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000135,2025-07-16T19:31:05Z,ACC1001,ACC2135,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000136,2025-07-16T19:31:10Z,ACC1001,ACC2136,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000137,2025-07-16T19:31:15Z,ACC1001,ACC2137,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000138,2025-07-16T19:31:20Z,ACC1001,ACC2138,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000139,2025-07-16T19:32:00Z,ACC1139,ACC2139,80.00,USD,debit,USA,Portland,192.168.1.67,DEV139,0,Legitimate\nTXN000140,2025-07-16T19:32:15Z,ACC1140,ACC2140,15.00,SEK,credit,Sweden,Gavle,192.168.1.68,DEV140,0,Legitimate\nTXN000141,2025-07-16T19:32:30Z,ACC1141,ACC2141,25.00,DKK,debit,Denmark,Randers,192.168.1.69,DEV141,0,Legitimate\nTXN000142,2025-07-16T19:32:45Z,ACC1142,ACC2142,70.00,NOK,credit,Norway,Fredrikstad,192.168.1.70,DEV142,0,Legitimate\nTXN000143,2025-07-16T19:33:00Z,ACC1143,ACC2143,40.00,GBP,debit,UK,Liverpool,192.168.1.71,DEV143,0,Legitimate\nTXN000144,2025-07-16T19:33:15Z,ACC1144,ACC2144,100.00,USD,credit,USA,Charlotte,192.168.1.72,DEV144,0,Legitimate\nTXN000145,2025-07-16T19:33:30Z,ACC1145,ACC2145,300.00,CAD,debit,Canada,Winnipeg,192.168.1.73,DEV145,0,Legitimate\nTXN000146,2025-07-16T19:33:45Z,ACC1146,ACC2146,80.00,AUD,credit,Australia,Gold Coast,192.168.1.74,DEV146,0,Legitimate\nTXN000147,2025-07-16T19:34:00Z,ACC1147,ACC2147,18.00,NZD,debit,New Zealand,Napier,192.168.1.75,DEV147,0,Legitimate\nTXN000148,2025-07-16T19:34:15Z,ACC1148,ACC2148,30.00,CHF,credit,Switzerland,Lucerne,192.168.1.76,DEV148,0,Legitimate\nTXN000149,2025-07-16T19:34:30Z,ACC1149,ACC2149,3.00,EUR,debit,Sweden,Karlstad,192.168.1.77,DEV149,0,Legitimate\nTXN000150,2025-07-16T19:34:45Z,ACC1150,ACC2150,90.00,SEK,credit,Sweden,Vaxjo,192.168.1.78,DEV150,0,Legitimate\nTXN000151,2025-07-16T19:35:00Z,ACC1004,ACC2151,20000.00,NOK,debit,Norway,Oslo,192.168.1.13,DEV004,1,High-Value Single\nTXN000152,2025-07-16T19:35:30Z,ACC1152,ACC2152,0.60,CAD,debit,Canada,Vancouver,203.0.113.7,DEV152,1,Rapid Small Transactions\nTXN000153,2025-07-16T19:35:35Z,ACC1152,ACC2153,0.90,CAD,debit,Canada,Vancouver,203.0.113.7,DEV152,1,Rapid Small Transactions\nTXN000154,2025-07-16T19:35:40Z,ACC1152,ACC2154,0.40,CAD,debit,Canada,Vancouver,203.0.113.7,DEV152,1,Rapid Small Transactions\nTXN000155,2025-07-16T19:35:45Z,ACC1152,ACC2155,1.50,CAD,debit,Canada,Vancouver,203.0.113.7,DEV152,1,Rapid Small Transactions\nTXN000156,2025-07-16T19:36:00Z,ACC1156,ACC2156,800.00,USD,debit,Ukraine,Kyiv,10.0.0.17,DEV156,1,Geographic Anomaly\nTXN000157,2025-07-16T19:36:15Z,ACC1157,ACC2157,1500.00,RUB,debit,Belarus,Minsk,10.0.0.18,DEV157,1,Geographic Anomaly\nTXN000158,2025-07-17T02:00:00Z,ACC1008,ACC2158,1000.00,AUD,debit,Australia,Sydney,192.168.1.17,DEV008,1,Time Anomaly\nTXN000159,2025-07-17T02:00:15Z,ACC1011,ACC2159,2000.00,EUR,debit,Sweden,Trollhattan,192.168.1.20,DEV011,1,Time Anomaly\nTXN000160,2025-07-16T19:37:00Z,ACC1160,ACC2160,90000.00,USD,credit,USA,Chicago,203.0.113.8,DEV160,1,Money Mule Entry\nTXN000161,2025-07-16T19:37:10Z,ACC1160,ACC2161,18000.00,USD,debit,USA,New Orleans,203.0.113.8,DEV160,1,Money Mule Outflow\nTXN000162,2025-07-16T19:37:20Z,ACC1160,ACC2162,22000.00,GBP,debit,UK,Cardiff,203.0.113.8,DEV160,1,Money Mule Outflow\nTXN000163,2025-07-16T19:37:30Z,ACC1160,ACC2163,16000.00,AUD,debit,New Zealand,Wellington,203.0.113.8,DEV160,1,Money Mule Outflow\nTXN000164,2025-07-16T19:37:40Z,ACC1160,ACC2164,28000.00,JPY,debit,Japan,Tokyo,203.0.113.8,DEV160,1,Money Mule Outflow\nTXN000165,2025-07-16T19:38:00Z,ACC1165,ACC2165,30000.00,USD,debit,USA,Houston,172.16.0.5,NEWDEV05,1,New Account Anomaly\nTXN000166,2025-07-16T19:38:15Z,ACC1165,ACC2166,12000.00,USD,credit,USA,Houston,172.16.0.5,NEWDEV05,1,New Account Anomaly\nTXN000167,2025-07-16T19:38:30Z,ACC1167,ACC2167,80000.00,NOK,debit,Norway,Oslo,10.0.0.19,DEV167,1,Round Number Transfer\nTXN000168,2025-07-16T19:38:45Z,ACC1168,ACC2168,50000.00,EUR,credit,Syria,Damascus,10.0.0.20,DEV168,1,High-Risk Country\nTXN000169,2025-07-16T19:39:00Z,ACC1002,ACC2169,1.00,SEK,debit,Sweden,Gothenburg,192.168.1.11,DEV002,1,Rapid Small Transactions\nTXN000170,2025-07-16T19:39:05Z,ACC1002,ACC2170,1.00,SEK,debit,Sweden,Gothenburg,192.168.1.11,DEV002,1,Rapid Small Transactions\nTXN000171,2025-07-16T19:39:10Z,ACC1002,ACC2171,1.00,SEK,debit,Sweden,Gothenburg,192.168.1.11,DEV002,1,Rapid Small Transactions\nTXN000172,2025-07-16T19:39:15Z,ACC1002,ACC2172,1.00,SEK,debit,Sweden,Gothenburg,192.168.1.11,DEV002,1,Rapid Small Transactions\nTXN000173,2025-07-16T19:39:20Z,ACC1002,ACC2173,1.00,SEK,debit,Sweden,Gothenburg,192.168.1.11,DEV002,1,Rapid Small Transactions\nTXN000174,2025-07-16T19:40:00Z,ACC1174,ACC2174,60.00,USD,debit,USA,Detroit,192.168.1.79,DEV174,0,Legitimate\nTXN000175,2025-07-16T19:40:15Z,ACC1175,ACC2175,8.00,DKK,credit,Denmark,Aalborg,192.168.1.80,DEV175,0,Legitimate\nTXN000176,2025-07-16T19:40:30Z,ACC1176,ACC2176,12.00,NOK,debit,Norway,Sandnes,192.168.1.81,DEV176,0,Legitimate\nTXN000177,2025-07-16T19:40:45Z,ACC1177,ACC2177,20.00,GBP,credit,UK,Leeds,192.168.1.82,DEV177,0,Legitimate\nTXN000178,2025-07-16T19:41:00Z,ACC1178,ACC2178,45.00,USD,debit,USA,Jacksonville,192.168.1.83,DEV178,0,Legitimate\nTXN000179,2025-07-16T19:41:15Z,ACC1179,ACC2179,180.00,CAD,credit,Canada,Ottawa,192.168.1.84,DEV179,0,Legitimate\nTXN000180,2025-07-16T19:41:30Z,ACC1180,ACC2180,55.00,AUD,debit,Australia,Brisbane,192.168.1.85,DEV180,0,Legitimate\nTXN000181,2025-07-16T19:41:45Z,ACC1181,ACC2181,12.00,NZD,credit,New Zealand,Tauranga,192.168.1.86,DEV181,0,Legitimate\nTXN000182,2025-07-16T19:42:00Z,ACC1182,ACC2182,20.00,CHF,debit,Switzerland,St. Gallen,192.168.1.87,DEV182,0,Legitimate\nTXN000183,2025-07-16T19:42:15Z,ACC1183,ACC2183,1.50,EUR,credit,Sweden,Vasteras,192.168.1.88,DEV183,0,Legitimate\nTXN000184,2025-07-16T19:42:30Z,ACC1184,ACC2184,60.00,SEK,debit,Sweden,Umea,192.168.1.89,DEV184,0,Legitimate\nTXN000185,2025-07-16T19:42:45Z,ACC1185,ACC2185,15000.00,USD,debit,USA,San Diego,192.168.1.90,DEV185,1,High-Value Single\nTXN000186,2025-07-16T19:43:00Z,ACC1003,ACC2186,1.00,DKK,debit,Denmark,Copenhagen,192.168.1.12,DEV003,1,Rapid Small Transactions\nTXN000187,2025-07-16T19:43:05Z,ACC1003,ACC2187,1.00,DKK,debit,Denmark,Copenhagen,192.168.1.12,DEV003,1,Rapid Small Transactions\nTXN000188,2025-07-16T19:43:10Z,ACC1003,ACC2188,1.00,DKK,debit,Denmark,Copenhagen,192.168.1.12,DEV003,1,Rapid Small Transactions\nTXN000189,2025-07-16T19:43:15Z,ACC1003,ACC2189,1.00,DKK,debit,Denmark,Copenhagen,192.168.1.12,DEV003,1,Rapid Small Transactions\nTXN000190,2025-07-16T19:43:20Z,ACC1003,ACC2190,1.00,DKK,debit,Denmark,Copenhagen,192.168.1.12,DEV003,1,Rapid Small Transactions\nTXN000191,2025-07-16T19:44:00Z,ACC1191,ACC2191,2000.00,USD,debit,Malaysia,Kuala Lumpur,10.0.0.21,DEV191,1,Geographic Anomaly\nTXN000192,2025-07-16T19:44:15Z,ACC1192,ACC2192,3000.00,IDR,debit,Indonesia,Jakarta,10.0.0.22,DEV192,1,Geographic Anomaly\nTXN000193,2025-07-17T05:00:00Z,ACC1009,ACC2193,700.00,NZD,debit,New Zealand,Wellington,192.168.1.18,DEV009,1,Time Anomaly\nTXN000194,2025-07-17T05:00:15Z,ACC1012,ACC2194,2800.00,SEK,debit,Sweden,Stockholm,192.168.1.21,DEV012,1,Time Anomaly\nTXN000195,2025-07-16T19:45:00Z,ACC1195,ACC2195,120000.00,USD,credit,USA,Las Vegas,203.0.113.9,DEV195,1,Money Mule Entry\nTXN000196,2025-07-16T19:45:10Z,ACC1195,ACC2196,25000.00,USD,debit,USA,Miami,203.0.113.9,DEV195,1,Money Mule Outflow\nTXN000197,2025-07-16T19:45:20Z,ACC1195,ACC2197,35000.00,EUR,debit,Netherlands,Amsterdam,203.0.113.9,DEV195,1,Money Mule Outflow\nTXN000198,2025-07-16T19:45:30Z,ACC1195,ACC2198,20000.00,GBP,debit,South Africa,Cape Town,203.0.113.9,DEV195,1,Money Mule Outflow\nTXN000199,2025-07-16T19:45:40Z,ACC1195,ACC2199,30000.00,AUD,debit,India,Mumbai,203.0.113.9,DEV195,1,Money Mule Outflow\nTXN000200,2025-07-16T19:46:00Z,ACC1200,ACC2200,8000.00,USD,debit,USA,Atlanta,172.16.0.6,NEWDEV06,1,New Account Anomaly\nTXN000201,2025-07-16T19:46:15Z,ACC1200,ACC2201,3000.00,USD,credit,USA,Atlanta,172.16.0.6,NEWDEV06,1,New Account Anomaly\nTXN000202,2025-07-16T19:46:30Z,ACC1202,ACC2202,100000.00,DKK,debit,Denmark,Copenhagen,10.0.0.23,DEV202,1,Round Number Transfer\nTXN000203,2025-07-16T19:46:45Z,ACC1203,ACC2203,60000.00,USD,credit,Afghanistan,Kabul,10.0.0.24,DEV203,1,High-Risk Country\nTXN000204,2025-07-16T19:47:00Z,ACC1004,ACC2204,1.00,NOK,debit,Norway,Oslo,192.168.1.13,DEV004,1,Rapid Small Transactions\nTXN000205,2025-07-16T19:47:05Z,ACC1004,ACC2205,1.00,NOK,debit,Norway,Oslo,192.168.1.13,DEV004,1,Rapid Small Transactions\nTXN000206,2025-07-16T19:47:10Z,ACC1004,ACC2206,1.00,NOK,debit,Norway,Oslo,192.168.1.13,DEV004,1,Rapid Small Transactions\nTXN000207,2025-07-16T19:47:15Z,ACC1004,ACC2207,1.00,NOK,debit,Norway,Oslo,192.168.1.13,DEV004,1,Rapid Small Transactions\nTXN000208,2025-07-16T19:47:20Z,ACC1004,ACC2208,1.00,NOK,debit,Norway,Oslo,192.168.1.13,DEV004,1,Rapid Small Transactions\nTXN000209,2025-07-16T19:48:00Z,ACC1209,ACC2209,90.00,USD,debit,USA,Denver,192.168.1.91,DEV209,0,Legitimate\nTXN000210,2025-07-16T19:48:15Z,ACC1210,ACC2210,18.00,GBP,credit,UK,Bristol,192.168.1.92,DEV210,0,Legitimate\nTXN000211,2025-07-16T19:48:30Z,ACC1211,ACC2211,30.00,USD,debit,USA,Orlando,192.168.1.93,DEV211,0,Legitimate\nTXN000212,2025-07-16T19:48:45Z,ACC1212,ACC2212,250.00,CAD,credit,Canada,Halifax,192.168.1.94,DEV212,0,Legitimate\nTXN000213,2025-07-16T19:49:00Z,ACC1213,ACC2213,70.00,AUD,debit,Australia,Hobart,192.168.1.95,DEV213,0,Legitimate\nTXN000214,2025-07-16T19:49:15Z,ACC1214,ACC2214,20.00,NZD,credit,New Zealand,Queenstown,192.168.1.96,DEV214,0,Legitimate\nTXN000215,2025-07-16T19:49:30Z,ACC1215,ACC2215,35.00,CHF,debit,Switzerland,Fribourg,192.168.1.97,DEV215,0,Legitimate\nTXN000216,2025-07-16T19:49:45Z,ACC1216,ACC2216,4.00,EUR,credit,Sweden,Malmo,192.168.1.98,DEV216,0,Legitimate\nTXN000217,2025-07-16T19:50:00Z,ACC1217,ACC2217,100.00,SEK,debit,Sweden,Gothenburg,192.168.1.99,DEV217,0,Legitimate\nTXN000218,2025-07-16T19:50:15Z,ACC1218,ACC2218,20000.00,USD,debit,USA,Miami,192.168.1.100,DEV218,1,High-Value Single\nTXN000219,2025-07-16T19:50:45Z,ACC1219,ACC2219,0.30,USD,debit,USA,Miami,203.0.113.10,DEV219,1,Rapid Small Transactions\nTXN000220,2025-07-16T19:50:50Z,ACC1219,ACC2220,0.50,USD,debit,USA,Miami,203.0.113.10,DEV219,1,Rapid Small Transactions\nTXN000221,2025-07-16T19:50:55Z,ACC1219,ACC2221,0.20,USD,debit,USA,Miami,203.0.113.10,DEV219,1,Rapid Small Transactions\nTXN000222,2025-07-16T19:51:00Z,ACC1219,ACC2222,0.80,USD,debit,USA,Miami,203.0.113.10,DEV219,1,Rapid Small Transactions\nTXN000223,2025-07-16T19:51:15Z,ACC1223,ACC2223,3000.00,USD,debit,Thailand,Bangkok,10.0.0.25,DEV223,1,Geographic Anomaly\nTXN000224,2025-07-16T19:51:30Z,ACC1224,ACC2224,4000.00,VND,debit,Myanmar,Yangon,10.0.0.26,DEV224,1,Geographic Anomaly\nTXN000225,2025-07-17T03:30:00Z,ACC1015,ACC2225,900.00,GBP,debit,UK,Manchester,192.168.1.24,DEV015,1,Time Anomaly\nTXN000226,2025-07-17T03:30:15Z,ACC1018,ACC2226,1500.00,AUD,debit,Australia,Melbourne,192.168.1.27,DEV018,1,Time Anomaly\nTXN000227,2025-07-16T19:52:00Z,ACC1227,ACC2227,70000.00,USD,credit,USA,Los Angeles,203.0.113.11,DEV227,1,Money Mule Entry\nTXN000228,2025-07-16T19:52:10Z,ACC1227,ACC2228,14000.00,USD,debit,USA,San Diego,203.0.113.11,DEV227,1,Money Mule Outflow\nTXN000229,2025-07-16T19:52:20Z,ACC1227,ACC2229,18000.00,CAD,debit,Canada,Vancouver,203.0.113.11,DEV227,1,Money Mule Outflow\nTXN000230,2025-07-16T19:52:30Z,ACC1227,ACC2230,13000.00,AUD,debit,Australia,Perth,203.0.113.11,DEV227,1,Money Mule Outflow\nTXN000231,2025-07-16T19:52:40Z,ACC1227,ACC2231,25000.00,NZD,debit,New Zealand,Auckland,203.0.113.11,DEV227,1,Money Mule Outflow\nTXN000232,2025-07-16T19:53:00Z,ACC1232,ACC2232,18000.00,USD,debit,USA,Seattle,172.16.0.7,NEWDEV07,1,New Account Anomaly\nTXN000233,2025-07-16T19:53:15Z,ACC1232,ACC2233,7000.00,USD,credit,USA,Seattle,172.16.0.7,NEWDEV07,1,New Account Anomaly\nTXN000234,2025-07-16T19:53:30Z,ACC1234,ACC2234,40000.00,GBP,debit,UK,London,10.0.0.27,DEV234,1,Round Number Transfer\nTXN000235,2025-07-16T19:53:45Z,ACC1235,ACC2235,25000.00,USD,credit,Yemen,Sanaa,10.0.0.28,DEV235,1,High-Risk Country\nTXN000236,2025-07-16T19:54:00Z,ACC1005,ACC2236,1.00,GBP,debit,UK,London,192.168.1.14,DEV005,1,Rapid Small Transactions\nTXN000237,2025-07-16T19:54:05Z,ACC1005,ACC2237,1.00,GBP,debit,UK,London,192.168.1.14,DEV005,1,Rapid Small Transactions\nTXN000238,2025-07-16T19:54:10Z,ACC1005,ACC2238,1.00,GBP,debit,UK,London,192.168.1.14,DEV005,1,Rapid Small Transactions\nTXN000239,2025-07-16T19:54:15Z,ACC1005,ACC2239,1.00,GBP,debit,UK,London,192.168.1.14,DEV005,1,Rapid Small Transactions\nTXN000240,2025-07-16T19:54:20Z,ACC1005,ACC2240,1.00,GBP,debit,UK,London,192.168.1.14,DEV005,1,Rapid Small Transactions\nTXN000241,2025-07-16T19:55:00Z,ACC1241,ACC2241,70.00,USD,debit,USA,Boston,192.168.1.101,DEV241,0,Legitimate\nTXN000242,2025-07-16T19:55:15Z,ACC1242,ACC2242,10.00,CHF,credit,Switzerland,Geneva,192.168.1.102,DEV242,0,Legitimate\nTXN000243,2025-07-16T19:55:30Z,ACC1243,ACC2243,20.00,EUR,debit,Sweden,Uppsala,192.168.1.103,DEV243,0,Legitimate\nTXN000244,2025-07-16T19:55:45Z,ACC1244,ACC2244,50.00,SEK,credit,Sweden,Lund,192.168.1.104,DEV244,0,Legitimate\nTXN000245,2025-07-16T19:56:00Z,ACC1245,ACC2245,25.00,DKK,debit,Denmark,Roskilde,192.168.1.105,DEV245,0,Legitimate\nTXN000246,2025-07-16T19:56:15Z,ACC1246,ACC2246,120.00,NOK,credit,Norway,Drammen,192.168.1.106,DEV246,0,Legitimate\nTXN000247,2025-07-16T19:56:30Z,ACC1247,ACC2247,45.00,GBP,debit,UK,Sheffield,192.168.1.107,DEV247,0,Legitimate\nTXN000248,2025-07-16T19:56:45Z,ACC1248,ACC2248,90.00,USD,credit,USA,Washington DC,192.168.1.108,DEV248,0,Legitimate\nTXN000249,2025-07-16T19:57:00Z,ACC1249,ACC2249,250.00,CAD,debit,Canada,Victoria,192.168.1.109,DEV249,0,Legitimate\nTXN000250,2025-07-16T19:57:15Z,ACC1250,ACC2250,70.00,AUD,credit,Australia,Darwin,192.168.1.110,DEV250,0,Legitimate\nTXN000251,2025-07-16T19:57:30Z,ACC1251,ACC2251,16.00,NZD,debit,New Zealand,Nelson,192.168.1.111,DEV251,0,Legitimate\nTXN000252,2025-07-16T19:57:45Z,ACC1252,ACC2252,28.00,CHF,credit,Switzerland,Bern,192.168.1.112,DEV252,0,Legitimate\nTXN000253,2025-07-16T19:58:00Z,ACC1253,ACC2253,5.00,EUR,debit,Sweden,Orebro,192.168.1.113,DEV253,0,Legitimate\nTXN000254,2025-07-16T19:58:15Z,ACC1254,ACC2254,110.00,SEK,credit,Sweden,Halmstad,192.168.1.114,DEV254,0,Legitimate\nTXN000255,2025-07-16T19:58:30Z,ACC1255,ACC2255,30000.00,USD,debit,USA,New York,192.168.1.115,DEV255,1,High-Value Single\nTXN000256,2025-07-16T19:59:00Z,ACC1256,ACC2256,0.10,EUR,debit,Sweden,Trollhattan,203.0.113.12,DEV256,1,Rapid Small Transactions\nTXN000257,2025-07-16T19:59:05Z,ACC1256,ACC2257,0.20,EUR,debit,Sweden,Trollhattan,203.0.113.12,DEV256,1,Rapid Small Transactions\nTXN000258,2025-07-16T19:59:10Z,ACC1256,ACC2258,0.15,EUR,debit,Sweden,Trollhattan,203.0.113.12,DEV256,1,Rapid Small Transactions\nTXN000259,2025-07-16T19:59:15Z,ACC1256,ACC2259,0.25,EUR,debit,Sweden,Trollhattan,203.0.113.12,DEV256,1,Rapid Small Transactions\nTXN000260,2025-07-16T19:59:20Z,ACC1256,ACC2260,0.30,EUR,debit,Sweden,Trollhattan,203.0.113.12,DEV256,1,Rapid Small Transactions\nTXN000261,2025-07-16T20:00:00Z,ACC1261,ACC2261,500.00,USD,debit,Venezuela,Caracas,10.0.0.29,DEV261,1,High-Risk Country\nTXN000262,2025-07-16T20:00:15Z,ACC1262,ACC2262,800.00,BRL,debit,Colombia,Bogota,10.0.0.30,DEV262,1,High-Risk Country\nTXN000263,2025-07-17T00:00:00Z,ACC1019,ACC2263,300.00,NZD,debit,New Zealand,Auckland,192.168.1.28,DEV019,1,Time Anomaly\nTXN000264,2025-07-17T00:00:15Z,ACC1020,ACC2264,150.00,CHF,debit,Switzerland,Geneva,192.168.1.29,DEV020,1,Time Anomaly\nTXN000265,2025-07-16T20:01:00Z,ACC1265,ACC2265,150000.00,USD,credit,USA,New York,203.0.113.13,DEV265,1,Money Mule Entry\nTXN000266,2025-07-16T20:01:10Z,ACC1265,ACC2266,30000.00,USD,debit,USA,Philadelphia,203.0.113.13,DEV265,1,Money Mule Outflow\nTXN000267,2025-07-16T20:01:20Z,ACC1265,ACC2267,40000.00,EUR,debit,Portugal,Lisbon,203.0.113.13,DEV265,1,Money Mule Outflow\nTXN000268,2025-07-16T20:01:30Z,ACC1265,ACC2268,25000.00,GBP,debit,Ghana,Accra,203.0.113.13,DEV265,1,Money Mule Outflow\nTXN000269,2025-07-16T20:01:40Z,ACC1265,ACC2269,50000.00,CAD,debit,Mexico,Mexico City,203.0.113.13,DEV265,1,Money Mule Outflow\nTXN000270,2025-07-16T20:02:00Z,ACC1270,ACC2270,10000.00,USD,debit,USA,Austin,172.16.0.8,NEWDEV08,1,New Account Anomaly\nTXN000271,2025-07-16T20:02:15Z,ACC1270,ACC2271,4000.00,USD,credit,USA,Austin,172.16.0.8,NEWDEV08,1,New Account Anomaly\nTXN000272,2025-07-16T20:02:30Z,ACC1272,ACC2272,120000.00,USD,debit,USA,Los Angeles,10.0.0.31,DEV272,1,Round Number Transfer\nTXN000273,2025-07-16T20:02:45Z,ACC1273,ACC2273,75000.00,USD,credit,Syria,Aleppo,10.0.0.32,DEV273,1,High-Risk Country\nTXN000274,2025-07-16T20:03:00Z,ACC1006,ACC2274,1.00,USD,debit,USA,New York,192.168.1.15,DEV006,1,Rapid Small Transactions\nTXN000275,2025-07-16T20:03:05Z,ACC1006,ACC2275,1.00,USD,debit,USA,New York,192.168.1.15,DEV006,1,Rapid Small Transactions\nTXN000276,2025-07-16T20:03:10Z,ACC1006,ACC2276,1.00,USD,debit,USA,New York,192.168.1.15,DEV006,1,Rapid Small Transactions\nTXN000277,2025-07-16T20:03:15Z,ACC1006,ACC2277,1.00,USD,debit,USA,New York,192.168.1.15,DEV006,1,Rapid Small Transactions\nTXN000278,2025-07-16T20:03:20Z,ACC1006,ACC2278,1.00,USD,debit,USA,New York,192.168.1.15,DEV006,1,Rapid Small Transactions\nTXN000279,2025-07-16T20:04:00Z,ACC1279,ACC2279,85.00,USD,debit,USA,Columbus,192.168.1.116,DEV279,0,Legitimate\nTXN000280,2025-07-16T20:04:15Z,ACC1280,ACC2280,12.00,EUR,credit,Ireland,Dublin,192.168.1.117,DEV280,0,Legitimate\nTXN000281,2025-07-16T20:04:30Z,ACC1281,ACC2281,22.00,SEK,debit,Sweden,Gotland,192.168.1.118,DEV281,0,Legitimate\nTXN000282,2025-07-16T20:04:45Z,ACC1282,ACC2282,65.00,NOK,credit,Norway,Arendal,192.168.1.119,DEV282,0,Legitimate\nTXN000283,2025-07-16T20:05:00Z,ACC1283,ACC2283,38.00,GBP,debit,UK,Newcastle,192.168.1.120,DEV283,0,Legitimate\nTXN000284,2025-07-16T20:05:15Z,ACC1284,ACC2284,95.00,USD,credit,USA,Indianapolis,192.168.1.121,DEV284,0,Legitimate\nTXN000285,2025-07-16T20:05:30Z,ACC1285,ACC2285,280.00,CAD,debit,Canada,Saskatoon,192.168.1.122,DEV285,0,Legitimate\nTXN000286,2025-07-16T20:05:45Z,ACC1286,ACC2286,75.00,AUD,credit,Australia,Canberra,192.168.1.123,DEV286,0,Legitimate\nTXN000287,2025-07-16T20:06:00Z,ACC1287,ACC2287,14.00,NZD,debit,New Zealand,Rotorua,192.168.1.124,DEV287,0,Legitimate\nTXN000288,2025-07-16T20:06:15Z,ACC1288,ACC2288,26.00,CHF,credit,Switzerland,Lugano,192.168.1.125,DEV288,0,Legitimate\nTXN000289,2025-07-16T20:06:30Z,ACC1289,ACC2289,6.00,EUR,debit,Sweden,Gavle,192.168.1.126,DEV289,0,Legitimate\nTXN000290,2025-07-16T20:06:45Z,ACC1290,ACC2290,105.00,SEK,credit,Sweden,Sundsvall,192.168.1.127,DEV290,0,Legitimate\nTXN000291,2025-07-16T20:07:00Z,ACC1291,ACC2291,40000.00,USD,debit,USA,Miami,192.168.1.128,DEV291,1,High-Value Single\nTXN000292,2025-07-16T20:07:30Z,ACC1292,ACC2292,0.70,CAD,debit,Canada,Montreal,203.0.113.14,DEV292,1,Rapid Small Transactions\nTXN000293,2025-07-16T20:07:35Z,ACC1292,ACC2293,0.80,CAD,debit,Canada,Montreal,203.0.113.14,DEV292,1,Rapid Small Transactions\nTXN000294,2025-07-16T20:07:40Z,ACC1292,ACC2294,0.60,CAD,debit,Canada,Montreal,203.0.113.14,DEV292,1,Rapid Small Transactions\nTXN000295,2025-07-16T20:07:45Z,ACC1292,ACC2295,0.95,CAD,debit,Canada,Montreal,203.0.113.14,DEV292,1,Rapid Small Transactions\nTXN000296,2025-07-16T20:08:00Z,ACC1296,ACC2296,1500.00,USD,debit,Turkey,Antalya,10.0.0.33,DEV296,1,High-Risk Country\nTXN000297,2025-07-16T20:08:15Z,ACC1297,ACC2297,2500.00,RUB,debit,Georgia,Tbilisi,10.0.0.34,DEV297,1,Geographic Anomaly\nTXN000298,2025-07-17T04:00:00Z,ACC1007,ACC2298,1200.00,CAD,debit,Canada,Toronto,192.168.1.16,DEV007,1,Time Anomaly\nTXN000299,2025-07-17T04:00:15Z,ACC1014,ACC2299,3500.00,NOK,debit,Norway,Bergen,192.168.1.23,DEV014,1,Time Anomaly\nTXN000300,2025-07-16T20:09:00Z,ACC1300,ACC2300,200000.00,USD,credit,USA,Los Angeles,203.0.113.15,DEV300,1,Money Mule Entry`, [\n\t{\n\t\tcolumn_name: \"from_account\",\n\t\tcolumn_id: \"from_account\",\n\t\tnode_type: \"c4rdx1offhvxrtl\"\n\t},\n\t{\n\t\tcolumn_name: \"to_account\",\n\t\tcolumn_id: \"to_account\",\n\t\tnode_type: \"c4rdx1offhvxrtl\"\n\t},\n])\n\t\t\tdocument.addEventListener(\"DOMContentLoaded\", () => {\n\t\t\t\tdriver.run(store).then(graph => {\n\t\t\t\t\twindow.driver = driver\n\t\t\t\t\twindow.addEventListener(\"keydown\", async (e) => {\n\t\t\t\t\t\tif (e.key == \"h\") {\n\t\t\t\t\t\t\tawait driver.graph.import(\n\t\t\t\t\t\t\t\tcsv_data,\n\t\t\t\t\t\t\t\t[\n\t\t\t\t\t\t\t\t\t{\n\t\t\t\t\t\t\t\t\t\tsource_column: \"from_account\",\n\t\t\t\t\t\t\t\t\t\ttarget_column: \"to_account\",\n\t\t\t\t\t\t\t\t\t\tedge_type: \"k33u61b74vg3888\"\n\t\t\t\t\t\t\t\t\t},\n\t\t\t\t\t\t\t\t]\n\t\t\t\t\t\t\t);\n\t\t\t\t\t\t\tdriver.graph.store.init(driver.graph)\n\t\t\t\t\t\t}\n\t\t\t\t\t})\n\t\t\t\t})\n\t\t\t})\n\t\t</script></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 37, ")\n\t\t\tconst live = new LiveSession(store, driver)\n\t\t\tconst csv_data = new CSVWriter(`transaction_id,timestamp,from_account,to_account,amount,currency,transaction_type,location_country,location_city,ip_address,device_id,is_flagged_for_fraud,fraud_pattern_type\nTXN000001,2025-07-16T19:00:00Z,ACC1001,ACC2001,50.25,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,0,Legitimate\nTXN000002,2025-07-16T19:00:15Z,ACC1002,ACC2002,120.00,SEK,credit,Sweden,Gothenburg,192.168.1.11,DEV002,0,Legitimate\nTXN000003,2025-07-16T19:00:30Z,ACC1003,ACC2003,30.50,DKK,debit,Denmark,Copenhagen,192.168.1.12,DEV003,0,Legitimate\nTXN000004,2025-07-16T19:00:45Z,ACC1004,ACC2004,200.75,NOK,credit,Norway,Oslo,192.168.1.13,DEV004,0,Legitimate\nTXN000005,2025-07-16T19:01:00Z,ACC1005,ACC2005,80.10,GBP,debit,UK,London,192.168.1.14,DEV005,0,Legitimate\nTXN000006,2025-07-16T19:01:15Z,ACC1006,ACC2006,15.99,USD,credit,USA,New York,192.168.1.15,DEV006,0,Legitimate\nTXN000007,2025-07-16T19:01:30Z,ACC1007,ACC2007,75.00,CAD,debit,Canada,Toronto,192.168.1.16,DEV007,0,Legitimate\nTXN000008,2025-07-16T19:01:45Z,ACC1008,ACC2008,180.30,AUD,credit,Australia,Sydney,192.168.1.17,DEV008,0,Legitimate\nTXN000009,2025-07-16T19:02:00Z,ACC1009,ACC2009,45.60,NZD,debit,New Zealand,Wellington,192.168.1.18,DEV009,0,Legitimate\nTXN000010,2025-07-16T19:02:15Z,ACC1010,ACC2010,99.99,CHF,credit,Switzerland,Zurich,192.168.1.19,DEV010,0,Legitimate\nTXN000011,2025-07-16T19:02:30Z,ACC1011,ACC2011,10.00,EUR,debit,Sweden,Trollhattan,192.168.1.20,DEV011,0,Legitimate\nTXN000012,2025-07-16T19:02:45Z,ACC1012,ACC2012,250.00,SEK,credit,Sweden,Stockholm,192.168.1.21,DEV012,0,Legitimate\nTXN000013,2025-07-16T19:03:00Z,ACC1013,ACC2013,60.00,DKK,debit,Denmark,Aarhus,192.168.1.22,DEV013,0,Legitimate\nTXN000014,2025-07-16T19:03:15Z,ACC1014,ACC2014,130.50,NOK,credit,Norway,Bergen,192.168.1.23,DEV014,0,Legitimate\nTXN000015,2025-07-16T19:03:30Z,ACC1015,ACC2015,25.75,GBP,debit,UK,Manchester,192.168.1.24,DEV015,0,Legitimate\nTXN000016,2025-07-16T19:03:45Z,ACC1016,ACC2016,190.00,USD,credit,USA,Los Angeles,192.168.1.25,DEV016,0,Legitimate\nTXN000017,2025-07-16T19:04:00Z,ACC1017,ACC2017,70.20,CAD,debit,Canada,Vancouver,192.168.1.26,DEV017,0,Legitimate\nTXN000018,2025-07-16T19:04:15Z,ACC1018,ACC2018,110.40,AUD,credit,Australia,Melbourne,192.168.1.27,DEV018,0,Legitimate\nTXN000019,2025-07-16T19:04:30Z,ACC1019,ACC2019,55.00,NZD,debit,New Zealand,Auckland,192.168.1.28,DEV019,0,Legitimate\nTXN000020,2025-07-16T19:04:45Z,ACC1020,ACC2020,85.80,CHF,credit,Switzerland,Geneva,192.168.1.29,DEV020,0,Legitimate\nTXN000021,2025-07-16T19:05:00Z,ACC1001,ACC2021,15000.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,High-Value Single\nTXN000022,2025-07-16T19:05:30Z,ACC1022,ACC2022,0.85,USD,debit,USA,Miami,203.0.113.1,DEV022,1,Rapid Small Transactions\nTXN000023,2025-07-16T19:05:35Z,ACC1022,ACC2023,1.20,USD,debit,USA,Miami,203.0.113.1,DEV022,1,Rapid Small Transactions\nTXN000024,2025-07-16T19:05:40Z,ACC1022,ACC2024,0.99,USD,debit,USA,Miami,203.0.113.1,DEV022,1,Rapid Small Transactions\nTXN000025,2025-07-16T19:05:45Z,ACC1022,ACC2025,2.50,USD,debit,USA,Miami,203.0.113.1,DEV022,1,Rapid Small Transactions\nTXN000026,2025-07-16T19:06:00Z,ACC1026,ACC2026,500.00,USD,debit,Nigeria,Lagos,10.0.0.1,DEV026,1,Geographic Anomaly\nTXN000027,2025-07-16T19:06:15Z,ACC1027,ACC2027,1000.00,EUR,debit,Russia,Moscow,10.0.0.2,DEV027,1,Geographic Anomaly\nTXN000028,2025-07-17T03:00:00Z,ACC1001,ACC2028,2500.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Time Anomaly\nTXN000029,2025-07-17T03:00:15Z,ACC1004,ACC2029,5000.00,NOK,debit,Norway,Oslo,192.168.1.13,DEV004,1,Time Anomaly\nTXN000030,2025-07-16T19:07:00Z,ACC1030,ACC2030,50000.00,USD,credit,USA,Miami,203.0.113.2,DEV030,1,Money Mule Entry\nTXN000031,2025-07-16T19:07:10Z,ACC1030,ACC2031,9800.00,USD,debit,USA,New York,203.0.113.2,DEV030,1,Money Mule Outflow\nTXN000032,2025-07-16T19:07:20Z,ACC1030,ACC2032,12000.00,EUR,debit,Germany,Berlin,203.0.113.2,DEV030,1,Money Mule Outflow\nTXN000033,2025-07-16T19:07:30Z,ACC1030,ACC2033,7500.00,GBP,debit,UK,London,203.0.113.2,DEV030,1,Money Mule Outflow\nTXN000034,2025-07-16T19:07:40Z,ACC1030,ACC2034,15000.00,CAD,debit,Canada,Montreal,203.0.113.2,DEV030,1,Money Mule Outflow\nTXN000035,2025-07-16T19:08:00Z,ACC1035,ACC2035,10000.00,USD,debit,USA,New York,172.16.0.1,NEWDEV01,1,New Account Anomaly\nTXN000036,2025-07-16T19:08:15Z,ACC1035,ACC2036,5000.00,USD,credit,USA,New York,172.16.0.1,NEWDEV01,1,New Account Anomaly\nTXN000037,2025-07-16T19:08:30Z,ACC1037,ACC2037,20000.00,EUR,debit,Sweden,Stockholm,10.0.0.3,DEV037,1,Round Number Transfer\nTXN000038,2025-07-16T19:08:45Z,ACC1038,ACC2038,15000.00,USD,credit,UAE,Dubai,10.0.0.4,DEV038,1,High-Risk Country\nTXN000039,2025-07-16T19:09:00Z,ACC1039,ACC2039,500.00,USD,debit,USA,Chicago,192.168.1.30,DEV039,0,Legitimate\nTXN000040,2025-07-16T19:09:15Z,ACC1040,ACC2040,10.00,EUR,credit,France,Paris,192.168.1.31,DEV040,0,Legitimate\nTXN000041,2025-07-16T19:09:30Z,ACC1041,ACC2041,75.00,SEK,debit,Sweden,Malmo,192.168.1.32,DEV041,0,Legitimate\nTXN000042,2025-07-16T19:09:45Z,ACC1042,ACC2042,300.00,NOK,credit,Norway,Trondheim,192.168.1.33,DEV042,0,Legitimate\nTXN000043,2025-07-16T19:10:00Z,ACC1043,ACC2043,90.50,GBP,debit,UK,Birmingham,192.168.1.34,DEV043,0,Legitimate\nTXN000044,2025-07-16T19:10:15Z,ACC1044,ACC2044,25.00,USD,credit,USA,Houston,192.168.1.35,DEV044,0,Legitimate\nTXN000045,2025-07-16T19:10:30Z,ACC1045,ACC2045,150.00,CAD,debit,Canada,Calgary,192.168.1.36,DEV045,0,Legitimate\nTXN000046,2025-07-16T19:10:45Z,ACC1046,ACC2046,50.00,AUD,credit,Australia,Perth,192.168.1.37,DEV046,0,Legitimate\nTXN000047,2025-07-16T19:11:00Z,ACC1047,ACC2047,20.00,NZD,debit,New Zealand,Christchurch,192.168.1.38,DEV047,0,Legitimate\nTXN000048,2025-07-16T19:11:15Z,ACC1048,ACC2048,40.00,CHF,credit,Switzerland,Basel,192.168.1.39,DEV048,0,Legitimate\nTXN000049,2025-07-16T19:11:30Z,ACC1049,ACC2049,5.00,EUR,debit,Sweden,Uppsala,192.168.1.40,DEV049,0,Legitimate\nTXN000050,2025-07-16T19:11:45Z,ACC1050,ACC2050,100.00,SEK,credit,Sweden,Lund,192.168.1.41,DEV050,0,Legitimate\nTXN000051,2025-07-16T19:12:00Z,ACC1001,ACC2051,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000052,2025-07-16T19:12:05Z,ACC1001,ACC2052,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000053,2025-07-16T19:12:10Z,ACC1001,ACC2053,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000054,2025-07-16T19:12:15Z,ACC1001,ACC2054,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000055,2025-07-16T19:12:20Z,ACC1001,ACC2055,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000056,2025-07-16T19:13:00Z,ACC1056,ACC2056,7500.00,USD,debit,Brazil,Rio de Janeiro,10.0.0.5,DEV056,1,Geographic Anomaly\nTXN000057,2025-07-16T19:13:15Z,ACC1057,ACC2057,12000.00,JPY,debit,China,Shanghai,10.0.0.6,DEV057,1,Geographic Anomaly\nTXN000058,2025-07-17T04:30:00Z,ACC1002,ACC2058,800.00,SEK,debit,Sweden,Gothenburg,192.168.1.11,DEV002,1,Time Anomaly\nTXN000059,2025-07-17T04:30:15Z,ACC1005,ACC2059,3000.00,GBP,debit,UK,London,192.168.1.14,DEV005,1,Time Anomaly\nTXN000060,2025-07-16T19:14:00Z,ACC1060,ACC2060,80000.00,USD,credit,USA,Dallas,203.0.113.3,DEV060,1,Money Mule Entry\nTXN000061,2025-07-16T19:14:10Z,ACC1060,ACC2061,15000.00,USD,debit,USA,Houston,203.0.113.3,DEV060,1,Money Mule Outflow\nTXN000062,2025-07-16T19:14:20Z,ACC1060,ACC2062,20000.00,AUD,debit,Australia,Sydney,203.0.113.3,DEV060,1,Money Mule Outflow\nTXN000063,2025-07-16T19:14:30Z,ACC1060,ACC2063,18000.00,NZD,debit,New Zealand,Auckland,203.0.113.3,DEV060,1,Money Mule Outflow\nTXN000064,2025-07-16T19:14:40Z,ACC1060,ACC2064,25000.00,SGD,debit,Singapore,Singapore,203.0.113.3,DEV060,1,Money Mule Outflow\nTXN000065,2025-07-16T19:15:00Z,ACC1065,ACC2065,25000.00,USD,debit,USA,Chicago,172.16.0.2,NEWDEV02,1,New Account Anomaly\nTXN000066,2025-07-16T19:15:15Z,ACC1065,ACC2066,10000.00,USD,credit,USA,Chicago,172.16.0.2,NEWDEV02,1,New Account Anomaly\nTXN000067,2025-07-16T19:15:30Z,ACC1067,ACC2067,50000.00,EUR,debit,Germany,Frankfurt,10.0.0.7,DEV067,1,Round Number Transfer\nTXN000068,2025-07-16T19:15:45Z,ACC1068,ACC2068,30000.00,GBP,credit,Turkey,Istanbul,10.0.0.8,DEV068,1,High-Risk Country\nTXN000069,2025-07-16T19:16:00Z,ACC1001,ACC2069,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000070,2025-07-16T19:16:05Z,ACC1001,ACC2070,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000071,2025-07-16T19:16:10Z,ACC1001,ACC2071,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000072,2025-07-16T19:16:15Z,ACC1001,ACC2072,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000073,2025-07-16T19:16:20Z,ACC1001,ACC2073,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000074,2025-07-16T19:17:00Z,ACC1074,ACC2074,100.00,USD,debit,USA,Orlando,192.168.1.42,DEV074,0,Legitimate\nTXN000075,2025-07-16T19:17:15Z,ACC1075,ACC2075,20.00,SEK,credit,Sweden,Vasteras,192.168.1.43,DEV075,0,Legitimate\nTXN000076,2025-07-16T19:17:30Z,ACC1076,ACC2076,50.00,DKK,debit,Denmark,Odense,192.168.1.44,DEV076,0,Legitimate\nTXN000077,2025-07-16T19:17:45Z,ACC1077,ACC2077,100.00,NOK,credit,Norway,Stavanger,192.168.1.45,DEV077,0,Legitimate\nTXN000078,2025-07-16T19:18:00Z,ACC1078,ACC2078,35.00,GBP,debit,UK,Glasgow,192.168.1.46,DEV078,0,Legitimate\nTXN000079,2025-07-16T19:18:15Z,ACC1079,ACC2079,80.00,USD,credit,USA,Phoenix,192.168.1.47,DEV079,0,Legitimate\nTXN000080,2025-07-16T19:18:30Z,ACC1080,ACC2080,200.00,CAD,debit,Canada,Edmonton,192.168.1.48,DEV080,0,Legitimate\nTXN000081,2025-07-16T19:18:45Z,ACC1081,ACC2081,60.00,AUD,credit,Australia,Adelaide,192.168.1.49,DEV081,0,Legitimate\nTXN000082,2025-07-16T19:19:00Z,ACC1082,ACC2082,15.00,NZD,debit,New Zealand,Dunedin,192.168.1.50,DEV082,0,Legitimate\nTXN000083,2025-07-16T19:19:15Z,ACC1083,ACC2083,25.00,CHF,credit,Switzerland,Bern,192.168.1.51,DEV083,0,Legitimate\nTXN000084,2025-07-16T19:19:30Z,ACC1084,ACC2084,5.00,EUR,debit,Sweden,Linkoping,192.168.1.52,DEV084,0,Legitimate\nTXN000085,2025-07-16T19:19:45Z,ACC1085,ACC2085,120.00,SEK,credit,Sweden,Helsingborg,192.168.1.53,DEV085,0,Legitimate\nTXN000086,2025-07-16T19:20:00Z,ACC1086,ACC2086,7500.00,EUR,debit,Latvia,Riga,10.0.0.9,DEV086,1,Geographic Anomaly\nTXN000087,2025-07-16T19:20:15Z,ACC1087,ACC2087,15000.00,RUB,debit,Kazakhstan,Nur-Sultan,10.0.0.10,DEV087,1,High-Risk Country\nTXN000088,2025-07-17T00:30:00Z,ACC1003,ACC2088,600.00,DKK,debit,Denmark,Copenhagen,192.168.1.12,DEV003,1,Time Anomaly\nTXN000089,2025-07-17T00:30:15Z,ACC1006,ACC2089,1200.00,USD,debit,USA,New York,192.168.1.15,DEV006,1,Time Anomaly\nTXN000090,2025-07-16T19:21:00Z,ACC1090,ACC2090,100000.00,USD,credit,USA,Las Vegas,203.0.113.4,DEV090,1,Money Mule Entry\nTXN000091,2025-07-16T19:21:10Z,ACC1090,ACC2091,20000.00,USD,debit,USA,San Francisco,203.0.113.4,DEV090,1,Money Mule Outflow\nTXN000092,2025-07-16T19:21:20Z,ACC1090,ACC2092,30000.00,EUR,debit,France,Marseille,203.0.113.4,DEV090,1,Money Mule Outflow\nTXN000093,2025-07-16T19:21:30Z,ACC1090,ACC2093,25000.00,GBP,debit,Ireland,Dublin,203.0.113.4,DEV090,1,Money Mule Outflow\nTXN000094,2025-07-16T19:21:40Z,ACC1090,ACC2094,18000.00,CHF,debit,Italy,Rome,203.0.113.4,DEV090,1,Money Mule Outflow\nTXN000095,2025-07-16T19:22:00Z,ACC1095,ACC2095,5000.00,USD,debit,USA,Boston,172.16.0.3,NEWDEV03,1,New Account Anomaly\nTXN000096,2025-07-16T19:22:15Z,ACC1095,ACC2096,2000.00,USD,credit,USA,Boston,172.16.0.3,NEWDEV03,1,New Account Anomaly\nTXN000097,2025-07-16T19:22:30Z,ACC1097,ACC2097,75000.00,EUR,debit,Spain,Madrid,10.0.0.11,DEV097,1,Round Number Transfer\nTXN000098,2025-07-16T19:22:45Z,ACC1098,ACC2098,40000.00,USD,credit,North Korea,Pyongyang,10.0.0.12,DEV098,1,High-Risk Country\nTXN000099,2025-07-16T19:23:00Z,ACC1001,ACC2099,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000100,2025-07-16T19:23:05Z,ACC1001,ACC2100,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000101,2025-07-16T19:23:10Z,ACC1001,ACC2101,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000102,2025-07-16T19:23:15Z,ACC1001,ACC2102,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000103,2025-07-16T19:23:20Z,ACC1001,ACC2103,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000104,2025-07-16T19:24:00Z,ACC1104,ACC2104,50.00,USD,debit,USA,Dallas,192.168.1.54,DEV104,0,Legitimate\nTXN000105,2025-07-16T19:24:15Z,ACC1105,ACC2105,10.00,SEK,credit,Sweden,Orebro,192.168.1.55,DEV105,0,Legitimate\nTXN000106,2025-07-16T19:24:30Z,ACC1106,ACC2106,20.00,DKK,debit,Denmark,Esbjerg,192.168.1.56,DEV106,0,Legitimate\nTXN000107,2025-07-16T19:24:45Z,ACC1107,ACC2107,50.00,NOK,credit,Norway,Kristiansand,192.168.1.57,DEV107,0,Legitimate\nTXN000108,2025-07-16T19:25:00Z,ACC1108,ACC2108,15.00,GBP,debit,UK,Edinburgh,192.168.1.58,DEV108,0,Legitimate\nTXN000109,2025-07-16T19:25:15Z,ACC1109,ACC2109,30.00,USD,credit,USA,San Antonio,192.168.1.59,DEV109,0,Legitimate\nTXN000110,2025-07-16T19:25:30Z,ACC1110,ACC2110,75.00,CAD,debit,Canada,Quebec City,192.168.1.60,DEV110,0,Legitimate\nTXN000111,2025-07-16T19:25:45Z,ACC1111,ACC2111,25.00,AUD,credit,Australia,Canberra,192.168.1.61,DEV111,0,Legitimate\nTXN000112,2025-07-16T19:26:00Z,ACC1112,ACC2112,10.00,NZD,debit,New Zealand,Hamilton,192.168.1.62,DEV112,0,Legitimate\nTXN000113,2025-07-16T19:26:15Z,ACC1113,ACC2113,15.00,CHF,credit,Switzerland,Lausanne,192.168.1.63,DEV113,0,Legitimate\nTXN000114,2025-07-16T19:26:30Z,ACC1114,ACC2114,2.00,EUR,debit,Sweden,Jonkoping,192.168.1.64,DEV114,0,Legitimate\nTXN000115,2025-07-16T19:26:45Z,ACC1115,ACC2115,80.00,SEK,credit,Sweden,Norrkoping,192.168.1.65,DEV115,0,Legitimate\nTXN000116,2025-07-16T19:27:00Z,ACC1116,ACC2116,25000.00,USD,debit,USA,New York,192.168.1.66,DEV116,1,High-Value Single\nTXN000117,2025-07-16T19:27:30Z,ACC1117,ACC2117,0.50,GBP,debit,UK,London,203.0.113.5,DEV117,1,Rapid Small Transactions\nTXN000118,2025-07-16T19:27:35Z,ACC1117,ACC2118,0.75,GBP,debit,UK,London,203.0.113.5,DEV117,1,Rapid Small Transactions\nTXN000119,2025-07-16T19:27:40Z,ACC1117,ACC2119,0.25,GBP,debit,UK,London,203.0.113.5,DEV117,1,Rapid Small Transactions\nTXN000120,2025-07-16T19:27:45Z,ACC1117,ACC2120,1.00,GBP,debit,UK,London,203.0.113.5,DEV117,1,Rapid Small Transactions\nTXN000121,2025-07-16T19:28:00Z,ACC1121,ACC2121,1000.00,EUR,debit,Romania,Bucharest,10.0.0.13,DEV121,1,Geographic Anomaly\nTXN000122,2025-07-16T19:28:15Z,ACC1122,ACC2122,2000.00,USD,debit,Pakistan,Karachi,10.0.0.14,DEV122,1,Geographic Anomaly\nTXN000123,2025-07-17T01:00:00Z,ACC1007,ACC2123,1500.00,CAD,debit,Canada,Toronto,192.168.1.16,DEV007,1,Time Anomaly\nTXN000124,2025-07-17T01:00:15Z,ACC1010,ACC2124,2000.00,CHF,debit,Switzerland,Zurich,192.168.1.19,DEV010,1,Time Anomaly\nTXN000125,2025-07-16T19:29:00Z,ACC1125,ACC2125,60000.00,USD,credit,USA,Orlando,203.0.113.6,DEV125,1,Money Mule Entry\nTXN000126,2025-07-16T19:29:10Z,ACC1125,ACC2126,10000.00,USD,debit,USA,Tampa,203.0.113.6,DEV125,1,Money Mule Outflow\nTXN000127,2025-07-16T19:29:20Z,ACC1125,ACC2127,15000.00,EUR,debit,Greece,Athens,203.0.113.6,DEV125,1,Money Mule Outflow\nTXN000128,2025-07-16T19:29:30Z,ACC1125,ACC2128,12000.00,GBP,debit,Egypt,Cairo,203.0.113.6,DEV125,1,Money Mule Outflow\nTXN000129,2025-07-16T19:29:40Z,ACC1125,ACC2129,20000.00,AUD,debit,Vietnam,Hanoi,203.0.113.6,DEV125,1,Money Mule Outflow\nTXN000130,2025-07-16T19:30:00Z,ACC1130,ACC2130,12000.00,USD,debit,USA,Denver,172.16.0.4,NEWDEV04,1,New Account Anomaly\nTXN000131,2025-07-16T19:30:15Z,ACC1130,ACC2131,6000.00,USD,credit,USA,Denver,172.16.0.4,NEWDEV04,1,New Account Anomaly\nTXN000132,2025-07-16T19:30:30Z,ACC1132,ACC2132,30000.00,SEK,debit,Sweden,Gothenburg,10.0.0.15,DEV132,1,Round Number Transfer\nTXN000133,2025-07-16T19:30:45Z,ACC1133,ACC2133,20000.00,USD,credit,Iran,Tehran,10.0.0.16,DEV133,1,High-Risk Country\nTXN000134,2025-07-16T19:31:00Z,ACC1001,ACC2134,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000135,2025-07-16T19:31:05Z,ACC1001,ACC2135,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000136,2025-07-16T19:31:10Z,ACC1001,ACC2136,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000137,2025-07-16T19:31:15Z,ACC1001,ACC2137,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000138,2025-07-16T19:31:20Z,ACC1001,ACC2138,1.00,EUR,debit,Sweden,Trollhattan,192.168.1.10,DEV001,1,Rapid Small Transactions\nTXN000139,2025-07-16T19:32:00Z,ACC1139,ACC2139,80.00,USD,debit,USA,Portland,192.168.1.67,DEV139,0,Legitimate\nTXN000140,2025-07-16T19:32:15Z,ACC1140,ACC2140,15.00,SEK,credit,Sweden,Gavle,192.168.1.68,DEV140,0,Legitimate\nTXN000141,2025-07-16T19:32:30Z,ACC1141,ACC2141,25.00,DKK,debit,Denmark,Randers,192.168.1.69,DEV141,0,Legitimate\nTXN000142,2025-07-16T19:32:45Z,ACC1142,ACC2142,70.00,NOK,credit,Norway,Fredrikstad,192.168.1.70,DEV142,0,Legitimate\nTXN000143,2025-07-16T19:33:00Z,ACC1143,ACC2143,40.00,GBP,debit,UK,Liverpool,192.168.1.71,DEV143,0,Legitimate\nTXN000144,2025-07-16T19:33:15Z,ACC1144,ACC2144,100.00,USD,credit,USA,Charlotte,192.168.1.72,DEV144,0,Legitimate\nTXN000145,2025-07-16T19:33:30Z,ACC1145,ACC2145,300.00,CAD,debit,Canada,Winnipeg,192.168.1.73,DEV145,0,Legitimate\nTXN000146,2025-07-16T19:33:45Z,ACC1146,ACC2146,80.00,AUD,credit,Australia,Gold Coast,192.168.1.74,DEV146,0,Legitimate\nTXN000147,2025-07-16T19:34:00Z,ACC1147,ACC2147,18.00,NZD,debit,New Zealand,Napier,192.168.1.75,DEV147,0,Legitimate\nTXN000148,2025-07-16T19:34:15Z,ACC1148,ACC2148,30.00,CHF,credit,Switzerland,Lucerne,192.168.1.76,DEV148,0,Legitimate\nTXN000149,2025-07-16T19:34:30Z,ACC1149,ACC2149,3.00,EUR,debit,Sweden,Karlstad,192.168.1.77,DEV149,0,Legitimate\nTXN000150,2025-07-16T19:34:45Z,ACC1150,ACC2150,90.00,SEK,credit,Sweden,Vaxjo,192.168.1.78,DEV150,0,Legitimate\nTXN000151,2025-07-16T19:35:00Z,ACC1004,ACC2151,20000.00,NOK,debit,Norway,Oslo,192.168.1.13,DEV004,1,High-Value Single\nTXN000152,2025-07-16T19:35:30Z,ACC1152,ACC2152,0.60,CAD,debit,Canada,Vancouver,203.0.113.7,DEV152,1,Rapid Small Transactions\nTXN000153,2025-07-16T19:35:35Z,ACC1152,ACC2153,0.90,CAD,debit,Canada,Vancouver,203.0.113.7,DEV152,1,Rapid Small Transactions\nTXN000154,2025-07-16T19:35:40Z,ACC1152,ACC2154,0.40,CAD,debit,Canada,Vancouver,203.0.113.7,DEV152,1,Rapid Small Transactions\nTXN000155,2025-07-16T19:35:45Z,ACC1152,ACC2155,1.50,CAD,debit,Canada,Vancouver,203.0.113.7,DEV152,1,Rapid Small Transactions\nTXN000156,2025-07-16T19:36:00Z,ACC1156,ACC2156,800.00,USD,debit,Ukraine,Kyiv,10.0.0.17,DEV156,1,Geographic Anomaly\nTXN000157,2025-07-16T19:36:15Z,ACC1157,ACC2157,1500.00,RUB,debit,Belarus,Minsk,10.0.0.18,DEV157,1,Geographic Anomaly\nTXN000158,2025-07-17T02:00:00Z,ACC1008,ACC2158,1000.00,AUD,debit,Australia,Sydney,192.168.1.17,DEV008,1,Time Anomaly\nTXN000159,2025-07-17T02:00:15Z,ACC1011,ACC2159,2000.00,EUR,debit,Sweden,Trollhattan,192.168.1.20,DEV011,1,Time Anomaly\nTXN000160,2025-07-16T19:37:00Z,ACC1160,ACC2160,90000.00,USD,credit,USA,Chicago,203.0.113.8,DEV160,1,Money Mule Entry\nTXN000161,2025-07-16T19:37:10Z,ACC1160,ACC2161,18000.00,USD,debit,USA,New Orleans,203.0.113.8,DEV160,1,Money Mule Outflow\nTXN000162,2025-07-16T19:37:20Z,ACC1160,ACC2162,22000.00,GBP,debit,UK,Cardiff,203.0.113.8,DEV160,1,Money Mule Outflow\nTXN000163,2025-07-16T19:37:30Z,ACC1160,ACC2163,16000.00,AUD,debit,New Zealand,Wellington,203.0.113.8,DEV160,1,Money Mule Outflow\nTXN000164,2025-07-16T19:37:40Z,ACC1160,ACC2164,28000.00,JPY,debit,Japan,Tokyo,203.0.113.8,DEV160,1,Money Mule Outflow\nTXN000165,2025-07-16T19:38:00Z,ACC1165,ACC2165,30000.00,USD,debit,USA,Houston,172.16.0.5,NEWDEV05,1,New Account Anomaly\nTXN000166,2025-07-16T19:38:15Z,ACC1165,ACC2166,12000.00,USD,credit,USA,Houston,172.16.0.5,NEWDEV05,1,New Account Anomaly\nTXN000167,2025-07-16T19:38:30Z,ACC1167,ACC2167,80000.00,NOK,debit,Norway,Oslo,10.0.0.19,DEV167,1,Round Number Transfer\nTXN000168,2025-07-16T19:38:45Z,ACC1168,ACC2168,50000.00,EUR,credit,Syria,Damascus,10.0.0.20,DEV168,1,High-Risk Country\nTXN000169,2025-07-16T19:39:00Z,ACC1002,ACC2169,1.00,SEK,debit,Sweden,Gothenburg,192.168.1.11,DEV002,1,Rapid Small Transactions\nTXN000170,2025-07-16T19:39:05Z,ACC1002,ACC2170,1.00,SEK,debit,Sweden,Gothenburg,192.168.1.11,DEV002,1,Rapid Small Transactions\nTXN000171,2025-07-16T19:39:10Z,ACC1002,ACC2171,1.00,SEK,debit,Sweden,Gothenburg,192.168.1.11,DEV002,1,Rapid Small Transactions\nTXN000172,2025-07-16T19:39:15Z,ACC1002,ACC2172,1.00,SEK,debit,Sweden,Gothenburg,192.168.1.11,DEV002,1,Rapid Small Transactions\nTXN000173,2025-07-16T19:39:20Z,ACC1002,ACC2173,1.00,SEK,debit,Sweden,Gothenburg,192.168.1.11,DEV002,1,Rapid Small Transactions\nTXN000174,2025-07-16T19:40:00Z,ACC1174,ACC2174,60.00,USD,debit,USA,Detroit,192.168.1.79,DEV174,0,Legitimate\nTXN000175,2025-07-16T19:40:15Z,ACC1175,ACC2175,8.00,DKK,credit,Denmark,Aalborg,192.168.1.80,DEV175,0,Legitimate\nTXN000176,2025-07-16T19:40:30Z,ACC1176,ACC2176,12.00,NOK,debit,Norway,Sandnes,192.168.1.81,DEV176,0,Legitimate\nTXN000177,2025-07-16T19:40:45Z,ACC1177,ACC2177,20.00,GBP,credit,UK,Leeds,192.168.1.82,DEV177,0,Legitimate\nTXN000178,2025-07-16T19:41:00Z,ACC1178,ACC2178,45.00,USD,debit,USA,Jacksonville,192.168.1.83,DEV178,0,Legitimate\nTXN000179,2025-07-16T19:41:15Z,ACC1179,ACC2179,180.00,CAD,credit,Canada,Ottawa,192.168.1.84,DEV179,0,Legitimate\nTXN000180,2025-07-16T19:41:30Z,ACC1180,ACC2180,55.00,AUD,debit,Australia,Brisbane,192.168.1.85,DEV180,0,Legitimate\nTXN000181,2025-07-16T19:41:45Z,ACC1181,ACC2181,12.00,NZD,credit,New Zealand,Tauranga,192.168.1.86,DEV181,0,Legitimate\nTXN000182,2025-07-16T19:42:00Z,ACC1182,ACC2182,20.00,CHF,debit,Switzerland,St. Gallen,192.168.1.87,DEV182,0,Legitimate\nTXN000183,2025-07-16T19:42:15Z,ACC1183,ACC2183,1.50,EUR,credit,Sweden,Vasteras,192.168.1.88,DEV183,0,Legitimate\nTXN000184,2025-07-16T19:42:30Z,ACC1184,ACC2184,60.00,SEK,debit,Sweden,Umea,192.168.1.89,DEV184,0,Legitimate\nTXN000185,2025-07-16T19:42:45Z,ACC1185,ACC2185,15000.00,USD,debit,USA,San Diego,192.168.1.90,DEV185,1,High-Value Single\nTXN000186,2025-07-16T19:43:00Z,ACC1003,ACC2186,1.00,DKK,debit,Denmark,Copenhagen,192.168.1.12,DEV003,1,Rapid Small Transactions\nTXN000187,2025-07-16T19:43:05Z,ACC1003,ACC2187,1.00,DKK,debit,Denmark,Copenhagen,192.168.1.12,DEV003,1,Rapid Small Transactions\nTXN000188,2025-07-16T19:43:10Z,ACC1003,ACC2188,1.00,DKK,debit,Denmark,Copenhagen,192.168.1.12,DEV003,1,Rapid Small Transactions\nTXN000189,2025-07-16T19:43:15Z,ACC1003,ACC2189,1.00,DKK,debit,Denmark,Copenhagen,192.168.1.12,DEV003,1,Rapid Small Transactions\nTXN000190,2025-07-16T19:43:20Z,ACC1003,ACC2190,1.00,DKK,debit,Denmark,Copenhagen,192.168.1.12,DEV003,1,Rapid Small Transactions\nTXN000191,2025-07-16T19:44:00Z,ACC1191,ACC2191,2000.00,USD,debit,Malaysia,Kuala Lumpur,10.0.0.21,DEV191,1,Geographic Anomaly\nTXN000192,2025-07-16T19:44:15Z,ACC1192,ACC2192,3000.00,IDR,debit,Indonesia,Jakarta,10.0.0.22,DEV192,1,Geographic Anomaly\nTXN000193,2025-07-17T05:00:00Z,ACC1009,ACC2193,700.00,NZD,debit,New Zealand,Wellington,192.168.1.18,DEV009,1,Time Anomaly\nTXN000194,2025-07-17T05:00:15Z,ACC1012,ACC2194,2800.00,SEK,debit,Sweden,Stockholm,192.168.1.21,DEV012,1,Time Anomaly\nTXN000195,2025-07-16T19:45:00Z,ACC1195,ACC2195,120000.00,USD,credit,USA,Las Vegas,203.0.113.9,DEV195,1,Money Mule Entry\nTXN000196,2025-07-16T19:45:10Z,ACC1195,ACC2196,25000.00,USD,debit,USA,Miami,203.0.113.9,DEV195,1,Money Mule Outflow\nTXN000197,2025-07-16T19:45:20Z,ACC1195,ACC2197,35000.00,EUR,debit,Netherlands,Amsterdam,203.0.113.9,DEV195,1,Money Mule Outflow\nTXN000198,2025-07-16T19:45:30Z,ACC1195,ACC2198,20000.00,GBP,debit,South Africa,Cape Town,203.0.113.9,DEV195,1,Money Mule Outflow\nTXN000199,2025-07-16T19:45:40Z,ACC1195,ACC2199,30000.00,AUD,debit,India,Mumbai,203.0.113.9,DEV195,1,Money Mule Outflow\nTXN000200,2025-07-16T19:46:00Z,ACC1200,ACC2200,8000.00,USD,debit,USA,Atlanta,172.16.0.6,NEWDEV06,1,New Account Anomaly\nTXN000201,2025-07-16T19:46:15Z,ACC1200,ACC2201,3000.00,USD,credit,USA,Atlanta,172.16.0.6,NEWDEV06,1,New Account Anomaly\nTXN000202,2025-07-16T19:46:30Z,ACC1202,ACC2202,100000.00,DKK,debit,Denmark,Copenhagen,10.0.0.23,DEV202,1,Round Number Transfer\nTXN000203,2025-07-16T19:46:45Z,ACC1203,ACC2203,60000.00,USD,credit,Afghanistan,Kabul,10.0.0.24,DEV203,1,High-Risk Country\nTXN000204,2025-07-16T19:47:00Z,ACC1004,ACC2204,1.00,NOK,debit,Norway,Oslo,192.168.1.13,DEV004,1,Rapid Small Transactions\nTXN000205,2025-07-16T19:47:05Z,ACC1004,ACC2205,1.00,NOK,debit,Norway,Oslo,192.168.1.13,DEV004,1,Rapid Small Transactions\nTXN000206,2025-07-16T19:47:10Z,ACC1004,ACC2206,1.00,NOK,debit,Norway,Oslo,192.168.1.13,DEV004,1,Rapid Small Transactions\nTXN000207,2025-07-16T19:47:15Z,ACC1004,ACC2207,1.00,NOK,debit,Norway,Oslo,192.168.1.13,DEV004,1,Rapid Small Transactions\nTXN000208,2025-07-16T19:47:20Z,ACC1004,ACC2208,1.00,NOK,debit,Norway,Oslo,192.168.1.13,DEV004,1,Rapid Small Transactions\nTXN000209,2025-07-16T19:48:00Z,ACC1209,ACC2209,90.00,USD,debit,USA,Denver,192.168.1.91,DEV209,0,Legitimate\nTXN000210,2025-07-16T19:48:15Z,ACC1210,ACC2210,18.00,GBP,credit,UK,Bristol,192.168.1.92,DEV210,0,Legitimate\nTXN000211,2025-07-16T19:48:30Z,ACC1211,ACC2211,30.00,USD,debit,USA,Orlando,192.168.1.93,DEV211,0,Legitimate\nTXN000212,2025-07-16T19:48:45Z,ACC1212,ACC2212,250.00,CAD,credit,Canada,Halifax,192.168.1.94,DEV212,0,Legitimate\nTXN000213,2025-07-16T19:49:00Z,ACC1213,ACC2213,70.00,AUD,debit,Australia,Hobart,192.168.1.95,DEV213,0,Legitimate\nTXN000214,2025-07-16T19:49:15Z,ACC1214,ACC2214,20.00,NZD,credit,New Zealand,Queenstown,192.168.1.96,DEV214,0,Legitimate\nTXN000215,2025-07-16T19:49:30Z,ACC1215,ACC2215,35.00,CHF,debit,Switzerland,Fribourg,192.168.1.97,DEV215,0,Legitimate\nTXN000216,2025-07-16T19:49:45Z,ACC1216,ACC2216,4.00,EUR,credit,Sweden,Malmo,192.168.1.98,DEV216,0,Legitimate\nTXN000217,2025-07-16T19:50:00Z,ACC1217,ACC2217,100.00,SEK,debit,Sweden,Gothenburg,192.168.1.99,DEV217,0,Legitimate\nTXN000218,2025-07-16T19:50:15Z,ACC1218,ACC2218,20000.00,USD,debit,USA,Miami,192.168.1.100,DEV218,1,High-Value Single\nTXN000219,2025-07-16T19:50:45Z,ACC1219,ACC2219,0.30,USD,debit,USA,Miami,203.0.113.10,DEV219,1,Rapid Small Transactions\nTXN000220,2025-07-16T19:50:50Z,ACC1219,ACC2220,0.50,USD,debit,USA,Miami,203.0.113.10,DEV219,1,Rapid Small Transactions\nTXN000221,2025-07-16T19:50:55Z,ACC1219,ACC2221,0.20,USD,debit,USA,Miami,203.0.113.10,DEV219,1,Rapid Small Transactions\nTXN000222,2025-07-16T19:51:00Z,ACC1219,ACC2222,0.80,USD,debit,USA,Miami,203.0.113.10,DEV219,1,Rapid Small Transactions\nTXN000223,2025-07-16T19:51:15Z,ACC1223,ACC2223,3000.00,USD,debit,Thailand,Bangkok,10.0.0.25,DEV223,1,Geographic Anomaly\nTXN000224,2025-07-16T19:51:30Z,ACC1224,ACC2224,4000.00,VND,debit,Myanmar,Yangon,10.0.0.26,DEV224,1,Geographic Anomaly\nTXN000225,2025-07-17T03:30:00Z,ACC1015,ACC2225,900.00,GBP,debit,UK,Manchester,192.168.1.24,DEV015,1,Time Anomaly\nTXN000226,2025-07-17T03:30:15Z,ACC1018,ACC2226,1500.00,AUD,debit,Australia,Melbourne,192.168.1.27,DEV018,1,Time Anomaly\nTXN000227,2025-07-16T19:52:00Z,ACC1227,ACC2227,70000.00,USD,credit,USA,Los Angeles,203.0.113.11,DEV227,1,Money Mule Entry\nTXN000228,2025-07-16T19:52:10Z,ACC1227,ACC2228,14000.00,USD,debit,USA,San Diego,203.0.113.11,DEV227,1,Money Mule Outflow\nTXN000229,2025-07-16T19:52:20Z,ACC1227,ACC2229,18000.00,CAD,debit,Canada,Vancouver,203.0.113.11,DEV227,1,Money Mule Outflow\nTXN000230,2025-07-16T19:52:30Z,ACC1227,ACC2230,13000.00,AUD,debit,Australia,Perth,203.0.113.11,DEV227,1,Money Mule Outflow\nTXN000231,2025-07-16T19:52:40Z,ACC1227,ACC2231,25000.00,NZD,debit,New Zealand,Auckland,203.0.113.11,DEV227,1,Money Mule Outflow\nTXN000232,2025-07-16T19:53:00Z,ACC1232,ACC2232,18000.00,USD,debit,USA,Seattle,172.16.0.7,NEWDEV07,1,New Account Anomaly\nTXN000233,2025-07-16T19:53:15Z,ACC1232,ACC2233,7000.00,USD,credit,USA,Seattle,172.16.0.7,NEWDEV07,1,New Account Anomaly\nTXN000234,2025-07-16T19:53:30Z,ACC1234,ACC2234,40000.00,GBP,debit,UK,London,10.0.0.27,DEV234,1,Round Number Transfer\nTXN000235,2025-07-16T19:53:45Z,ACC1235,ACC2235,25000.00,USD,credit,Yemen,Sanaa,10.0.0.28,DEV235,1,High-Risk Country\nTXN000236,2025-07-16T19:54:00Z,ACC1005,ACC2236,1.00,GBP,debit,UK,London,192.168.1.14,DEV005,1,Rapid Small Transactions\nTXN000237,2025-07-16T19:54:05Z,ACC1005,ACC2237,1.00,GBP,debit,UK,London,192.168.1.14,DEV005,1,Rapid Small Transactions\nTXN000238,2025-07-16T19:54:10Z,ACC1005,ACC2238,1.00,GBP,debit,UK,London,192.168.1.14,DEV005,1,Rapid Small Transactions\nTXN000239,2025-07-16T19:54:15Z,ACC1005,ACC2239,1.00,GBP,debit,UK,London,192.168.1.14,DEV005,1,Rapid Small Transactions\nTXN000240,2025-07-16T19:54:20Z,ACC1005,ACC2240,1.00,GBP,debit,UK,London,192.168.1.14,DEV005,1,Rapid Small Transactions\nTXN000241,2025-07-16T19:55:00Z,ACC1241,ACC2241,70.00,USD,debit,USA,Boston,192.168.1.101,DEV241,0,Legitimate\nTXN000242,2025-07-16T19:55:15Z,ACC1242,ACC2242,10.00,CHF,credit,Switzerland,Geneva,192.168.1.102,DEV242,0,Legitimate\nTXN000243,2025-07-16T19:55:30Z,ACC1243,ACC2243,20.00,EUR,debit,Sweden,Uppsala,192.168.1.103,DEV243,0,Legitimate\nTXN000244,2025-07-16T19:55:45Z,ACC1244,ACC2244,50.00,SEK,credit,Sweden,Lund,192.168.1.104,DEV244,0,Legitimate\nTXN000245,2025-07-16T19:56:00Z,ACC1245,ACC2245,25.00,DKK,debit,Denmark,Roskilde,192.168.1.105,DEV245,0,Legitimate\nTXN000246,2025-07-16T19:56:15Z,ACC1246,ACC2246,120.00,NOK,credit,Norway,Drammen,192.168.1.106,DEV246,0,Legitimate\nTXN000247,2025-07-16T19:56:30Z,ACC1247,ACC2247,45.00,GBP,debit,UK,Sheffield,192.168.1.107,DEV247,0,Legitimate\nTXN000248,2025-07-16T19:56:45Z,ACC1248,ACC2248,90.00,USD,credit,USA,Washington DC,192.168.1.108,DEV248,0,Legitimate\nTXN000249,2025-07-16T19:57:00Z,ACC1249,ACC2249,250.00,CAD,debit,Canada,Victoria,192.168.1.109,DEV249,0,Legitimate\nTXN000250,2025-07-16T19:57:15Z,ACC1250,ACC2250,70.00,AUD,credit,Australia,Darwin,192.168.1.110,DEV250,0,Legitimate\nTXN000251,2025-07-16T19:57:30Z,ACC1251,ACC2251,16.00,NZD,debit,New Zealand,Nelson,192.168.1.111,DEV251,0,Legitimate\nTXN000252,2025-07-16T19:57:45Z,ACC1252,ACC2252,28.00,CHF,credit,Switzerland,Bern,192.168.1.112,DEV252,0,Legitimate\nTXN000253,2025-07-16T19:58:00Z,ACC1253,ACC2253,5.00,EUR,debit,Sweden,Orebro,192.168.1.113,DEV253,0,Legitimate\nTXN000254,2025-07-16T19:58:15Z,ACC1254,ACC2254,110.00,SEK,credit,Sweden,Halmstad,192.168.1.114,DEV254,0,Legitimate\nTXN000255,2025-07-16T19:58:30Z,ACC1255,ACC2255,30000.00,USD,debit,USA,New York,192.168.1.115,DEV255,1,High-Value Single\nTXN000256,2025-07-16T19:59:00Z,ACC1256,ACC2256,0.10,EUR,debit,Sweden,Trollhattan,203.0.113.12,DEV256,1,Rapid Small Transactions\nTXN000257,2025-07-16T19:59:05Z,ACC1256,ACC2257,0.20,EUR,debit,Sweden,Trollhattan,203.0.113.12,DEV256,1,Rapid Small Transactions\nTXN000258,2025-07-16T19:59:10Z,ACC1256,ACC2258,0.15,EUR,debit,Sweden,Trollhattan,203.0.113.12,DEV256,1,Rapid Small Transactions\nTXN000259,2025-07-16T19:59:15Z,ACC1256,ACC2259,0.25,EUR,debit,Sweden,Trollhattan,203.0.113.12,DEV256,1,Rapid Small Transactions\nTXN000260,2025-07-16T19:59:20Z,ACC1256,ACC2260,0.30,EUR,debit,Sweden,Trollhattan,203.0.113.12,DEV256,1,Rapid Small Transactions\nTXN000261,2025-07-16T20:00:00Z,ACC1261,ACC2261,500.00,USD,debit,Venezuela,Caracas,10.0.0.29,DEV261,1,High-Risk Country\nTXN000262,2025-07-16T20:00:15Z,ACC1262,ACC2262,800.00,BRL,debit,Colombia,Bogota,10.0.0.30,DEV262,1,High-Risk Country\nTXN000263,2025-07-17T00:00:00Z,ACC1019,ACC2263,300.00,NZD,debit,New Zealand,Auckland,192.168.1.28,DEV019,1,Time Anomaly\nTXN000264,2025-07-17T00:00:15Z,ACC1020,ACC2264,150.00,CHF,debit,Switzerland,Geneva,192.168.1.29,DEV020,1,Time Anomaly\nTXN000265,2025-07-16T20:01:00Z,ACC1265,ACC2265,150000.00,USD,credit,USA,New York,203.0.113.13,DEV265,1,Money Mule Entry\nTXN000266,2025-07-16T20:01:10Z,ACC1265,ACC2266,30000.00,USD,debit,USA,Philadelphia,203.0.113.13,DEV265,1,Money Mule Outflow\nTXN000267,2025-07-16T20:01:20Z,ACC1265,ACC2267,40000.00,EUR,debit,Portugal,Lisbon,203.0.113.13,DEV265,1,Money Mule Outflow\nTXN000268,2025-07-16T20:01:30Z,ACC1265,ACC2268,25000.00,GBP,debit,Ghana,Accra,203.0.113.13,DEV265,1,Money Mule Outflow\nTXN000269,2025-07-16T20:01:40Z,ACC1265,ACC2269,50000.00,CAD,debit,Mexico,Mexico City,203.0.113.13,DEV265,1,Money Mule Outflow\nTXN000270,2025-07-16T20:02:00Z,ACC1270,ACC2270,10000.00,USD,debit,USA,Austin,172.16.0.8,NEWDEV08,1,New Account Anomaly\nTXN000271,2025-07-16T20:02:15Z,ACC1270,ACC2271,4000.00,USD,credit,USA,Austin,172.16.0.8,NEWDEV08,1,New Account Anomaly\nTXN000272,2025-07-16T20:02:30Z,ACC1272,ACC2272,120000.00,USD,debit,USA,Los Angeles,10.0.0.31,DEV272,1,Round Number Transfer\nTXN000273,2025-07-16T20:02:45Z,ACC1273,ACC2273,75000.00,USD,credit,Syria,Aleppo,10.0.0.32,DEV273,1,High-Risk Country\nTXN000274,2025-07-16T20:03:00Z,ACC1006,ACC2274,1.00,USD,debit,USA,New York,192.168.1.15,DEV006,1,Rapid Small Transactions\nTXN000275,2025-07-16T20:03:05Z,ACC1006,ACC2275,1.00,USD,debit,USA,New York,192.168.1.15,DEV006,1,Rapid Small Transactions\nTXN000276,2025-07-16T20:03:10Z,ACC1006,ACC2276,1.00,USD,debit,USA,New York,192.168.1.15,DEV006,1,Rapid Small Transactions\nTXN000277,2025-07-16T20:03:15Z,ACC1006,ACC2277,1.00,USD,debit,USA,New York,192.168.1.15,DEV006,1,Rapid Small Transactions\nTXN000278,2025-07-16T20:03:20Z,ACC1006,ACC2278,1.00,USD,debit,USA,New York,192.168.1.15,DEV006,1,Rapid Small Transactions\nTXN000279,2025-07-16T20:04:00Z,ACC1279,ACC2279,85.00,USD,debit,USA,Columbus,192.168.1.116,DEV279,0,Legitimate\nTXN000280,2025-07-16T20:04:15Z,ACC1280,ACC2280,12.00,EUR,credit,Ireland,Dublin,192.168.1.117,DEV280,0,Legitimate\nTXN000281,2025-07-16T20:04:30Z,ACC1281,ACC2281,22.00,SEK,debit,Sweden,Gotland,192.168.1.118,DEV281,0,Legitimate\nTXN000282,2025-07-16T20:04:45Z,ACC1282,ACC2282,65.00,NOK,credit,Norway,Arendal,192.168.1.119,DEV282,0,Legitimate\nTXN000283,2025-07-16T20:05:00Z,ACC1283,ACC2283,38.00,GBP,debit,UK,Newcastle,192.168.1.120,DEV283,0,Legitimate\nTXN000284,2025-07-16T20:05:15Z,ACC1284,ACC2284,95.00,USD,credit,USA,Indianapolis,192.168.1.121,DEV284,0,Legitimate\nTXN000285,2025-07-16T20:05:30Z,ACC1285,ACC2285,280.00,CAD,debit,Canada,Saskatoon,192.168.1.122,DEV285,0,Legitimate\nTXN000286,2025-07-16T20:05:45Z,ACC1286,ACC2286,75.00,AUD,credit,Australia,Canberra,192.168.1.123,DEV286,0,Legitimate\nTXN000287,2025-07-16T20:06:00Z,ACC1287,ACC2287,14.00,NZD,debit,New Zealand,Rotorua,192.168.1.124,DEV287,0,Legitimate\nTXN000288,2025-07-16T20:06:15Z,ACC1288,ACC2288,26.00,CHF,credit,Switzerland,Lugano,192.168.1.125,DEV288,0,Legitimate\nTXN000289,2025-07-16T20:06:30Z,ACC1289,ACC2289,6.00,EUR,debit,Sweden,Gavle,192.168.1.126,DEV289,0,Legitimate\nTXN000290,2025-07-16T20:06:45Z,ACC1290,ACC2290,105.00,SEK,credit,Sweden,Sundsvall,192.168.1.127,DEV290,0,Legitimate\nTXN000291,2025-07-16T20:07:00Z,ACC1291,ACC2291,40000.00,USD,debit,USA,Miami,192.168.1.128,DEV291,1,High-Value Single\nTXN000292,2025-07-16T20:07:30Z,ACC1292,ACC2292,0.70,CAD,debit,Canada,Montreal,203.0.113.14,DEV292,1,Rapid Small Transactions\nTXN000293,2025-07-16T20:07:35Z,ACC1292,ACC2293,0.80,CAD,debit,Canada,Montreal,203.0.113.14,DEV292,1,Rapid Small Transactions\nTXN000294,2025-07-16T20:07:40Z,ACC1292,ACC2294,0.60,CAD,debit,Canada,Montreal,203.0.113.14,DEV292,1,Rapid Small Transactions\nTXN000295,2025-07-16T20:07:45Z,ACC1292,ACC2295,0.95,CAD,debit,Canada,Montreal,203.0.113.14,DEV292,1,Rapid Small Transactions\nTXN000296,2025-07-16T20:08:00Z,ACC1296,ACC2296,1500.00,USD,debit,Turkey,Antalya,10.0.0.33,DEV296,1,High-Risk Country\nTXN000297,2025-07-16T20:08:15Z,ACC1297,ACC2297,2500.00,RUB,debit,Georgia,Tbilisi,10.0.0.34,DEV297,1,Geographic Anomaly\nTXN000298,2025-07-17T04:00:00Z,ACC1007,ACC2298,1200.00,CAD,debit,Canada,Toronto,192.168.1.16,DEV007,1,Time Anomaly\nTXN000299,2025-07-17T04:00:15Z,ACC1014,ACC2299,3500.00,NOK,debit,Norway,Bergen,192.168.1.23,DEV014,1,Time Anomaly\nTXN000300,2025-07-16T20:09:00Z,ACC1300,ACC2300,200000.00,USD,credit,USA,Los Angeles,203.0.113.15,DEV300,1,Money Mule Entry`, [\n\t{\n\t\tcolumn_name: \"from_account\",\n\t\tcolumn_id: \"from_account\",\n\t\tnode_type: \"c4rdx1offhvxrtl\"\n\t},\n\t{\n\t\tcolumn_name: \"to_account\",\n\t\tcolumn_id: \"to_account\",\n\t\tnode_type: \"c4rdx1offhvxrtl\"\n\t},\n])\n\t\t\tdocument.addEventListener(\"DOMContentLoaded\", () => {\n\t\t\t\tdriver.run(store).then(graph => {\n\t\t\t\t\twindow.driver = driver\n\t\t\t\t\tlive.start()\n\t\t\t\t\twindow.addEventListener(\"keydown\", async (e) => {\n\t\t\t\t\t\tif (e.target instanceof HTMLInputElement || e.target instanceof HTMLTextAreaElement) return\n\t\t\t\t\t\tif ((e.ctrlKey || e.metaKey) && e.key.toLowerCase() == \"z\") {\n\t\t\t\t\t\t\te.preventDefault()\n\t\t\t\t\t\t\tawait store.travel(e.shiftKey ? \"redo\" : \"undo\")\n\t\t\t\t\t\t\treturn\n\t\t\t\t\t\t}\n\t\t\t\t\t\tif ((e.ctrlKey || e.metaKey) && e.key == \"y\") {\n\t\t\t\t\t\t\te.preventDefault()\n\t\t\t\t\t\t\tawait store.travel(\"redo\")\n\t\t\t\t\t\t\treturn\n\t\t\t\t\t\t}\n\t\t\t\t\t\tif (e.key == \"h\" && !store.read_only) {\n\t\t\t\t\t\t\tawait driver.graph.import(\n\t\t\t\t\t\t\t\tcsv_data,\n\t\t\t\t\t\t\t\t[\n\t\t\t\t\t\t\t\t\t{\n\t\t\t\t\t\t\t\t\t\tsource_column: \"from_account\",\n\t\t\t\t\t\t\t\t\t\ttarget_column: \"to_account\",\n\t\t\t\t\t\t\t\t\t\tedge_type: \"k33u61b74vg3888\"\n\t\t\t\t\t\t\t\t\t},\n\t\t\t\t\t\t\t\t]\n\t\t\t\t\t\t\t);\n\t\t\t\t\t\t\tdriver.graph.store.init(driver.graph)\n\t\t\t\t\t\t}\n\t\t\t\t\t})\n\t\t\t\t})\n\t\t\t})\n\t\t</script></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -194,12 +516,91 @@ func Footer() templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var9 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var9 == nil {
-			templ_7745c5c3_Var9 = templ.NopComponent
+		templ_7745c5c3_Var27 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var27 == nil {
+			templ_7745c5c3_Var27 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 38, "<div class=\"control-panel__section control-panel__section--footer\"><dialog data-ref=\"tooltips\"><div class=\"tooltips\"><div><span>ctrl</span> + <span>drag</span>: pan</div><div><span>shift</span> + <span>drag</span>: connect</div><div><span>del</span>: delete selected</div><div><span>backspace</span>: delete selected connections</div><div><span>scroll</span>: zoom</div><div><span>ctrl</span> + <span>z</span>: undo</div><div><span>ctrl</span> + <span>shift</span> + <span>z</span>: redo</div></div></dialog><div class=\"control-panel__button-group\"><button data-on-click=\"$tooltips.showModal()\"><span class=\"material-symbols\">question_mark</span></button></div></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+// Presence lists everyone who has the project open.
+func Presence(viewers []hub.Viewer) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var28 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var28 == nil {
+			templ_7745c5c3_Var28 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "<div class=\"control-panel__section control-panel__section--footer\"><dialog data-ref=\"tooltips\"><div class=\"tooltips\"><div><span>ctrl</span> + <span>drag</span>: pan</div><div><span>shift</span> + <span>drag</span>: connect</div><div><span>del</span>: delete selected</div><div><span>backspace</span>: delete selected connections</div><div><span>scroll</span>: zoom</div></div></dialog><div class=\"control-panel__button-group\"><button data-on-click=\"$tooltips.showModal()\"><span class=\"material-symbols\">question_mark</span></button></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 39, "<div id=\"presence\" class=\"control-panel__section presence\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		for _, v := range viewers {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 40, "<span class=\"presence__viewer\" title=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var29 string
+			templ_7745c5c3_Var29, templ_7745c5c3_Err = templ.JoinStringErrs(v.Name)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 506, Col: 18}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var29))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 41, "\" style=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var30 string
+			templ_7745c5c3_Var30, templ_7745c5c3_Err = templruntime.SanitizeStyleAttributeValues(fmt.Sprintf("background-color: %s", v.Color))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 507, Col: 56}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var30))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 42, "\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var31 string
+			templ_7745c5c3_Var31, templ_7745c5c3_Err = templ.JoinStringErrs(initial(v.Name))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 508, Col: 21}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var31))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 43, "</span>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 44, "</div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -207,6 +608,1300 @@ func Footer() templ.Component {
 	})
 }
 
+func initial(name string) string {
+	for _, r := range name {
+		return string(r)
+	}
+	return "?"
+}
+
+func snapshotId(snapshot *Snapshot) string {
+	if snapshot == nil {
+		return ""
+	}
+	return snapshot.Id
+}
+
+// SnapshotList shows the saved versions of a project. Only members who may
+// manage the project can save and restore them.
+func SnapshotList(project_id string, snapshots []Snapshot, can_manage bool, csrf_token string) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var32 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var32 == nil {
+			templ_7745c5c3_Var32 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 45, "<div id=\"snapshots\" class=\"snapshots\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		if can_manage {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 46, "<form class=\"snapshots__form\" data-on-submit=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var33 string
+			templ_7745c5c3_Var33, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("@post('/sse/project/%s/snapshots', {contentType: 'form'})", project_id))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 534, Col: 105}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var33))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 47, "\"><input type=\"hidden\" name=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var34 string
+			templ_7745c5c3_Var34, templ_7745c5c3_Err = templ.JoinStringErrs(middleware.CSRF_TOKEN_FIELD)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 536, Col: 59}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var34))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 48, "\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var35 string
+			templ_7745c5c3_Var35, templ_7745c5c3_Err = templ.JoinStringErrs(csrf_token)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 536, Col: 80}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var35))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 49, "\"> <input type=\"text\" name=\"name\" placeholder=\"Snapshot name\"> <button type=\"submit\" title=\"Save snapshot\"><span class=\"material-symbols\">add</span></button></form>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		if len(snapshots) == 0 {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 50, "<p class=\"snapshots__empty\">No snapshots yet</p>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 51, "<ul class=\"snapshots__list\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		for _, s := range snapshots {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 52, "<li class=\"snapshots__item\"><a href=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var36 templ.SafeURL
+			templ_7745c5c3_Var36, templ_7745c5c3_Err = templ.JoinURLErrs(templ.SafeURL(fmt.Sprintf("/project/%s/snapshots/%s", project_id, s.Id)))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 549, Col: 87}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var36))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 53, "\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var37 string
+			templ_7745c5c3_Var37, templ_7745c5c3_Err = templ.JoinStringErrs(s.Name)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 549, Col: 98}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var37))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 54, "</a> <span class=\"snapshots__meta\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var38 string
+			templ_7745c5c3_Var38, templ_7745c5c3_Err = templ.JoinStringErrs(shortDate(s.Created))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 551, Col: 28}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var38))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 55, " · ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var39 string
+			templ_7745c5c3_Var39, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprint(s.Nodes))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 551, Col: 55}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var39))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 56, " nodes · ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var40 string
+			templ_7745c5c3_Var40, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprint(s.Edges))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 551, Col: 88}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var40))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 57, " connections</span> ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if can_manage {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 58, "<form data-on-submit=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var41 string
+				templ_7745c5c3_Var41, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("confirm('Replace the project with this snapshot?') && @post('/sse/project/%s/snapshots/%s/restore', {contentType: 'form'})", project_id, s.Id))
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 555, Col: 179}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var41))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 59, "\"><input type=\"hidden\" name=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var42 string
+				templ_7745c5c3_Var42, templ_7745c5c3_Err = templ.JoinStringErrs(middleware.CSRF_TOKEN_FIELD)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 557, Col: 62}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var42))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 60, "\" value=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var43 string
+				templ_7745c5c3_Var43, templ_7745c5c3_Err = templ.JoinStringErrs(csrf_token)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 557, Col: 83}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var43))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 61, "\"> <button type=\"submit\" title=\"Restore snapshot\"><span class=\"material-symbols\">restore</span></button></form>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 62, "</li>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 63, "</ul></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+// TypeEditor lists the node and edge types of a project, editable for those
+// who may manage it.
+func TypeEditor(project_id string, node_types []NodeType, edge_types []EdgeType, can_manage bool, csrf_token string) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var44 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var44 == nil {
+			templ_7745c5c3_Var44 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 64, "<div id=\"types\" class=\"types\"><span class=\"snapshots__meta\">Nodes</span> ")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		for _, t := range node_types {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 65, "<details class=\"types__item\"><summary>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var45 string
+			templ_7745c5c3_Var45, templ_7745c5c3_Err = templ.JoinStringErrs(t.Name)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 576, Col: 21}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var45))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 66, "</summary>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = nodeTypeForm(project_id, t, can_manage, csrf_token).Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if can_manage {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 67, "<form class=\"types__delete\" data-on-submit=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var46 string
+				templ_7745c5c3_Var46, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("confirm('Delete this node type?') && @post('/sse/project/%s/node-types/%s/delete', {contentType: 'form'})", project_id, t.Id))
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 581, Col: 161}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var46))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 68, "\"><input type=\"hidden\" name=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var47 string
+				templ_7745c5c3_Var47, templ_7745c5c3_Err = templ.JoinStringErrs(middleware.CSRF_TOKEN_FIELD)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 583, Col: 61}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var47))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 69, "\" value=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var48 string
+				templ_7745c5c3_Var48, templ_7745c5c3_Err = templ.JoinStringErrs(csrf_token)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 583, Col: 82}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var48))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 70, "\"> <label>Move its nodes to <select name=\"reassign\"><option value=\"\">Nowhere, only delete when unused</option> ")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				for _, other := range node_types {
+					if other.Id != t.Id {
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 71, "<option value=\"")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						var templ_7745c5c3_Var49 string
+						templ_7745c5c3_Var49, templ_7745c5c3_Err = templ.JoinStringErrs(other.Id)
+						if templ_7745c5c3_Err != nil {
+							return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 590, Col: 34}
+						}
+						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var49))
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 72, "\">")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						var templ_7745c5c3_Var50 string
+						templ_7745c5c3_Var50, templ_7745c5c3_Err = templ.JoinStringErrs(other.Name)
+						if templ_7745c5c3_Err != nil {
+							return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 590, Col: 49}
+						}
+						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var50))
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 73, "</option>")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+					}
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 74, "</select></label> <button type=\"submit\" title=\"Delete node type\"><span class=\"material-symbols\">delete</span></button></form>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 75, "</details> ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		if can_manage {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 76, "<details class=\"types__item\"><summary>New node type</summary>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = nodeTypeForm(project_id, NodeType{FillColor: "#ffffff", StrokeColor: "#000000", StrokeWidth: 1}, true, csrf_token).Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 77, "</details> ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 78, "<span class=\"snapshots__meta\">Connections</span> ")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		for _, t := range edge_types {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 79, "<details class=\"types__item\"><summary>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var51 string
+			templ_7745c5c3_Var51, templ_7745c5c3_Err = templ.JoinStringErrs(t.Name)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 611, Col: 21}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var51))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 80, "</summary>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = edgeTypeForm(project_id, t, can_manage, csrf_token).Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if can_manage {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 81, "<form class=\"types__delete\" data-on-submit=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var52 string
+				templ_7745c5c3_Var52, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("confirm('Delete this type of connection?') && @post('/sse/project/%s/edge-types/%s/delete', {contentType: 'form'})", project_id, t.Id))
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 616, Col: 170}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var52))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 82, "\"><input type=\"hidden\" name=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var53 string
+				templ_7745c5c3_Var53, templ_7745c5c3_Err = templ.JoinStringErrs(middleware.CSRF_TOKEN_FIELD)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 618, Col: 61}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var53))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 83, "\" value=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var54 string
+				templ_7745c5c3_Var54, templ_7745c5c3_Err = templ.JoinStringErrs(csrf_token)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 618, Col: 82}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var54))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 84, "\"> <label>Move its connections to <select name=\"reassign\"><option value=\"\">Nowhere, only delete when unused</option> ")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				for _, other := range edge_types {
+					if other.Id != t.Id {
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 85, "<option value=\"")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						var templ_7745c5c3_Var55 string
+						templ_7745c5c3_Var55, templ_7745c5c3_Err = templ.JoinStringErrs(other.Id)
+						if templ_7745c5c3_Err != nil {
+							return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 625, Col: 34}
+						}
+						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var55))
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 86, "\">")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						var templ_7745c5c3_Var56 string
+						templ_7745c5c3_Var56, templ_7745c5c3_Err = templ.JoinStringErrs(other.Name)
+						if templ_7745c5c3_Err != nil {
+							return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 625, Col: 49}
+						}
+						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var56))
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 87, "</option>")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+					}
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 88, "</select></label> <button type=\"submit\" title=\"Delete type of connection\"><span class=\"material-symbols\">delete</span></button></form>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 89, "</details> ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		if can_manage {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 90, "<details class=\"types__item\"><summary>New type of connection</summary>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = edgeTypeForm(project_id, EdgeType{StrokeColor: "#000000", StrokeWidth: 1}, true, csrf_token).Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 91, "</details>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 92, "</div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+// nodeTypeForm edits a node type, or creates one when it has no id yet.
+func nodeTypeForm(project_id string, t NodeType, can_manage bool, csrf_token string) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var57 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var57 == nil {
+			templ_7745c5c3_Var57 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 93, "<form class=\"types__form\" data-on-submit=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var58 string
+		templ_7745c5c3_Var58, templ_7745c5c3_Err = templ.JoinStringErrs(typeAction(project_id, "node-types", t.Id))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 650, Col: 61}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var58))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 94, "\"><fieldset")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		if !can_manage {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 95, " disabled")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 96, "><input type=\"hidden\" name=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var59 string
+		templ_7745c5c3_Var59, templ_7745c5c3_Err = templ.JoinStringErrs(middleware.CSRF_TOKEN_FIELD)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 653, Col: 58}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var59))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 97, "\" value=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var60 string
+		templ_7745c5c3_Var60, templ_7745c5c3_Err = templ.JoinStringErrs(csrf_token)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 653, Col: 79}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var60))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 98, "\"> <label>Name <input type=\"text\" name=\"name\" value=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var61 string
+		templ_7745c5c3_Var61, templ_7745c5c3_Err = templ.JoinStringErrs(t.Name)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 654, Col: 60}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var61))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 99, "\" required></label> <label>Fill <input type=\"text\" name=\"fill_color\" value=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var62 string
+		templ_7745c5c3_Var62, templ_7745c5c3_Err = templ.JoinStringErrs(t.FillColor)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 655, Col: 71}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var62))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 100, "\" pattern=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var63 string
+		templ_7745c5c3_Var63, templ_7745c5c3_Err = templ.JoinStringErrs(COLOR_PATTERN)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 655, Col: 97}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var63))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 101, "\" required></label> <label>Stroke <input type=\"text\" name=\"stroke_color\" value=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var64 string
+		templ_7745c5c3_Var64, templ_7745c5c3_Err = templ.JoinStringErrs(t.StrokeColor)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 656, Col: 77}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var64))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 102, "\" pattern=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var65 string
+		templ_7745c5c3_Var65, templ_7745c5c3_Err = templ.JoinStringErrs(COLOR_PATTERN)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 656, Col: 103}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var65))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 103, "\" required></label> <label>Stroke width <input type=\"number\" name=\"stroke_width\" value=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var66 string
+		templ_7745c5c3_Var66, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprint(t.StrokeWidth))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 657, Col: 97}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var66))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 104, "\" min=\"0\" max=\"32\"></label> <label>Shape <select name=\"shape\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		for i, shape := range SHAPES {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 105, "<option value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var67 string
+			templ_7745c5c3_Var67, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprint(i))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 662, Col: 35}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var67))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 106, "\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if int(t.Shape) == i {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 107, " selected")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 108, ">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var68 string
+			templ_7745c5c3_Var68, templ_7745c5c3_Err = templ.JoinStringErrs(shape)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 662, Col: 77}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var68))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 109, "</option>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 110, "</select></label>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = schemaField(t.Schema).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		if can_manage {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 111, "<button type=\"submit\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var69 string
+			templ_7745c5c3_Var69, templ_7745c5c3_Err = templ.JoinStringErrs(saveLabel(t.Id))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 668, Col: 43}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var69))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 112, "</button>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 113, "</fieldset></form>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+// edgeTypeForm edits a type of connection, or creates one when it has no id
+// yet.
+func edgeTypeForm(project_id string, t EdgeType, can_manage bool, csrf_token string) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var70 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var70 == nil {
+			templ_7745c5c3_Var70 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 114, "<form class=\"types__form\" data-on-submit=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var71 string
+		templ_7745c5c3_Var71, templ_7745c5c3_Err = templ.JoinStringErrs(typeAction(project_id, "edge-types", t.Id))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 679, Col: 61}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var71))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 115, "\"><fieldset")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		if !can_manage {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 116, " disabled")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 117, "><input type=\"hidden\" name=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var72 string
+		templ_7745c5c3_Var72, templ_7745c5c3_Err = templ.JoinStringErrs(middleware.CSRF_TOKEN_FIELD)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 682, Col: 58}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var72))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 118, "\" value=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var73 string
+		templ_7745c5c3_Var73, templ_7745c5c3_Err = templ.JoinStringErrs(csrf_token)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 682, Col: 79}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var73))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 119, "\"> <label>Name <input type=\"text\" name=\"name\" value=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var74 string
+		templ_7745c5c3_Var74, templ_7745c5c3_Err = templ.JoinStringErrs(t.Name)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 683, Col: 60}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var74))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 120, "\" required></label> <label>Stroke <input type=\"text\" name=\"stroke_color\" value=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var75 string
+		templ_7745c5c3_Var75, templ_7745c5c3_Err = templ.JoinStringErrs(t.StrokeColor)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 684, Col: 77}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var75))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 121, "\" pattern=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var76 string
+		templ_7745c5c3_Var76, templ_7745c5c3_Err = templ.JoinStringErrs(COLOR_PATTERN)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 684, Col: 103}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var76))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 122, "\" required></label> <label>Stroke width <input type=\"number\" name=\"stroke_width\" value=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var77 string
+		templ_7745c5c3_Var77, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprint(t.StrokeWidth))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 685, Col: 97}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var77))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 123, "\" min=\"0\" max=\"32\"></label> <label>Line dash <input type=\"text\" name=\"line_dash\" value=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var78 string
+		templ_7745c5c3_Var78, templ_7745c5c3_Err = templ.JoinStringErrs(lineDashText(t.LineDash))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 686, Col: 88}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var78))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 124, "\" placeholder=\"Solid, or lengths such as 5, 3\"></label>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = schemaField(t.Schema).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		if can_manage {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 125, "<button type=\"submit\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var79 string
+			templ_7745c5c3_Var79, templ_7745c5c3_Err = templ.JoinStringErrs(saveLabel(t.Id))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 689, Col: 43}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var79))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 126, "</button>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 127, "</fieldset></form>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+// schemaField edits the attributes of a type as JSON, see Attribute.
+func schemaField(schema []byte) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var80 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var80 == nil {
+			templ_7745c5c3_Var80 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 128, "<label class=\"types__schema\">Attributes <textarea name=\"schema\" rows=\"4\" placeholder=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var81 string
+		templ_7745c5c3_Var81, templ_7745c5c3_Err = templ.JoinStringErrs(`[{"name": "amount", "type": "number", "required": true}]`)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 702, Col: 75}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var81))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 129, "\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var82 string
+		templ_7745c5c3_Var82, templ_7745c5c3_Err = templ.JoinStringErrs(schemaText(schema))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 703, Col: 23}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var82))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 130, "</textarea></label>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+// schemaText indents a stored schema for the editor.
+func schemaText(raw []byte) string {
+	indented := bytes.Buffer{}
+	if len(raw) == 0 || json.Indent(&indented, raw, "", "  ") != nil || indented.String() == "null" {
+		return ""
+	}
+	return indented.String()
+}
+
+// COLOR_PATTERN is the pattern of the color fields, the server accepts the
+// same hex colors.
+const COLOR_PATTERN = "#([0-9a-fA-F]{3,4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})"
+
+func exportURL(project_id string, format string) templ.SafeURL {
+	return templ.URL(fmt.Sprintf("/v-api/project/%s/export.%s", project_id, format))
+}
+
+func typeAction(project_id string, collection string, id string) string {
+	if id == "" {
+		return fmt.Sprintf("@post('/sse/project/%s/%s', {contentType: 'form'})", project_id, collection)
+	}
+	return fmt.Sprintf("@post('/sse/project/%s/%s/%s', {contentType: 'form'})", project_id, collection, id)
+}
+
+func saveLabel(id string) string {
+	if id == "" {
+		return "Create"
+	}
+	return "Save"
+}
+
+// lineDashText writes a stored dash pattern the way it is typed in.
+func lineDashText(raw []byte) string {
+	dash := []float64{}
+	if len(raw) == 0 || json.Unmarshal(raw, &dash) != nil {
+		return ""
+	}
+
+	lengths := make([]string, len(dash))
+	for i, length := range dash {
+		lengths[i] = strconv.FormatFloat(length, 'f', -1, 64)
+	}
+	return strings.Join(lengths, ", ")
+}
+
+// TrashList shows the deleted nodes and edges of a project. names holds the
+// names of the nodes edges point at, in the trash or not.
+func TrashList(project_id string, nodes []Node, edges []Edge, names map[string]string, permissions Permission, csrf_token string) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var83 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var83 == nil {
+			templ_7745c5c3_Var83 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 131, "<div id=\"trash\" class=\"snapshots\"><button class=\"trash__refresh\" title=\"Refresh\" data-on-click=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var84 string
+		templ_7745c5c3_Var84, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("@get('/sse/project/%s/trash')", project_id))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 759, Col: 75}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var84))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 132, "\"><span class=\"material-symbols\">refresh</span></button> ")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		if len(nodes) == 0 && len(edges) == 0 {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 133, "<p class=\"snapshots__empty\">The trash is empty</p>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 134, "<ul class=\"snapshots__list\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		for _, n := range nodes {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 135, "<li class=\"snapshots__item\"><span>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var85 string
+			templ_7745c5c3_Var85, templ_7745c5c3_Err = templ.JoinStringErrs(n.Name)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 769, Col: 19}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var85))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 136, "</span> <span class=\"snapshots__meta\">Node · deleted ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var86 string
+			templ_7745c5c3_Var86, templ_7745c5c3_Err = templ.JoinStringErrs(shortDate(n.DeletedAt))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 770, Col: 75}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var86))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 137, "</span> ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if HasPermission(permissions, P_EDIT_NODES) {
+				templ_7745c5c3_Err = trashRestore(project_id, "node", n.Id, csrf_token).Render(ctx, templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 138, "</li>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		for _, e := range edges {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 139, "<li class=\"snapshots__item\"><span>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var87 string
+			templ_7745c5c3_Var87, templ_7745c5c3_Err = templ.JoinStringErrs(names[e.StartId])
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 779, Col: 24}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var87))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 140, " → ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var88 string
+			templ_7745c5c3_Var88, templ_7745c5c3_Err = templ.JoinStringErrs(names[e.EndId])
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 779, Col: 47}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var88))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 141, " ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if e.Name != "" {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 142, "(")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var89 string
+				templ_7745c5c3_Var89, templ_7745c5c3_Err = templ.JoinStringErrs(e.Name)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 781, Col: 16}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var89))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 143, ")")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 144, "</span> <span class=\"snapshots__meta\">Connection · deleted ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var90 string
+			templ_7745c5c3_Var90, templ_7745c5c3_Err = templ.JoinStringErrs(shortDate(e.DeletedAt))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 784, Col: 81}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var90))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 145, "</span> ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if HasPermission(permissions, P_EDIT_CONNECTION) {
+				templ_7745c5c3_Err = trashRestore(project_id, "edge", e.Id, csrf_token).Render(ctx, templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 146, "</li>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 147, "</ul></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+func trashRestore(project_id string, field string, id string, csrf_token string) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var91 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var91 == nil {
+			templ_7745c5c3_Var91 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 148, "<form data-on-submit=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var92 string
+		templ_7745c5c3_Var92, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("@post('/sse/project/%s/trash/restore', {contentType: 'form'})", project_id))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 796, Col: 107}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var92))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 149, "\"><input type=\"hidden\" name=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var93 string
+		templ_7745c5c3_Var93, templ_7745c5c3_Err = templ.JoinStringErrs(middleware.CSRF_TOKEN_FIELD)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 798, Col: 57}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var93))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 150, "\" value=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var94 string
+		templ_7745c5c3_Var94, templ_7745c5c3_Err = templ.JoinStringErrs(csrf_token)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 798, Col: 78}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var94))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 151, "\"> <input type=\"hidden\" name=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var95 string
+		templ_7745c5c3_Var95, templ_7745c5c3_Err = templ.JoinStringErrs(field)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 799, Col: 35}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var95))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 152, "\" value=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var96 string
+		templ_7745c5c3_Var96, templ_7745c5c3_Err = templ.JoinStringErrs(id)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 799, Col: 48}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var96))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 153, "\"> <button type=\"submit\" title=\"Restore\"><span class=\"material-symbols\">restore_from_trash</span></button></form>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+// shortDate cuts a stored timestamp down to the minute.
+func shortDate(date string) string {
+	if len(date) < 16 {
+		return date
+	}
+	return date[:16]
+}
+
 func header() templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
@@ -223,12 +1918,12 @@ func header() templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var10 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var10 == nil {
-			templ_7745c5c3_Var10 = templ.NopComponent
+		templ_7745c5c3_Var97 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var97 == nil {
+			templ_7745c5c3_Var97 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "<div class=\"control-panel__section control-panel__section--header\"><div id=\"user-card\" data-on-load=\"@get('/auth/user')\"></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 154, "<div class=\"control-panel__section control-panel__section--header\"><div id=\"user-card\" data-on-load=\"@get('/auth/user')\"></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -252,59 +1947,59 @@ func ControlPanelSection(title string, icon string, tool int) templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var11 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var11 == nil {
-			templ_7745c5c3_Var11 = templ.NopComponent
+		templ_7745c5c3_Var98 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var98 == nil {
+			templ_7745c5c3_Var98 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "<div class=\"control-panel__section\" tool=\"")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 155, "<div class=\"control-panel__section\" tool=\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var12 string
-		templ_7745c5c3_Var12, templ_7745c5c3_Err = templ.JoinStringErrs(tool)
+		var templ_7745c5c3_Var99 string
+		templ_7745c5c3_Var99, templ_7745c5c3_Err = templ.JoinStringErrs(tool)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 429, Col: 47}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 821, Col: 47}
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var12))
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var99))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "\"><div class=\"control-panel__section-name\"><span class=\"material-symbols\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 156, "\"><div class=\"control-panel__section-name\"><span class=\"material-symbols\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var13 string
-		templ_7745c5c3_Var13, templ_7745c5c3_Err = templ.JoinStringErrs(icon)
+		var templ_7745c5c3_Var100 string
+		templ_7745c5c3_Var100, templ_7745c5c3_Err = templ.JoinStringErrs(icon)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 431, Col: 39}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 823, Col: 39}
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var13))
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var100))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "</span> ")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 157, "</span> ")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var14 string
-		templ_7745c5c3_Var14, templ_7745c5c3_Err = templ.JoinStringErrs(title)
+		var templ_7745c5c3_Var101 string
+		templ_7745c5c3_Var101, templ_7745c5c3_Err = templ.JoinStringErrs(title)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 432, Col: 9}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 824, Col: 9}
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var14))
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var101))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "</div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 158, "</div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templ_7745c5c3_Var11.Render(ctx, templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = templ_7745c5c3_Var98.Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "</div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 159, "</div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -328,48 +2023,48 @@ func NodeSelector(node_types []NodeType) templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var15 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var15 == nil {
-			templ_7745c5c3_Var15 = templ.NopComponent
+		templ_7745c5c3_Var102 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var102 == nil {
+			templ_7745c5c3_Var102 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, "<div id=\"node-type-select\" class=\"type-select\"><label>Type:  <graph-select name=\"node_type\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 160, "<div id=\"node-type-select\" class=\"type-select\"><label>Type:  <graph-select name=\"node_type\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		for _, t := range node_types {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, "<option value=\"")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 161, "<option value=\"")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var16 string
-			templ_7745c5c3_Var16, templ_7745c5c3_Err = templ.JoinStringErrs(t.Id)
+			var templ_7745c5c3_Var103 string
+			templ_7745c5c3_Var103, templ_7745c5c3_Err = templ.JoinStringErrs(t.Id)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 443, Col: 24}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 835, Col: 24}
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var16))
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var103))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "\">")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 162, "\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var17 string
-			templ_7745c5c3_Var17, templ_7745c5c3_Err = templ.JoinStringErrs(t.Name)
+			var templ_7745c5c3_Var104 string
+			templ_7745c5c3_Var104, templ_7745c5c3_Err = templ.JoinStringErrs(t.Name)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 443, Col: 33}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 835, Col: 33}
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var17))
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var104))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 20, "</option>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 163, "</option>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 21, "</graph-select></label></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 164, "</graph-select></label></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -393,48 +2088,48 @@ func EdgeSelector(edge_types []EdgeType) templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var18 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var18 == nil {
-			templ_7745c5c3_Var18 = templ.NopComponent
+		templ_7745c5c3_Var105 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var105 == nil {
+			templ_7745c5c3_Var105 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 22, "<div id=\"edge-type-select\" class=\"type-select\"><label>Type:  <graph-select name=\"edge_type\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 165, "<div id=\"edge-type-select\" class=\"type-select\"><label>Type:  <graph-select name=\"edge_type\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		for _, t := range edge_types {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 23, "<option value=\"")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 166, "<option value=\"")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var19 string
-			templ_7745c5c3_Var19, templ_7745c5c3_Err = templ.JoinStringErrs(t.Id)
+			var templ_7745c5c3_Var106 string
+			templ_7745c5c3_Var106, templ_7745c5c3_Err = templ.JoinStringErrs(t.Id)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 455, Col: 24}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 847, Col: 24}
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var19))
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var106))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "\">")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 167, "\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var20 string
-			templ_7745c5c3_Var20, templ_7745c5c3_Err = templ.JoinStringErrs(t.Name)
+			var templ_7745c5c3_Var107 string
+			templ_7745c5c3_Var107, templ_7745c5c3_Err = templ.JoinStringErrs(t.Name)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 455, Col: 33}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/graph/graph.templ`, Line: 847, Col: 33}
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var20))
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var107))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "</option>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 168, "</option>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 26, "</graph-select></label></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 169, "</graph-select></label></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -1,6 +1,10 @@
 package graph
 
 import (
+	"image/color"
+	"strconv"
+	"strings"
+
 	"github.com/pocketbase/pocketbase"
 )
 
@@ -15,6 +19,9 @@ type Node struct {
 	TempId   string `json:"temp_id"`
 	X        int    `db:"x" json:"x"`
 	Y        int    `db:"y" json:"y"`
+	Revision int    `db:"revision" json:"revision"`
+	// DeletedAt is set while the node is in the trash.
+	DeletedAt string `db:"deleted_at" json:"deleted_at,omitempty"`
 }
 
 type NodeType struct {
@@ -24,18 +31,75 @@ type NodeType struct {
 	FillColor   string `db:"fill_color" json:"fill_color"`
 	StrokeColor string `db:"stroke_color" json:"stroke_color"`
 	Metadata    []byte `db:"metadata" json:"metadata"`
+	// Schema lists the Attributes the metadata of nodes of this type has.
+	Schema      []byte `db:"schema" json:"schema"`
 	StrokeWidth uint8  `db:"stroke_width" json:"stroke_width"`
 	Shape       uint8  `db:"shape" json:"shape"`
 }
 
+// Node shapes, matching NodeShape of the engine.
+const (
+	S_CIRCLE uint8 = iota
+	S_SQUARE
+	S_SQUARE_ROUNDED
+	S_DIAMOND
+)
+
+// SHAPES names every node shape, in the order of their values.
+var SHAPES = []string{"Circle", "Square", "Rounded square", "Diamond"}
+
+// MAX_STROKE_WIDTH is the widest stroke a type may draw with.
+const MAX_STROKE_WIDTH = 32
+
+// MAX_LINE_DASH is the longest dash pattern an edge type may have.
+const MAX_LINE_DASH = 8
+
+// MIN_LINE_DASH is the shortest a dash pattern may add up to, shorter ones
+// cannot be told apart from a solid line.
+const MIN_LINE_DASH = 1
+
+// NODE_RADIUS is how far a node reaches from its position, as the canvas
+// draws it.
+const NODE_RADIUS = 20
+
+// ParseColor reads a color in any of the forms COLOR_PATTERN accepts.
+func ParseColor(s string) (color.NRGBA, bool) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 || len(hex) == 4 {
+		long := make([]byte, 0, len(hex)*2)
+		for i := range len(hex) {
+			long = append(long, hex[i], hex[i])
+		}
+		hex = string(long)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return color.NRGBA{}, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, true
+}
+
 type Edge struct {
 	Id      string `db:"id" json:"id"`
 	TempId  string `json:"temp_id"`
 	StartId string `db:"start_id" json:"start_id"`
 	EndId   string `db:"end_id" json:"end_id"`
 	Type    string `db:"type" json:"type"`
-	Created string `db:"created" json:"created"`
-	Updated string `db:"updated" json:"updated"`
+	// Name labels the edge, Metadata holds the properties of the
+	// relationship.
+	Name     string `db:"name" json:"name"`
+	Metadata []byte `db:"metadata" json:"metadata"`
+	Created  string `db:"created" json:"created"`
+	Updated  string `db:"updated" json:"updated"`
+	Revision int    `db:"revision" json:"revision"`
+	// DeletedAt is set while the edge is in the trash.
+	DeletedAt string `db:"deleted_at" json:"deleted_at,omitempty"`
 }
 
 type EdgeType struct {
@@ -45,19 +109,44 @@ type EdgeType struct {
 	StrokeColor string `db:"stroke_color" json:"stroke_color"`
 	LineDash    []byte `db:"line_dash" json:"line_dash"`
 	Metadata    []byte `db:"metadata" json:"metadata"`
+	// Schema lists the Attributes the metadata of edges of this type has.
+	Schema      []byte `db:"schema" json:"schema"`
 	StrokeWidth uint8  `db:"stroke_width" json:"stroke_width"`
 }
 
+// Attribute types.
+const (
+	A_STRING = "string"
+	A_NUMBER = "number"
+	A_BOOL   = "bool"
+	A_DATE   = "date"
+	A_ENUM   = "enum"
+	A_URL    = "url"
+)
+
+// Attribute is a field of the metadata of the nodes or edges of a type.
+// Options lists the values of an enum.
+type Attribute struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required,omitempty"`
+	Default  any      `json:"default,omitempty"`
+	Options  []string `json:"options,omitempty"`
+}
+
 type Permission uint8
 
 const (
 	P_EDIT_CONNECTION Permission = 1 << iota
 	P_EDIT_NODES
 	P_MANAGE_PROJECT
+
+	P_ALL = P_EDIT_CONNECTION | P_EDIT_NODES | P_MANAGE_PROJECT
 )
 
-func HasPermission(conf uint8, flag uint8) bool {
-	return conf&flag != 0
+// HasPermission reports whether conf grants every flag set in flag.
+func HasPermission(conf Permission, flag Permission) bool {
+	return conf&flag == flag
 }
 
 type PermissionConfig struct {
@@ -66,13 +155,81 @@ type PermissionConfig struct {
 	ManageProject  bool `db:"manage_project" json:"manage_project"`
 }
 
+func (c PermissionConfig) Permission() Permission {
+	var p Permission
+	if c.EditConnection {
+		p |= P_EDIT_CONNECTION
+	}
+	if c.EditNodes {
+		p |= P_EDIT_NODES
+	}
+	if c.ManageProject {
+		p |= P_MANAGE_PROJECT
+	}
+	return p
+}
+
+// ProjectMember gives a user other than the owner access to a project. Email
+// is joined in from users.
+type ProjectMember struct {
+	Id          string     `db:"id" json:"id"`
+	Project     string     `db:"project" json:"project"`
+	User        string     `db:"user" json:"user"`
+	Email       string     `db:"email" json:"email"`
+	Permissions Permission `db:"permissions" json:"permissions"`
+	Created     string     `db:"created" json:"created"`
+}
+
+// Snapshot is a saved version of a project. The graph itself stays in the
+// database until the snapshot is viewed or restored, Nodes and Edges only
+// count it. AuthorName is joined in from users; it is never their email, as
+// guests can list snapshots too.
+type Snapshot struct {
+	Id         string `db:"id" json:"id"`
+	Project    string `db:"project" json:"project"`
+	Author     string `db:"author" json:"author"`
+	AuthorName string `db:"author_name" json:"author_name"`
+	Name       string `db:"name" json:"name"`
+	Nodes      int    `db:"nodes" json:"nodes"`
+	Edges      int    `db:"edges" json:"edges"`
+	Created    string `db:"created" json:"created"`
+}
+
+const (
+	V_PRIVATE  = "private"
+	V_UNLISTED = "unlisted"
+	V_PUBLIC   = "public"
+)
+
+// Project is a graph owned by a single user. Permissions is not stored on the
+// project, it holds what the user who loaded it may do.
 type Project struct {
 	Permissions Permission `db:"permissions" json:"permissions"`
 	Id          string     `db:"id" json:"id"`
 	Owner       string     `db:"owner" json:"owner"`
 	Name        string     `db:"name" json:"name"`
+	Visibility  string     `db:"visibility" json:"visibility"`
 	Updated     string     `db:"updated" json:"updated"`
 	Created     string     `db:"created" json:"created"`
+	// Thumbnail is the file name of the drawing of the graph shown on the
+	// dashboard, empty until it is first drawn.
+	Thumbnail string `db:"thumbnail" json:"thumbnail,omitempty"`
+	// DeletedAt is set while the project is in the trash.
+	DeletedAt string `db:"deleted_at" json:"deleted_at,omitempty"`
+}
+
+// IsShared reports whether people other than the owner may view the project.
+// Projects without a visibility are private.
+func (p *Project) IsShared() bool {
+	return p.Visibility == V_UNLISTED || p.Visibility == V_PUBLIC
+}
+
+func (p *Project) CanEdit() bool {
+	return p.Permissions != 0
+}
+
+func IsVisibility(v string) bool {
+	return v == V_PRIVATE || v == V_UNLISTED || v == V_PUBLIC
 }
 
 func GetNodeTypes(app *pocketbase.PocketBase) *[]NodeType {